@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/spf13/cobra"
+)
+
+// New creates a new auth command
+func authCmd(ac *ic.Context) *cobra.Command {
+	o := &cmd.NoopRunner[*ic.Context]{}
+	c := cmd.NewSubCommand("auth", o, ac).
+		WithShortDesc("Inspect authentication").
+		WithExample(authCmdExample()).
+		WithGroupID(groupAuth).
+		WithNoArgs().
+		Build()
+	c.RunE = func(cmd *cobra.Command, _ []string) error {
+		return cmd.Help()
+	}
+
+	c.AddCommand(
+		authStatusCmd(ac, "status"),
+		authCacheCmd(ac),
+		authExecCredentialCmd(ac),
+	)
+	return c
+}
+
+func authCmdExample() string {
+	b := strings.Builder{}
+
+	b.WriteString("  # Show who you are logged in as and when the token expires\n")
+	b.WriteString("  ic auth status\n\n")
+
+	b.WriteString("  # Print an ExecCredential for kubectl\n")
+	b.WriteString("  ic auth exec-credential\n")
+	b.WriteString("\n")
+
+	return b.String()
+}
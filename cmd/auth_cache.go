@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/spf13/cobra"
+)
+
+var authCacheLongDesc = fmt.Sprintf(`Manage the token cache.
+
+The commands work on the token cache given by --token-cache, i.e. the files in
+the token cache directory given by --oidc-token-cache-dir or the items of ic in
+the keyring. The files are used if the keyring is not available. The format
+column shows which is used.
+
+re-encrypt only applies to files. Entries are encrypted when a key file is
+given using --token-cache-key-file or %s, or a passphrase
+is given using %s. Plaintext entries written before
+encryption was enabled are encrypted the first time they are used. Newer
+plaintext entries are not trusted and are rejected.`, envTokenCacheKeyFile, envTokenCachePassphrase)
+
+// New creates a new "auth cache" command
+func authCacheCmd(ac *ic.Context) *cobra.Command {
+	o := &cmd.NoopRunner[*ic.Context]{}
+	c := cmd.NewSubCommand("cache", o, ac).
+		WithShortDesc("Manage the token cache").
+		WithLongDesc(authCacheLongDesc).
+		WithExample(authCacheCmdExample()).
+		WithNoArgs().
+		Build()
+	c.RunE = func(cmd *cobra.Command, _ []string) error {
+		return cmd.Help()
+	}
+
+	c.AddCommand(
+		authCacheListCmd(ac),
+		authCachePurgeCmd(ac),
+		authCacheReEncryptCmd(ac),
+		authCacheShowCmd(ac),
+	)
+	return c
+}
+
+func authCacheCmdExample() string {
+	b := strings.Builder{}
+
+	b.WriteString("  # List cached tokens\n")
+	b.WriteString("  ic auth cache list\n\n")
+
+	b.WriteString("  # Show a cached token by a unique ID prefix\n")
+	b.WriteString("  ic auth cache show 3f2a9c\n\n")
+
+	b.WriteString("  # Encrypt all cached tokens using a key file\n")
+	b.WriteString("  ic auth cache re-encrypt --token-cache-key-file ~/.ic.key\n\n")
+
+	b.WriteString("  # Remove expired cached tokens\n")
+	b.WriteString("  ic auth cache purge --expired\n\n")
+
+	b.WriteString("  # Remove all cached tokens\n")
+	b.WriteString("  ic auth cache purge\n")
+	b.WriteString("\n")
+
+	return b.String()
+}
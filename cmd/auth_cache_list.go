@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/tokencache"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/spf13/cobra"
+)
+
+// New creates a new "auth cache list" command
+func authCacheListCmd(ac *ic.Context) *cobra.Command {
+	o := &authCacheListOptions{}
+	c := cmd.NewSubCommand("list", o, ac).
+		WithShortDesc("List cached tokens").
+		WithNoArgs().
+		Build()
+	return c
+}
+
+type authCacheListOptions struct{}
+
+func (o *authCacheListOptions) Complete(_ context.Context, _ *ic.Context) error { return nil }
+func (o *authCacheListOptions) Validate(_ context.Context, _ *ic.Context) error { return nil }
+
+func (o *authCacheListOptions) Run(_ context.Context, ac *ic.Context) error {
+	tokenCache, err := ac.NewEntryTokenCache()
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Opening token cache",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+	entries, err := tokenCache.Entries()
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Listing cached tokens",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	r := tokencache.NewEntriesRenderer(entries, ac.EC.Stdout, ac.EC.PFlags.NoHeaders)
+	if err := r.Render(ac.EC.PFlags.OutputFormat); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Failed to render output",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	return nil
+}
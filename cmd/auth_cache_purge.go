@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/tokencache"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/neticdk/go-common/pkg/cli/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const authCachePurgeLongDesc = `Remove cached tokens.
+
+All cached tokens are removed unless IDs or --expired are given. IDs are the
+IDs of entries or unique prefixes of them as shown by 'ic auth cache list'.
+
+Expired entries are entries with an expired access token and no refresh token
+or an expired refresh token.`
+
+// New creates a new "auth cache purge" command
+func authCachePurgeCmd(ac *ic.Context) *cobra.Command {
+	o := &authCachePurgeOptions{}
+	c := cmd.NewSubCommand("purge", o, ac).
+		WithShortDesc("Remove cached tokens").
+		WithLongDesc(authCachePurgeLongDesc).
+		Build()
+	c.Use = "purge [ID...]"
+
+	o.bindFlags(c.Flags())
+	return c
+}
+
+type authCachePurgeOptions struct {
+	Expired bool
+	ids     []string
+}
+
+func (o *authCachePurgeOptions) bindFlags(f *pflag.FlagSet) {
+	f.BoolVar(&o.Expired, "expired", false, "Only remove expired cached tokens")
+}
+
+func (o *authCachePurgeOptions) Complete(_ context.Context, ac *ic.Context) error {
+	o.ids = ac.EC.CommandArgs
+	return nil
+}
+
+func (o *authCachePurgeOptions) Validate(_ context.Context, _ *ic.Context) error { return nil }
+
+func (o *authCachePurgeOptions) Run(_ context.Context, ac *ic.Context) error {
+	tokenCache, err := ac.NewEntryTokenCache()
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Opening token cache",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	if len(o.ids) == 0 && !o.Expired {
+		if !ac.EC.PFlags.Force {
+			if yes := ui.Confirm("Remove all cached tokens"); !yes {
+				ui.Info.Println("User aborted")
+				return nil
+			}
+		}
+		n, err := tokenCache.Purge()
+		if err != nil {
+			return ac.EC.ErrorHandler.NewGeneralError(
+				"Removing cached tokens",
+				"See details for more information",
+				err,
+				0,
+			)
+		}
+		ui.Success.Printf("Removed %d cached token(s)\n", n)
+		return nil
+	}
+
+	entries, err := tokenCache.Entries()
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Listing cached tokens",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+	selected, err := o.selectEntries(entries)
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			err.Error(),
+			"Use 'ic auth cache list' to list cached tokens",
+			nil,
+			0,
+		)
+	}
+	if len(selected) == 0 {
+		ui.Info.Println("No cached tokens to remove")
+		return nil
+	}
+	if !ac.EC.PFlags.Force {
+		if yes := ui.Confirm(fmt.Sprintf("Remove %d cached token(s)", len(selected))); !yes {
+			ui.Info.Println("User aborted")
+			return nil
+		}
+	}
+
+	for i, e := range selected {
+		if err := tokenCache.Remove(e.ID); err != nil {
+			return ac.EC.ErrorHandler.NewGeneralError(
+				fmt.Sprintf("Removing cached tokens (%d removed)", i),
+				"See details for more information",
+				err,
+				0,
+			)
+		}
+	}
+
+	ui.Success.Printf("Removed %d cached token(s)\n", len(selected))
+	return nil
+}
+
+// selectEntries returns the entries given by IDs. Only expired entries are
+// returned if --expired is given
+func (o *authCachePurgeOptions) selectEntries(entries []tokencache.Entry) ([]tokencache.Entry, error) {
+	if len(o.ids) > 0 {
+		var selected []tokencache.Entry
+		seen := map[string]bool{}
+		for _, id := range o.ids {
+			e, err := tokencache.FindEntry(entries, id)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[e.ID] {
+				seen[e.ID] = true
+				selected = append(selected, *e)
+			}
+		}
+		entries = selected
+	}
+	if !o.Expired {
+		return entries, nil
+	}
+	var expired []tokencache.Entry
+	for _, e := range entries {
+		if e.State == tokencache.StateExpired {
+			expired = append(expired, e)
+		}
+	}
+	return expired, nil
+}
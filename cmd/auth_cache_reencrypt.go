@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/tokencache"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/neticdk/go-common/pkg/cli/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var authCacheReEncryptLongDesc = fmt.Sprintf(`Encrypt all cached tokens using the current key.
+
+Plaintext entries are encrypted. Entries encrypted with another key, e.g. when
+rotating keys, are decrypted using the key file given by --from-key-file or the
+passphrase given using %s. Entries already encrypted with
+the current key, e.g. by an interrupted earlier run, are encrypted again.
+
+Entries which cannot be decrypted and plaintext entries written after
+encryption was enabled are skipped and listed. Remove them using auth cache
+purge.`, envTokenCacheOldPassphrase)
+
+// New creates a new "auth cache re-encrypt" command
+func authCacheReEncryptCmd(ac *ic.Context) *cobra.Command {
+	o := &authCacheReEncryptOptions{}
+	c := cmd.NewSubCommand("re-encrypt", o, ac).
+		WithShortDesc("Encrypt cached tokens using the current key").
+		WithLongDesc(authCacheReEncryptLongDesc).
+		WithNoArgs().
+		Build()
+
+	o.bindFlags(c.Flags())
+	return c
+}
+
+type authCacheReEncryptOptions struct {
+	FromKeyFile string
+	from        tokencache.KeySource
+}
+
+func (o *authCacheReEncryptOptions) bindFlags(f *pflag.FlagSet) {
+	f.StringVar(&o.FromKeyFile, "from-key-file", "", "File with the key the entries are currently encrypted with")
+}
+
+func (o *authCacheReEncryptOptions) Complete(_ context.Context, _ *ic.Context) error {
+	o.from = tokencache.KeySource{
+		KeyFile:    o.FromKeyFile,
+		Passphrase: os.Getenv(envTokenCacheOldPassphrase),
+	}
+	return nil
+}
+
+func (o *authCacheReEncryptOptions) Validate(_ context.Context, ac *ic.Context) error {
+	if ac.OIDC.TokenCacheKeyFile == "" && ac.OIDC.TokenCachePassphrase == "" {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"No key given",
+			fmt.Sprintf("Use --token-cache-key-file, %s or %s", envTokenCacheKeyFile, envTokenCachePassphrase),
+			nil,
+			0,
+		)
+	}
+	return nil
+}
+
+func (o *authCacheReEncryptOptions) Run(_ context.Context, ac *ic.Context) error {
+	fileCache, err := ac.NewFileTokenCache()
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Opening token cache",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	var n int
+	var skipped []string
+	if err := ui.Spin(ac.EC.Spinner, "Encrypting cached tokens", func(_ ui.Spinner) error {
+		n, skipped, err = fileCache.ReEncrypt(o.from)
+		return err
+	}); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Encrypting cached tokens",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	ui.Success.Printf("Encrypted %d cached token(s)\n", n)
+	if len(skipped) > 0 {
+		ui.Warning.Printf("Skipped %d cached token(s) which could not be decrypted or are untrusted plaintext: %s\n", len(skipped), strings.Join(skipped, ", "))
+	}
+	return nil
+}
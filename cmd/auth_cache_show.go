@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/tokencache"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/spf13/cobra"
+)
+
+const authCacheShowLongDesc = `Show a cached token.
+
+ID is the ID of the entry or a unique prefix of it as shown by 'ic auth cache
+list'. Tokens are not printed.`
+
+// New creates a new "auth cache show" command
+func authCacheShowCmd(ac *ic.Context) *cobra.Command {
+	o := &authCacheShowOptions{}
+	c := cmd.NewSubCommand("show", o, ac).
+		WithShortDesc("Show a cached token").
+		WithLongDesc(authCacheShowLongDesc).
+		WithExactArgs(1).
+		Build()
+	c.Use = "show ID"
+	return c
+}
+
+type authCacheShowOptions struct {
+	id string
+}
+
+func (o *authCacheShowOptions) Complete(_ context.Context, ac *ic.Context) error {
+	o.id = ac.EC.CommandArgs[0]
+	return nil
+}
+
+func (o *authCacheShowOptions) Validate(_ context.Context, _ *ic.Context) error { return nil }
+
+func (o *authCacheShowOptions) Run(_ context.Context, ac *ic.Context) error {
+	tokenCache, err := ac.NewEntryTokenCache()
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Opening token cache",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+	entries, err := tokenCache.Entries()
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Listing cached tokens",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+	entry, err := tokencache.FindEntry(entries, o.id)
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			err.Error(),
+			"Use 'ic auth cache list' to list cached tokens",
+			nil,
+			0,
+		)
+	}
+
+	r := tokencache.NewEntryRenderer(entry, ac.EC.Stdout)
+	if err := r.Render(ac.EC.PFlags.OutputFormat); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Failed to render output",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/jwt"
+	"github.com/neticdk-k8s/ic/internal/kubeconfig"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/spf13/cobra"
+)
+
+var authExecCredentialLongDesc = fmt.Sprintf(`Print an ExecCredential for kubectl.
+
+ic acts as a client-go credential plugin. The cached token is used if it is
+valid and login is performed otherwise. The token and its expiry are printed
+as an ExecCredential (%s) which kubectl uses to
+authenticate to clusters accepting tokens issued by the OIDC provider.
+
+The ExecCredential version asked for by kubectl in %s is used.
+Logins needing standard input (authcode-keyboard) fail when kubectl runs the
+plugin non-interactively.`, kubeconfig.ExecCredentialAPIVersion, kubeconfig.ExecInfoEnv)
+
+// New creates a new "auth exec-credential" command
+func authExecCredentialCmd(ac *ic.Context) *cobra.Command {
+	o := &authExecCredentialOptions{}
+	c := cmd.NewSubCommand("exec-credential", o, ac).
+		WithShortDesc("Print an ExecCredential for kubectl").
+		WithLongDesc(authExecCredentialLongDesc).
+		WithExample(authExecCredentialCmdExample()).
+		WithNoArgs().
+		Build()
+	return c
+}
+
+type authExecCredentialOptions struct {
+	execInfo *kubeconfig.ExecCredential
+}
+
+func (o *authExecCredentialOptions) Complete(_ context.Context, ac *ic.Context) (err error) {
+	if o.execInfo, err = kubeconfig.ParseExecInfo(os.Getenv(kubeconfig.ExecInfoEnv)); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Reading exec info",
+			"Use a supported ExecCredential version in the kubeconfig",
+			err,
+			0,
+		)
+	}
+	return nil
+}
+
+func (o *authExecCredentialOptions) Validate(_ context.Context, ac *ic.Context) error {
+	if !o.execInfo.Spec.Interactive && ac.OIDC.GrantType == "authcode-keyboard" {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Login using authcode-keyboard needs standard input",
+			"Set interactiveMode: IfAvailable in the kubeconfig or use another grant type",
+			nil,
+			0,
+		)
+	}
+	return nil
+}
+
+func (o *authExecCredentialOptions) Run(ctx context.Context, ac *ic.Context) error {
+	logger := ac.EC.Logger.WithGroup("Login")
+	ac.Authenticator.SetLogger(logger)
+
+	tokenSet, err := doLogin(ctx, ac)
+	if err != nil {
+		return fmt.Errorf("logging in: %w", err)
+	}
+	claims, err := jwt.DecodeWithoutVerify(tokenSet.AccessToken)
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Invalid token",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	// tokens without exp are passed without expirationTimestamp so kubectl
+	// does not consider them expired
+	var expiry time.Time
+	if claims.HasExpiry() {
+		expiry = claims.Expiry
+	}
+
+	// kubectl reads the ExecCredential from stdout so nothing else may be
+	// written there
+	if err := json.NewEncoder(ac.EC.Stdout).Encode(o.execInfo.WithToken(tokenSet.AccessToken, expiry)); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Failed to render output",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+	return nil
+}
+
+func authExecCredentialCmdExample() string {
+	b := strings.Builder{}
+
+	b.WriteString("  # Print an ExecCredential\n")
+	b.WriteString("  ic auth exec-credential\n\n")
+
+	b.WriteString("  # Use ic as credential plugin in a kubeconfig\n")
+	b.WriteString("  users:\n")
+	b.WriteString("  - name: my-cluster\n")
+	b.WriteString("    user:\n")
+	b.WriteString("      exec:\n")
+	b.WriteString("        apiVersion: client.authentication.k8s.io/v1\n")
+	b.WriteString("        command: ic\n")
+	b.WriteString("        args: [auth, exec-credential]\n")
+	b.WriteString("        interactiveMode: IfAvailable\n")
+	b.WriteString("\n")
+
+	return b.String()
+}
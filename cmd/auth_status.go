@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/render"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/spf13/cobra"
+)
+
+const authStatusLongDesc = `Show the token used for authentication.
+
+The cached token (or the token given using --token, --token-file or IC_TOKEN)
+is decoded and the subject, email, roles, groups, issuer, audience and expiry
+are shown together with what the next command will do: use the token, refresh
+it or require a new login.
+
+The access token is checked for the role needed to change resources given by
+--write-role and, if --api-audience is set, for the audience expected by the
+inventory server.
+
+No login or token refresh is performed. The command fails if no token is
+found. json and yaml output is still printed with logged_in set to false.`
+
+// New creates a new "auth status" command. It is also used for "whoami"
+func authStatusCmd(ac *ic.Context, name string) *cobra.Command {
+	o := &authStatusOptions{}
+	c := cmd.NewSubCommand(name, o, ac).
+		WithShortDesc("Show authentication status").
+		WithLongDesc(authStatusLongDesc).
+		WithNoArgs().
+		Build()
+	if name == "whoami" {
+		c.GroupID = groupAuth
+	}
+	return c
+}
+
+type authStatusOptions struct{}
+
+func (o *authStatusOptions) Complete(_ context.Context, _ *ic.Context) error { return nil }
+func (o *authStatusOptions) Validate(_ context.Context, _ *ic.Context) error { return nil }
+
+func (o *authStatusOptions) Run(_ context.Context, ac *ic.Context) error {
+	staticToken, err := readStaticToken(ac)
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Reading token",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	status, err := authentication.Status(authentication.StatusInput{
+		Provider:    *ac.OIDCProvider,
+		TokenCache:  ac.TokenCache,
+		Profile:     ac.Profile,
+		StaticToken: staticToken,
+		RefreshSkew: ac.OIDC.RefreshSkew,
+		Audience:    ac.APIAudience,
+		WriteRole:   ac.WriteRole,
+	})
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Getting authentication status",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	// json and yaml output is also rendered when logged out so scripts can
+	// read logged_in. The command fails either way
+	format := ac.EC.PFlags.OutputFormat
+	if status.LoggedIn || format == render.FormatJSON || format == render.FormatYAML {
+		r := authentication.NewStatusRenderer(status, ac.EC.Stdout)
+		if err := r.Render(format); err != nil {
+			return ac.EC.ErrorHandler.NewGeneralError(
+				"Failed to render output",
+				"See details for more information",
+				err,
+				0,
+			)
+		}
+	}
+	if !status.LoggedIn {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Not logged in",
+			"Use 'ic login' to log in",
+			nil,
+			0,
+		)
+	}
+
+	return nil
+}
@@ -3,16 +3,24 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/neticdk-k8s/ic/internal/ic"
 	"github.com/neticdk-k8s/ic/internal/oidc"
+	"github.com/neticdk-k8s/ic/internal/reader"
 	testingJWT "github.com/neticdk-k8s/ic/internal/testing/jwt"
 	"github.com/neticdk-k8s/ic/internal/tokencache"
 	"github.com/neticdk-k8s/ic/internal/usecases/authentication"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication/authcode"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication/clientcredentials"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication/devicecode"
 	"github.com/neticdk/go-common/pkg/cli/cmd"
 	"github.com/neticdk/go-common/pkg/cli/ui"
 	"github.com/stretchr/testify/assert"
@@ -100,4 +108,498 @@ func Test_AuthCommands(t *testing.T) {
 		assert.Contains(t, got.String(), "Logging out")
 		assert.Contains(t, got.String(), "Logged out")
 	})
+
+	t.Run("auth exec-credential", func(t *testing.T) {
+		t.Setenv("KUBERNETES_EXEC_INFO", `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false}}`)
+		stdout := new(bytes.Buffer)
+		stderr := new(bytes.Buffer)
+		ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+		ec.Stderr = stderr
+		ec.Stdout = stdout
+		ui.SetDefaultOutput(stderr)
+		ac := ic.NewContext()
+		ac.EC = ec
+		mockAuthentication := authentication.NewMockAuthentication(t)
+		mockAuthentication.EXPECT().
+			Authenticate(mock.Anything, mock.Anything).
+			Return(&authentication.AuthResult{
+				UsingCachedToken: true,
+				TokenSet:         issuedTokenSet,
+			}, nil)
+		mockAuthentication.EXPECT().
+			SetLogger(mock.Anything).
+			Return()
+		mockTokenCache := tokencache.NewMockCache(t)
+		mockTokenCache.EXPECT().
+			Lookup(mock.Anything).
+			Return(&issuedTokenSet, nil)
+		ac.TokenCache = mockTokenCache
+		ac.Authenticator = authentication.NewAuthenticator(logger, mockAuthentication)
+
+		cmd := newRootCmd(ac)
+
+		cmd.SetArgs([]string{"auth", "exec-credential"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+		want := fmt.Sprintf(`{
+			"kind": "ExecCredential",
+			"apiVersion": "client.authentication.k8s.io/v1beta1",
+			"spec": {},
+			"status": {"token": %q, "expirationTimestamp": %q}
+		}`, issuedIDToken, issuedIDTokenExpiration.UTC().Format(time.RFC3339))
+		assert.JSONEq(t, want, stdout.String())
+	})
+
+	t.Run("auth exec-credential with static token without exp", func(t *testing.T) {
+		t.Setenv("KUBERNETES_EXEC_INFO", `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false}}`)
+		token := testingJWT.EncodeF(t, func(claims *testingJWT.Claims) {
+			claims.Subject = "YOUR_SUBJECT"
+		})
+		stdout := new(bytes.Buffer)
+		stderr := new(bytes.Buffer)
+		ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+		ec.Stderr = stderr
+		ec.Stdout = stdout
+		ui.SetDefaultOutput(stderr)
+		ac := ic.NewContext()
+		ac.EC = ec
+		mockAuthentication := authentication.NewMockAuthentication(t)
+		mockAuthentication.EXPECT().
+			SetLogger(mock.Anything).
+			Return()
+		ac.TokenCache = tokencache.NewMockCache(t)
+		ac.Authenticator = authentication.NewAuthenticator(logger, mockAuthentication)
+
+		cmd := newRootCmd(ac)
+
+		cmd.SetArgs([]string{"--token", token, "auth", "exec-credential"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+		want := fmt.Sprintf(`{
+			"kind": "ExecCredential",
+			"apiVersion": "client.authentication.k8s.io/v1beta1",
+			"spec": {},
+			"status": {"token": %q}
+		}`, token)
+		assert.JSONEq(t, want, stdout.String())
+	})
+
+	t.Run("auth exec-credential non-interactive keyboard", func(t *testing.T) {
+		t.Setenv("KUBERNETES_EXEC_INFO", `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1","spec":{"interactive":false}}`)
+		got := new(bytes.Buffer)
+		ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+		ec.Stderr = got
+		ec.Stdout = got
+		ui.SetDefaultOutput(got)
+		ac := ic.NewContext()
+		ac.EC = ec
+		ac.Authenticator = authentication.NewAuthenticator(logger, authentication.NewMockAuthentication(t))
+		ac.TokenCache = tokencache.NewMockCache(t)
+
+		cmd := newRootCmd(ac)
+
+		cmd.SetArgs([]string{"--oidc-grant-type", "authcode-keyboard", "auth", "exec-credential"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.ErrorContains(t, err, "needs standard input")
+	})
+
+	t.Run("auth exec-credential interactive keyboard", func(t *testing.T) {
+		t.Setenv("KUBERNETES_EXEC_INFO", `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1","spec":{"interactive":true}}`)
+		stdout := new(bytes.Buffer)
+		stderr := new(bytes.Buffer)
+		ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+		ec.Stderr = stderr
+		ec.Stdout = stdout
+		ui.SetDefaultOutput(stderr)
+		ac := ic.NewContext()
+		ac.EC = ec
+		mockClient := oidc.NewMockClient(t)
+		mockClient.EXPECT().
+			GetAuthCodeURL(mock.Anything, mock.Anything).
+			Return("https://issuer.example.com/auth", nil)
+		mockClient.EXPECT().
+			ExchangeAuthCode(mock.Anything, mock.Anything).
+			Return(&issuedTokenSet, nil)
+		mockClientFactory := oidc.NewMockFactoryClient(t)
+		mockClientFactory.EXPECT().
+			SetLogger(mock.Anything).
+			Return()
+		mockClientFactory.EXPECT().
+			New(mock.Anything, mock.Anything).
+			Return(mockClient, nil)
+		mockReader := reader.NewMockReader(t)
+		mockReader.EXPECT().
+			ReadString(mock.Anything).
+			Return("YOUR_AUTH_CODE", nil)
+		authn := authentication.NewAuthentication(logger, mockClientFactory,
+			&authcode.Browser{Logger: logger},
+			&authcode.Keyboard{Reader: mockReader, Writer: ec.Stderr, Logger: logger},
+			&clientcredentials.ClientCredentials{Logger: logger},
+			&devicecode.DeviceCode{Writer: ec.Stderr, Logger: logger})
+		ac.Authenticator = authentication.NewAuthenticator(logger, authn)
+		mockTokenCache := tokencache.NewMockCache(t)
+		mockTokenCache.EXPECT().
+			Lookup(mock.Anything).
+			Return(nil, errors.New("cache miss"))
+		mockTokenCache.EXPECT().
+			Save(mock.Anything, issuedTokenSet).
+			Return(nil)
+		ac.TokenCache = mockTokenCache
+
+		cmd := newRootCmd(ac)
+
+		cmd.SetArgs([]string{"--oidc-grant-type", "authcode-keyboard", "auth", "exec-credential"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+		assert.Contains(t, stderr.String(), "https://issuer.example.com/auth")
+		want := fmt.Sprintf(`{
+			"kind": "ExecCredential",
+			"apiVersion": "client.authentication.k8s.io/v1",
+			"spec": {"interactive": true},
+			"status": {"token": %q, "expirationTimestamp": %q}
+		}`, issuedIDToken, issuedIDTokenExpiration.UTC().Format(time.RFC3339))
+		assert.JSONEq(t, want, stdout.String())
+	})
+
+	t.Run("auth status", func(t *testing.T) {
+		for _, args := range [][]string{{"auth", "status"}, {"whoami"}} {
+			got := new(bytes.Buffer)
+			ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+			ec.Stderr = got
+			ec.Stdout = got
+			ui.SetDefaultOutput(got)
+			ac := ic.NewContext()
+			ac.EC = ec
+			ac.Authenticator = authentication.NewAuthenticator(logger, authentication.NewMockAuthentication(t))
+			mockTokenCache := tokencache.NewMockCache(t)
+			mockTokenCache.EXPECT().
+				Lookup(mock.Anything).
+				Return(&issuedTokenSet, nil)
+			ac.TokenCache = mockTokenCache
+
+			cmd := newRootCmd(ac)
+
+			cmd.SetArgs(append(args, "-o", "json"))
+			err := cmd.ExecuteContext(context.Background())
+			assert.NoError(t, err)
+			assert.Contains(t, got.String(), `"subject": "YOUR_SUBJECT"`)
+			assert.Contains(t, got.String(), `"next_action": "use-cached-token"`)
+			assert.Contains(t, got.String(), `"has_refresh_token": true`)
+		}
+	})
+
+	t.Run("auth cache", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		keyFile := filepath.Join(t.TempDir(), "key")
+		assert.NoError(t, os.WriteFile(keyFile, []byte("YOUR_KEY_MATERIAL"), 0o600))
+		plainCache, err := tokencache.NewFSCache(cacheDir)
+		assert.NoError(t, err)
+		assert.NoError(t, plainCache.Save(tokencache.Key{IssuerURL: "https://issuer.example.com", ClientID: "YOUR_CLIENT_ID"}, issuedTokenSet))
+		expiredToken := testingJWT.EncodeF(t, func(claims *testingJWT.Claims) {
+			claims.Subject = "EXPIRED_SUBJECT"
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		})
+		assert.NoError(t, plainCache.Save(tokencache.Key{IssuerURL: "https://issuer.example.com", ClientID: "EXPIRED_CLIENT_ID"}, oidc.TokenSet{AccessToken: expiredToken}))
+		entries, err := plainCache.Entries()
+		assert.NoError(t, err)
+		var validID string
+		for _, e := range entries {
+			if e.State == tokencache.StateValid {
+				validID = e.ID
+			}
+		}
+
+		run := func(args ...string) (string, error) {
+			got := new(bytes.Buffer)
+			ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+			ec.Stderr = got
+			ec.Stdout = got
+			ec.PFlags.ForceEnabled = true
+			ui.SetDefaultOutput(got)
+			ac := ic.NewContext()
+			ac.EC = ec
+			ac.Authenticator = authentication.NewAuthenticator(logger, authentication.NewMockAuthentication(t))
+			cmd := newRootCmd(ac)
+			cmd.SetArgs(append([]string{"--token-cache", "fs", "--oidc-token-cache-dir", cacheDir}, args...))
+			err := cmd.ExecuteContext(context.Background())
+			return got.String(), err
+		}
+
+		got, err := run("auth", "cache", "list", "-o", "json")
+		assert.NoError(t, err)
+		assert.Contains(t, got, `"format": "plaintext"`)
+		assert.Contains(t, got, `"client_id": "YOUR_CLIENT_ID"`)
+		assert.Contains(t, got, `"state": "expired"`)
+		assert.NotContains(t, got, "YOUR_REFRESH_TOKEN")
+
+		got, err = run("auth", "cache", "show", validID[:12])
+		assert.NoError(t, err)
+		assert.Contains(t, got, "YOUR_SUBJECT")
+		assert.Contains(t, got, "valid")
+		assert.NotContains(t, got, issuedIDToken)
+
+		_, err = run("auth", "cache", "show", "unknown")
+		assert.ErrorContains(t, err, "not found")
+
+		got, err = run("--force", "auth", "cache", "purge", "--expired")
+		assert.NoError(t, err)
+		assert.Contains(t, got, "Removed 1 cached token(s)")
+
+		_, err = run("auth", "cache", "re-encrypt")
+		assert.Error(t, err, "re-encrypt without a key")
+
+		got, err = run("auth", "cache", "re-encrypt", "--token-cache-key-file", keyFile)
+		assert.NoError(t, err)
+		assert.Contains(t, got, "Encrypted 1 cached token(s)")
+
+		got, err = run("auth", "cache", "list", "-o", "json")
+		assert.NoError(t, err)
+		assert.Contains(t, got, `"format": "encrypted"`)
+		assert.Contains(t, got, `"state": "unknown"`)
+
+		got, err = run("--token-cache-key-file", keyFile, "auth", "cache", "list", "-o", "json")
+		assert.NoError(t, err)
+		assert.Contains(t, got, `"state": "valid"`)
+
+		got, err = run("--force", "auth", "cache", "purge", validID[:12])
+		assert.NoError(t, err)
+		assert.Contains(t, got, "Removed 1 cached token(s)")
+	})
+
+	t.Run("auth status not logged in", func(t *testing.T) {
+		got := new(bytes.Buffer)
+		ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+		ec.Stderr = got
+		ec.Stdout = got
+		ui.SetDefaultOutput(got)
+		ac := ic.NewContext()
+		ac.EC = ec
+		ac.Authenticator = authentication.NewAuthenticator(logger, authentication.NewMockAuthentication(t))
+		mockTokenCache := tokencache.NewMockCache(t)
+		mockTokenCache.EXPECT().
+			Lookup(mock.Anything).
+			Return(nil, &tokencache.CacheMissError{})
+		ac.TokenCache = mockTokenCache
+
+		cmd := newRootCmd(ac)
+
+		cmd.SetArgs([]string{"auth", "status"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.ErrorContains(t, err, "Not logged in")
+		assert.Empty(t, got.String())
+	})
+
+	t.Run("auth status not logged in json", func(t *testing.T) {
+		stdout := new(bytes.Buffer)
+		stderr := new(bytes.Buffer)
+		ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+		ec.Stderr = stderr
+		ec.Stdout = stdout
+		ui.SetDefaultOutput(stderr)
+		ac := ic.NewContext()
+		ac.EC = ec
+		ac.Authenticator = authentication.NewAuthenticator(logger, authentication.NewMockAuthentication(t))
+		mockTokenCache := tokencache.NewMockCache(t)
+		mockTokenCache.EXPECT().
+			Lookup(mock.Anything).
+			Return(nil, &tokencache.CacheMissError{})
+		ac.TokenCache = mockTokenCache
+
+		cmd := newRootCmd(ac)
+
+		cmd.SetArgs([]string{"auth", "status", "-o", "json"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.ErrorContains(t, err, "Not logged in")
+		assert.JSONEq(t, `{"logged_in": false, "source": "cache", "has_refresh_token": false, "next_action": "login"}`, stdout.String())
+	})
+
+	t.Run("login with static token", func(t *testing.T) {
+		tokenFile := filepath.Join(t.TempDir(), "token")
+		assert.NoError(t, os.WriteFile(tokenFile, []byte(issuedIDToken+"\n"), 0o600))
+		expiredToken := testingJWT.EncodeF(t, func(claims *testingJWT.Claims) {
+			claims.Subject = "YOUR_SUBJECT"
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-1 * time.Hour))
+		})
+		nonExpiringToken := testingJWT.EncodeF(t, func(claims *testingJWT.Claims) {
+			claims.Subject = "YOUR_SUBJECT"
+		})
+
+		tests := []struct {
+			name       string
+			args       []string
+			env        string
+			wantErrMsg string
+		}{
+			{name: "flag", args: []string{"--token", issuedIDToken}},
+			{name: "file", args: []string{"--token-file", tokenFile}},
+			{name: "env", env: issuedIDToken},
+			{name: "without exp", args: []string{"--token", nonExpiringToken}},
+			{name: "expired", args: []string{"--token", expiredToken}, wantErrMsg: "Token expired"},
+			{name: "invalid", args: []string{"--token", "not-a-jwt"}, wantErrMsg: "Invalid token"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Setenv(envToken, tt.env)
+				got := new(bytes.Buffer)
+				ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+				ec.Stderr = got
+				ec.Stdout = got
+				ui.SetDefaultOutput(got)
+				ac := ic.NewContext()
+				ac.EC = ec
+				// neither authentication nor the token cache may be used
+				mockAuthentication := authentication.NewMockAuthentication(t)
+				mockAuthentication.EXPECT().
+					SetLogger(mock.Anything).
+					Return()
+				ac.TokenCache = tokencache.NewMockCache(t)
+				ac.Authenticator = authentication.NewAuthenticator(logger, mockAuthentication)
+
+				cmd := newRootCmd(ac)
+
+				cmd.SetArgs(append([]string{"login"}, tt.args...))
+				err := cmd.ExecuteContext(context.Background())
+				if tt.wantErrMsg != "" {
+					assert.ErrorContains(t, err, tt.wantErrMsg)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Contains(t, got.String(), "Logged in")
+				assert.NotNil(t, ac.APIClient)
+			})
+		}
+	})
+
+	t.Run("logout with static token", func(t *testing.T) {
+		got := new(bytes.Buffer)
+		ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+		ec.Stderr = got
+		ec.Stdout = got
+		ui.SetDefaultOutput(got)
+		ac := ic.NewContext()
+		ac.EC = ec
+		mockAuthentication := authentication.NewMockAuthentication(t)
+		mockAuthentication.EXPECT().
+			SetLogger(mock.Anything).
+			Return()
+		ac.TokenCache = tokencache.NewMockCache(t)
+		ac.Authenticator = authentication.NewAuthenticator(logger, mockAuthentication)
+
+		cmd := newRootCmd(ac)
+
+		cmd.SetArgs([]string{"logout", "--token", issuedIDToken})
+		err := cmd.ExecuteContext(context.Background())
+		assert.ErrorContains(t, err, "Logging out")
+	})
+}
+
+func Test_readClientSecret(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "secret")
+	assert.NoError(t, os.WriteFile(secretFile, []byte("file-secret\n"), 0o600))
+	emptyFile := filepath.Join(dir, "empty")
+	assert.NoError(t, os.WriteFile(emptyFile, []byte("\n"), 0o600))
+
+	tests := []struct {
+		name       string
+		flag       string
+		file       string
+		env        string
+		want       string
+		wantErrMsg string
+	}{
+		{name: "flag", flag: "flag-secret", file: secretFile, env: "env-secret", want: "flag-secret"},
+		{name: "file", file: secretFile, env: "env-secret", want: "file-secret"},
+		{name: "env", env: "env-secret", want: "env-secret"},
+		{name: "empty file", file: emptyFile, wantErrMsg: "is empty"},
+		{name: "missing file", file: filepath.Join(dir, "missing"), wantErrMsg: "reading client secret file"},
+		{name: "not set", wantErrMsg: "client secret not set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envClientSecret, tt.env)
+			ac := ic.NewContext()
+			ac.OIDC.ClientSecret = tt.flag
+			ac.OIDC.ClientSecretFile = tt.file
+			got, err := readClientSecret(ac)
+			if tt.wantErrMsg != "" {
+				assert.ErrorContains(t, err, tt.wantErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_warnMissingRole(t *testing.T) {
+	encode := func(mutation func(*testingJWT.Claims)) string {
+		return testingJWT.EncodeF(t, func(claims *testingJWT.Claims) {
+			claims.Subject = "YOUR_SUBJECT"
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+			mutation(claims)
+		})
+	}
+	const writeRole = "inventory-write"
+	tests := []struct {
+		name      string
+		token     string
+		writeRole string
+		wantWarn  bool
+	}{
+		{
+			name:      "realm role",
+			writeRole: writeRole,
+			token: encode(func(claims *testingJWT.Claims) {
+				claims.RealmAccess = &testingJWT.Access{Roles: []string{writeRole}}
+			}),
+		},
+		{
+			name:      "client role",
+			writeRole: writeRole,
+			token: encode(func(claims *testingJWT.Claims) {
+				claims.ResourceAccess = map[string]testingJWT.Access{
+					"inventory-api": {Roles: []string{writeRole}},
+				}
+			}),
+		},
+		{
+			name: "missing role",
+			token: encode(func(claims *testingJWT.Claims) {
+				claims.RealmAccess = &testingJWT.Access{Roles: []string{"inventory-read"}}
+			}),
+			writeRole: writeRole,
+			wantWarn:  true,
+		},
+		{
+			name: "no write role configured",
+			token: encode(func(claims *testingJWT.Claims) {
+				claims.RealmAccess = &testingJWT.Access{Roles: []string{"inventory-read"}}
+			}),
+		},
+		{
+			name:      "no role claims",
+			token:     encode(func(_ *testingJWT.Claims) {}),
+			writeRole: writeRole,
+		},
+		{
+			name:      "not a jwt",
+			token:     "YOUR_ACCESS_TOKEN",
+			writeRole: writeRole,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := new(bytes.Buffer)
+			ui.SetDefaultOutput(got)
+			ac := ic.NewContext()
+			ac.WriteRole = tt.writeRole
+			warnMissingRole(ac, &oidc.TokenSet{AccessToken: tt.token}, "delete clusters")
+			if tt.wantWarn {
+				assert.Contains(t, got.String(), `lacks the "inventory-write" role needed to delete clusters`)
+			} else {
+				assert.Empty(t, got.String())
+			}
+		})
+	}
 }
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/neticdk-k8s/ic/internal/config"
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/spf13/cobra"
+)
+
+// profileKeys are the flags which can be set in a profile
+var profileKeys = []string{
+	"api-server",
+	"api-audience",
+	"write-role",
+	"ca-file",
+	"client-cert",
+	"client-key",
+	"proxy",
+	"oidc-issuer-url",
+	"oidc-client-id",
+	"oidc-grant-type",
+	"oidc-redirect-url-hostname",
+	"oidc-auth-bind-addr",
+	"oidc-redirect-uri-authcode-keyboard",
+	"oidc-scopes",
+	"oidc-acr-values",
+	"oidc-prompt",
+	"oidc-client-secret-file",
+	"oidc-refresh-skew",
+	"oidc-token-cache-dir",
+	"oidc-verify-cached-token",
+	"token-cache",
+	"token-cache-key-file",
+	"token-file",
+}
+
+// New creates a new config command
+func configCmd(ac *ic.Context) *cobra.Command {
+	o := &cmd.NoopRunner[*ic.Context]{}
+	c := cmd.NewSubCommand("config", o, ac).
+		WithShortDesc("Manage configuration profiles").
+		WithExample(configCmdExample()).
+		WithGroupID(groupOther).
+		WithNoArgs().
+		Build()
+	c.RunE = func(cmd *cobra.Command, _ []string) error {
+		return cmd.Help()
+	}
+
+	c.AddCommand(
+		configGetProfilesCmd(ac),
+		configUseProfileCmd(ac),
+		configSetCmd(ac),
+	)
+	return c
+}
+
+func configCmdExample() string {
+	b := strings.Builder{}
+
+	b.WriteString("  # Create a profile for a local inventory server\n")
+	b.WriteString("  ic config set api-server http://localhost:8087 --profile local\n\n")
+
+	b.WriteString("  # Use the profile by default\n")
+	b.WriteString("  ic config use-profile local\n\n")
+
+	b.WriteString("  # Use another profile for a single command\n")
+	b.WriteString("  ic get clusters --profile production\n")
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// isConfigCmd returns true if c is the config command or one of its
+// subcommands
+func isConfigCmd(c *cobra.Command) bool {
+	for ; c != nil; c = c.Parent() {
+		if c.Name() == "config" && c.HasParent() && !c.Parent().HasParent() {
+			return true
+		}
+	}
+	return false
+}
+
+// applyProfile selects the active profile and uses its settings for all flags
+// not given on the command line
+func applyProfile(c *cobra.Command, ac *ic.Context) error {
+	if ac.Profile == "" {
+		ac.Profile = os.Getenv(envProfile)
+	}
+	cfg, err := config.Load(ac.ConfigFile)
+	if err != nil {
+		return err
+	}
+	if ac.Profile == "" {
+		ac.Profile = cfg.CurrentProfile
+	}
+	if ac.Profile == "" {
+		return nil
+	}
+	profile, err := cfg.Profile(ac.Profile)
+	if err != nil {
+		return err
+	}
+	for key, value := range profile {
+		f := c.Flags().Lookup(key)
+		if f == nil || !slices.Contains(profileKeys, key) {
+			return fmt.Errorf("profile %q: unknown setting %q", ac.Profile, key)
+		}
+		if f.Changed {
+			continue
+		}
+		if err := f.Value.Set(value); err != nil {
+			return fmt.Errorf("profile %q: setting %q: %w", ac.Profile, key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/neticdk-k8s/ic/internal/config"
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/spf13/cobra"
+)
+
+// New creates a new "config get-profiles" command
+func configGetProfilesCmd(ac *ic.Context) *cobra.Command {
+	o := &configGetProfilesOptions{}
+	c := cmd.NewSubCommand("get-profiles", o, ac).
+		WithShortDesc("List profiles").
+		WithNoArgs().
+		Build()
+	return c
+}
+
+type configGetProfilesOptions struct{}
+
+func (o *configGetProfilesOptions) Complete(_ context.Context, _ *ic.Context) error { return nil }
+func (o *configGetProfilesOptions) Validate(_ context.Context, _ *ic.Context) error { return nil }
+
+func (o *configGetProfilesOptions) Run(_ context.Context, ac *ic.Context) error {
+	cfg, err := config.Load(ac.ConfigFile)
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Reading configuration",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	r := config.NewProfilesRenderer(cfg, ac.EC.Stdout, ac.EC.PFlags.NoHeaders)
+	if err := r.Render(ac.EC.PFlags.OutputFormat); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Failed to render output",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	return nil
+}
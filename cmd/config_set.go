@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/neticdk-k8s/ic/internal/config"
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/neticdk/go-common/pkg/cli/ui"
+	"github.com/spf13/cobra"
+)
+
+const configSetExample = `
+# set the api server of the profile 'staging'
+ic config set api-server https://api.staging.example.com --profile staging
+
+# remove a setting from the current profile
+ic config set oidc-client-id ""`
+
+// New creates a new "config set" command
+func configSetCmd(ac *ic.Context) *cobra.Command {
+	o := &configSetOptions{}
+	c := cmd.NewSubCommand("set", o, ac).
+		WithShortDesc("Set a value in a profile").
+		WithLongDesc(fmt.Sprintf("Set a value in a profile. The profile is created if it does not exist.\n\nThe profile given by --profile is used, otherwise the current profile.\n\nSupported keys are: %s", strings.Join(profileKeys, ", "))).
+		WithExample(configSetExample).
+		WithExactArgs(2).
+		Build()
+	c.Use = "set KEY VALUE"
+	return c
+}
+
+type configSetOptions struct {
+	key   string
+	value string
+}
+
+func (o *configSetOptions) Complete(_ context.Context, ac *ic.Context) error {
+	o.key = ac.EC.CommandArgs[0]
+	o.value = ac.EC.CommandArgs[1]
+	return nil
+}
+
+func (o *configSetOptions) Validate(_ context.Context, ac *ic.Context) error {
+	if !slices.Contains(profileKeys, o.key) {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			fmt.Sprintf("Unknown key %q", o.key),
+			fmt.Sprintf("Use one of: %s", strings.Join(profileKeys, ", ")),
+			nil,
+			0,
+		)
+	}
+	// the value is set on a flag bound to a throwaway context so invalid
+	// values are rejected here rather than when the profile is applied
+	if f := newPersistentFlags(ic.NewContext()).Lookup(o.key); f != nil && o.value != "" {
+		if err := f.Value.Set(o.value); err != nil {
+			return ac.EC.ErrorHandler.NewGeneralError(
+				fmt.Sprintf("Invalid value %q for %s", o.value, o.key),
+				"See details for more information",
+				err,
+				0,
+			)
+		}
+	}
+	return nil
+}
+
+func (o *configSetOptions) Run(_ context.Context, ac *ic.Context) error {
+	cfg, err := config.Load(ac.ConfigFile)
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Reading configuration",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	profile := ac.Profile
+	if profile == "" {
+		profile = cfg.CurrentProfile
+	}
+	if profile == "" {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"No profile selected",
+			"Use --profile to select a profile",
+			nil,
+			0,
+		)
+	}
+
+	if err := cfg.Set(profile, o.key, o.value); err != nil {
+		return &cmd.InvalidArgumentError{
+			Flag:    "profile",
+			Val:     profile,
+			Context: err.Error(),
+		}
+	}
+	if err := cfg.Save(ac.ConfigFile); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Writing configuration",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	if o.value == "" {
+		ui.Success.Printf("Removed %s from profile %q\n", o.key, profile)
+	} else {
+		ui.Success.Printf("Set %s in profile %q\n", o.key, profile)
+	}
+	return nil
+}
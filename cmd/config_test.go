@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/neticdk-k8s/ic/internal/config"
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/tokencache"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/neticdk/go-common/pkg/cli/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newConfigTestContext(t *testing.T) (*ic.Context, *bytes.Buffer) {
+	t.Helper()
+	got := new(bytes.Buffer)
+	ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+	ec.Stderr = got
+	ec.Stdout = got
+	ui.SetDefaultOutput(got)
+	ac := ic.NewContext()
+	ac.EC = ec
+	return ac, got
+}
+
+func Test_ConfigCommands(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(envProfile, "")
+
+	run := func(t *testing.T, args ...string) (string, error) {
+		ac, got := newConfigTestContext(t)
+		cmd := newRootCmd(ac)
+		cmd.SetArgs(append(args, "--config", configFile))
+		err := cmd.ExecuteContext(context.Background())
+		return got.String(), err
+	}
+
+	t.Run("set without profile", func(t *testing.T) {
+		_, err := run(t, "config", "set", "api-server", "http://localhost:8087")
+		assert.ErrorContains(t, err, "No profile selected")
+	})
+
+	t.Run("set unknown key", func(t *testing.T) {
+		_, err := run(t, "config", "set", "unknown", "value", "--profile", "local")
+		assert.ErrorContains(t, err, `Unknown key "unknown"`)
+	})
+
+	t.Run("set invalid value", func(t *testing.T) {
+		_, err := run(t, "config", "set", "oidc-refresh-skew", "foo", "--profile", "local")
+		assert.ErrorContains(t, err, `Invalid value "foo" for oidc-refresh-skew`)
+		_, err = run(t, "config", "set", "oidc-verify-cached-token", "maybe", "--profile", "local")
+		assert.ErrorContains(t, err, `Invalid value "maybe" for oidc-verify-cached-token`)
+		_, err = run(t, "config", "set", "oidc-verify-cached-token", "", "--profile", "local")
+		assert.NoError(t, err)
+	})
+
+	t.Run("set", func(t *testing.T) {
+		out, err := run(t, "config", "set", "api-server", "http://localhost:8087", "--profile", "local")
+		assert.NoError(t, err)
+		assert.Contains(t, out, `Set api-server in profile "local"`)
+		_, err = run(t, "config", "set", "api-server", "https://api.staging.example.com", "--profile", "staging")
+		assert.NoError(t, err)
+	})
+
+	t.Run("use-profile unknown", func(t *testing.T) {
+		_, err := run(t, "config", "use-profile", "missing")
+		assert.ErrorContains(t, err, `profile "missing" not found`)
+	})
+
+	t.Run("use-profile", func(t *testing.T) {
+		out, err := run(t, "config", "use-profile", "staging")
+		assert.NoError(t, err)
+		assert.Contains(t, out, `Switched to profile "staging"`)
+
+		cfg, err := config.Load(configFile)
+		assert.NoError(t, err)
+		assert.Equal(t, "staging", cfg.CurrentProfile)
+	})
+
+	t.Run("set in current profile", func(t *testing.T) {
+		out, err := run(t, "config", "set", "oidc-client-id", "staging-cli")
+		assert.NoError(t, err)
+		assert.Contains(t, out, `Set oidc-client-id in profile "staging"`)
+	})
+
+	t.Run("get-profiles", func(t *testing.T) {
+		out, err := run(t, "config", "get-profiles")
+		assert.NoError(t, err)
+		assert.Contains(t, out, "local")
+		assert.Contains(t, out, "http://localhost:8087")
+		assert.Regexp(t, `\*\s+staging\s+https://api.staging.example.com`, out)
+	})
+
+	t.Run("get-profiles json", func(t *testing.T) {
+		out, err := run(t, "config", "get-profiles", "-o", "json")
+		assert.NoError(t, err)
+		assert.Contains(t, out, `"current": true`)
+		assert.Contains(t, out, `"oidc-client-id": "staging-cli"`)
+	})
+}
+
+func Test_applyProfile(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := config.New()
+	assert.NoError(t, cfg.Set("staging", "api-server", "https://api.staging.example.com"))
+	assert.NoError(t, cfg.Set("staging", "oidc-client-id", "staging-cli"))
+	assert.NoError(t, cfg.Set("local", "api-server", "http://localhost:8087"))
+	assert.NoError(t, cfg.UseProfile("staging"))
+	assert.NoError(t, cfg.Save(configFile))
+
+	// logout is used as it only needs the token cache and authentication
+	runLogout := func(t *testing.T, env string, args ...string) (*ic.Context, error) {
+		t.Setenv(envProfile, env)
+		ac, _ := newConfigTestContext(t)
+		mockAuthentication := authentication.NewMockAuthentication(t)
+		mockAuthentication.EXPECT().
+			SetLogger(mock.Anything).
+			Return()
+		mockTokenCache := tokencache.NewMockCache(t)
+		mockTokenCache.EXPECT().
+			Lookup(mock.Anything).
+			Return(nil, &tokencache.CacheMissError{})
+		ac.TokenCache = mockTokenCache
+		ac.Authenticator = authentication.NewAuthenticator(slog.Default(), mockAuthentication)
+		cmd := newRootCmd(ac)
+		cmd.SetArgs(append([]string{"logout", "--config", configFile}, args...))
+		err := cmd.ExecuteContext(context.Background())
+		return ac, err
+	}
+
+	t.Run("current profile", func(t *testing.T) {
+		ac, _ := runLogout(t, "")
+		assert.Equal(t, "staging", ac.Profile)
+		assert.Equal(t, "https://api.staging.example.com", ac.APIServer)
+		assert.Equal(t, "staging-cli", ac.OIDC.ClientID)
+		mockTokenCache := ac.TokenCache.(*tokencache.MockCache)
+		mockTokenCache.AssertCalled(t, "Lookup", tokencache.Key{
+			IssuerURL:   ac.OIDC.IssuerURL,
+			ClientID:    "staging-cli",
+			ExtraScopes: ac.OIDCProvider.ExtraScopes,
+			Profile:     "staging",
+		})
+	})
+
+	t.Run("profile flag", func(t *testing.T) {
+		ac, _ := runLogout(t, "staging", "--profile", "local")
+		assert.Equal(t, "local", ac.Profile)
+		assert.Equal(t, "http://localhost:8087", ac.APIServer)
+		assert.Equal(t, "inventory-cli", ac.OIDC.ClientID)
+	})
+
+	t.Run("profile env", func(t *testing.T) {
+		ac, _ := runLogout(t, "local")
+		assert.Equal(t, "local", ac.Profile)
+		assert.Equal(t, "http://localhost:8087", ac.APIServer)
+	})
+
+	t.Run("flags override profile", func(t *testing.T) {
+		ac, _ := runLogout(t, "", "--api-server", "https://api.example.com")
+		assert.Equal(t, "staging", ac.Profile)
+		assert.Equal(t, "https://api.example.com", ac.APIServer)
+		assert.Equal(t, "staging-cli", ac.OIDC.ClientID)
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		t.Setenv(envProfile, "")
+		ac, _ := newConfigTestContext(t)
+		cmd := newRootCmd(ac)
+		cmd.SetArgs([]string{"logout", "--config", configFile, "--profile", "missing"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.ErrorContains(t, err, `profile "missing" not found`)
+	})
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/neticdk-k8s/ic/internal/config"
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/neticdk/go-common/pkg/cli/ui"
+	"github.com/spf13/cobra"
+)
+
+// New creates a new "config use-profile" command
+func configUseProfileCmd(ac *ic.Context) *cobra.Command {
+	o := &configUseProfileOptions{}
+	c := cmd.NewSubCommand("use-profile", o, ac).
+		WithShortDesc("Set the current profile").
+		WithExactArgs(1).
+		Build()
+	c.Use = "use-profile NAME"
+	return c
+}
+
+type configUseProfileOptions struct {
+	name string
+}
+
+func (o *configUseProfileOptions) Complete(_ context.Context, ac *ic.Context) error {
+	o.name = ac.EC.CommandArgs[0]
+	return nil
+}
+
+func (o *configUseProfileOptions) Validate(_ context.Context, _ *ic.Context) error { return nil }
+
+func (o *configUseProfileOptions) Run(_ context.Context, ac *ic.Context) error {
+	cfg, err := config.Load(ac.ConfigFile)
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Reading configuration",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	if err := cfg.UseProfile(o.name); err != nil {
+		var notFound *config.ProfileNotFoundError
+		if errors.As(err, &notFound) {
+			return ac.EC.ErrorHandler.NewGeneralError(
+				err.Error(),
+				"Use 'ic config get-profiles' to list profiles",
+				nil,
+				0,
+			)
+		}
+		return err
+	}
+	if err := cfg.Save(ac.ConfigFile); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Writing configuration",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	ui.Success.Printf("Switched to profile %q\n", o.name)
+	return nil
+}
@@ -175,10 +175,11 @@ func (o *createClusterOptions) Run(ctx context.Context, ac *ic.Context) error {
 	logger := ac.EC.Logger.WithGroup("Clusters")
 	ac.Authenticator.SetLogger(logger)
 
-	_, err := doLogin(ctx, ac)
+	tokenSet, err := doLogin(ctx, ac)
 	if err != nil {
 		return err
 	}
+	warnMissingRole(ac, tokenSet, "create clusters")
 
 	var result *cluster.CreateClusterResult
 	spinnerText := fmt.Sprintf("Creating cluster %s", o.Name)
@@ -222,7 +223,7 @@ func (o *createClusterOptions) Run(ctx context.Context, ac *ic.Context) error {
 		)
 	}
 
-	r := cluster.NewClusterRenderer(result.ClusterResponse, result.JSONResponse, ac.EC.Stdout)
+	r := cluster.NewClusterRenderer(result.ClusterResponse, result.JSONResponse, ac.EC.Stdout, nil)
 	if err := r.Render(ac.EC.PFlags.OutputFormat); err != nil {
 		return ac.EC.ErrorHandler.NewGeneralError(
 			"Failed to render output",
@@ -45,10 +45,11 @@ func (o *deleteClusterOptions) Run(ctx context.Context, ac *ic.Context) error {
 		}
 	}
 
-	_, err := doLogin(ctx, ac)
+	tokenSet, err := doLogin(ctx, ac)
 	if err != nil {
 		return err
 	}
+	warnMissingRole(ac, tokenSet, "delete clusters")
 
 	var result *cluster.DeleteClusterResult
 	spinnerText := fmt.Sprintf("Deleting cluster %s", o.clusterID)
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/render"
 	"github.com/neticdk/go-common/pkg/cli/cmd"
 	"github.com/spf13/cobra"
 )
@@ -26,12 +28,16 @@ func getCmd(ac *ic.Context) *cobra.Command {
 		getClusterCmd(ac),
 		getClusterNodesCmd(ac),
 		getClusterNodeCmd(ac),
+		getClusterPodsCmd(ac),
 		getClusterKubeconfigCmd(ac),
+		getKubeConfigsCmd(ac),
 		getPartitionsCmd(ac),
 		getRegionsCmd(ac),
 		getResilienceZonesCmd(ac),
 		getComponentsCmd(ac),
 		getComponentCmd(ac),
+		getResourcesCmd(ac),
+		getResourceCmd(ac),
 	)
 
 	c.AddGroup(
@@ -43,6 +49,10 @@ func getCmd(ac *ic.Context) *cobra.Command {
 			ID:    groupComponent,
 			Title: "Component Commands:",
 		},
+		&cobra.Group{
+			ID:    groupResource,
+			Title: "Resource Commands:",
+		},
 		&cobra.Group{
 			ID:    groupOther,
 			Title: "Other Commands:",
@@ -51,6 +61,31 @@ func getCmd(ac *ic.Context) *cobra.Command {
 	return c
 }
 
+const columnsFlagUsage = "Comma separated list of columns shown in table, csv, tsv and markdown output"
+
+const (
+	sortByFlagUsage  = "Sort by a field of the json output given as a JSONPath, e.g. %s"
+	reverseFlagUsage = "Sort in descending order"
+)
+
+// parseSortBy parses the value of --sort-by. It returns nil if no field is
+// given
+func parseSortBy(ac *ic.Context, expr string) (*render.Field, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	field, err := render.ParseField(expr)
+	if err != nil {
+		return nil, ac.EC.ErrorHandler.NewGeneralError(
+			fmt.Sprintf("Invalid sort field %q", expr),
+			"Use a field of the json output, e.g. kubernetes_version",
+			err,
+			0,
+		)
+	}
+	return field, nil
+}
+
 func getCmdExample() string {
 	b := strings.Builder{}
 
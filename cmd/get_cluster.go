@@ -3,12 +3,16 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/neticdk-k8s/ic/internal/apiclient"
 	"github.com/neticdk-k8s/ic/internal/ic"
 	"github.com/neticdk-k8s/ic/internal/usecases/cluster"
 	"github.com/neticdk/go-common/pkg/cli/cmd"
 	"github.com/neticdk/go-common/pkg/cli/ui"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // New creates a new "get cluster" command
@@ -16,15 +20,49 @@ func getClusterCmd(ac *ic.Context) *cobra.Command {
 	o := &getClusterOptions{}
 	c := cmd.NewSubCommand("cluster", o, ac).
 		WithShortDesc("Get a cluster").
+		WithLongDesc(getClusterCmdLongDesc()).
+		WithExample(getClusterCmdExample()).
 		WithGroupID(groupCluster).
 		WithExactArgs(1).
 		Build()
 	c.Use = "cluster CLUSTER-ID" //nolint:goconst
+	o.bindFlags(c.Flags())
 	return c
 }
 
+func getClusterCmdLongDesc() string {
+	b := strings.Builder{}
+	b.WriteString("Get a cluster.\n\n")
+	b.WriteString("The information is grouped in sections selected using --show:\n\n")
+	b.WriteString("  base      the cluster, its placement, versions and references\n")
+	b.WriteString("  capacity  the allocatable capacity of each node role\n")
+	b.WriteString("  links     the collections linked from the cluster, e.g. pods and images,\n")
+	b.WriteString("            with the number of items in each. The collections are fetched\n")
+	b.WriteString("            from the inventory server\n")
+	b.WriteString("  all       all of the above\n\n")
+	b.WriteString("The links are also followed for JSON output when the links section is selected.")
+	return b.String()
+}
+
+func getClusterCmdExample() string {
+	b := strings.Builder{}
+	b.WriteString("# get a cluster\n")
+	b.WriteString("ic get cluster my-cluster.my-provider\n\n")
+	b.WriteString("# get a cluster including the number of pods, images, etc.\n")
+	b.WriteString("ic get cluster my-cluster.my-provider --show all\n\n")
+	b.WriteString("# get only the capacity of a cluster\n")
+	b.WriteString("ic get cluster my-cluster.my-provider --show capacity")
+	return b.String()
+}
+
 type getClusterOptions struct {
 	clusterID string
+	// show are the sections shown
+	show []string
+}
+
+func (o *getClusterOptions) bindFlags(f *pflag.FlagSet) {
+	f.StringSliceVar(&o.show, "show", cluster.DefaultSections, fmt.Sprintf("Sections to show. One or more of (%s|%s)", strings.Join(cluster.Sections, "|"), cluster.SectionAll))
 }
 
 func (o *getClusterOptions) Complete(_ context.Context, ac *ic.Context) error {
@@ -32,7 +70,24 @@ func (o *getClusterOptions) Complete(_ context.Context, ac *ic.Context) error {
 	return nil
 }
 
-func (o *getClusterOptions) Validate(_ context.Context, _ *ic.Context) error { return nil }
+func (o *getClusterOptions) Validate(_ context.Context, ac *ic.Context) error {
+	for _, section := range o.show {
+		if section != cluster.SectionAll && !slices.Contains(cluster.Sections, section) {
+			return ac.EC.ErrorHandler.NewGeneralError(
+				fmt.Sprintf("Unknown section %q", section),
+				fmt.Sprintf("Use one or more of: %s, %s", strings.Join(cluster.Sections, ", "), cluster.SectionAll),
+				nil,
+				0,
+			)
+		}
+	}
+	return nil
+}
+
+// followLinks returns true if the links section is shown
+func (o *getClusterOptions) followLinks() bool {
+	return slices.Contains(o.show, cluster.SectionLinks) || slices.Contains(o.show, cluster.SectionAll)
+}
 
 func (o *getClusterOptions) Run(ctx context.Context, ac *ic.Context) error {
 	logger := ac.EC.Logger.WithGroup("Clusters")
@@ -50,6 +105,9 @@ func (o *getClusterOptions) Run(ctx context.Context, ac *ic.Context) error {
 			Logger:    logger,
 			APIClient: ac.APIClient,
 		}
+		if f, ok := ac.APIClient.(apiclient.LinkFollower); ok && o.followLinks() {
+			in.LinkFollower = f
+		}
 		result, err = cluster.GetCluster(ctx, o.clusterID, in)
 		return err
 	}); err != nil {
@@ -70,7 +128,7 @@ func (o *getClusterOptions) Run(ctx context.Context, ac *ic.Context) error {
 		)
 	}
 
-	r := cluster.NewClusterRenderer(result.ClusterResponse, result.JSONResponse, ac.EC.Stdout)
+	r := cluster.NewClusterRenderer(result.ClusterResponse, result.JSONResponse, ac.EC.Stdout, o.show)
 	if err := r.Render(ac.EC.PFlags.OutputFormat); err != nil {
 		return ac.EC.ErrorHandler.NewGeneralError(
 			"Failed to render output",
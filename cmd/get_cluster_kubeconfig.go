@@ -2,9 +2,15 @@ package cmd
 
 import (
 	"context"
+	goerr "errors"
+	"fmt"
+	"os"
+	"text/template"
 
 	"github.com/neticdk-k8s/ic/internal/errors"
 	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/kubeconfig"
+	"github.com/neticdk-k8s/ic/internal/render"
 	"github.com/neticdk-k8s/ic/internal/usecases/cluster"
 	"github.com/neticdk/go-common/pkg/cli/cmd"
 	"github.com/neticdk/go-common/pkg/cli/ui"
@@ -14,7 +20,13 @@ import (
 
 const getClusterKubeConfigExample = `
 # get the kubeconfig for a cluster
-ic get cluster-kubeconfig --cluster-name my-cluster.my-provider`
+ic get cluster-kubeconfig --cluster-id my-cluster.my-provider
+
+# merge the kubeconfig for a cluster into $KUBECONFIG or ~/.kube/config
+ic get cluster-kubeconfig --cluster-id my-cluster.my-provider --merge --set-current
+
+# merge using a custom context name
+ic get cluster-kubeconfig --cluster-id my-cluster.my-provider --merge --context-name "{{.Provider}}-{{.Name}}"`
 
 func getClusterKubeconfigCmd(ac *ic.Context) *cobra.Command {
 	o := &getClusterKubeConfigOptions{}
@@ -33,15 +45,55 @@ func getClusterKubeconfigCmd(ac *ic.Context) *cobra.Command {
 type getClusterKubeConfigOptions struct {
 	clusterID   string
 	clusterName string
+	merge       bool
+	setCurrent  bool
+	contextName string
+	contextTmpl *template.Template
 }
 
 func (o *getClusterKubeConfigOptions) bindFlags(f *pflag.FlagSet) {
 	f.StringVar(&o.clusterID, "cluster-id", "", "The id of the cluster")
 	f.StringVar(&o.clusterName, "cluster-name", "", "The id of the cluster. Use cluster-id instead")
+	f.BoolVar(&o.merge, "merge", false, "Merge into $KUBECONFIG or ~/.kube/config instead of printing")
+	f.BoolVar(&o.setCurrent, "set-current", false, "Set the current context to the merged context. Requires --merge")
+	f.StringVar(&o.contextName, "context-name", kubeconfig.DefaultContextNameTemplate, "Template used to name merged contexts. Fields: .ClusterID, .Name, .Provider, .Context")
 }
 
 func (o *getClusterKubeConfigOptions) Complete(_ context.Context, _ *ic.Context) error { return nil }
-func (o *getClusterKubeConfigOptions) Validate(_ context.Context, _ *ic.Context) error { return nil }
+
+func (o *getClusterKubeConfigOptions) Validate(_ context.Context, ac *ic.Context) error {
+	flags := ac.EC.Command.Flags()
+	if !o.merge {
+		for _, f := range []string{"set-current", "context-name"} {
+			if flags.Changed(f) {
+				return &cmd.InvalidArgumentError{
+					Flag:    f,
+					Val:     flags.Lookup(f).Value.String(),
+					Context: "requires --merge",
+				}
+			}
+		}
+		return nil
+	}
+	tmpl, err := parseContextNameTemplate(o.contextName)
+	if err != nil {
+		return err
+	}
+	o.contextTmpl = tmpl
+	return nil
+}
+
+func parseContextNameTemplate(contextName string) (*template.Template, error) {
+	tmpl, err := template.New("context-name").Option("missingkey=error").Parse(contextName)
+	if err != nil {
+		return nil, &cmd.InvalidArgumentError{
+			Flag:    "context-name",
+			Val:     contextName,
+			Context: "must be a valid template",
+		}
+	}
+	return tmpl, nil
+}
 
 func (o *getClusterKubeConfigOptions) Run(ctx context.Context, ac *ic.Context) error {
 	logger := ac.EC.Logger.WithGroup("ClusterKubeConfig")
@@ -56,12 +108,13 @@ func (o *getClusterKubeConfigOptions) Run(ctx context.Context, ac *ic.Context) e
 		return err
 	}
 
+	clusterID := o.clusterName
+	if o.clusterID != "" {
+		clusterID = o.clusterID
+	}
+
 	var result *cluster.GetClusterKubeConfigResult
 	if err := ui.Spin(ac.EC.Spinner, "Getting kubeconfig", func(_ ui.Spinner) error {
-		clusterID := o.clusterName
-		if o.clusterID != "" {
-			clusterID = o.clusterID
-		}
 		in := cluster.GetClusterKubeConfigInput{
 			Logger:    logger,
 			APIClient: ac.APIClient,
@@ -84,8 +137,12 @@ func (o *getClusterKubeConfigOptions) Run(ctx context.Context, ac *ic.Context) e
 		}
 	}
 
-	if ac.EC.PFlags.OutputFormat != "json" {
-		ac.EC.PFlags.OutputFormat = "yaml"
+	if o.merge {
+		return o.mergeKubeConfig(ac, clusterID, result.Response)
+	}
+
+	if ac.EC.PFlags.OutputFormat != render.FormatJSON {
+		ac.EC.PFlags.OutputFormat = render.FormatYAML
 	}
 	r := cluster.NewClusterKubeConfigRenderer(result.Response, ac.EC.Stdout)
 	if err := r.Render(ac.EC.PFlags.OutputFormat); err != nil {
@@ -99,3 +156,90 @@ func (o *getClusterKubeConfigOptions) Run(ctx context.Context, ac *ic.Context) e
 
 	return nil
 }
+
+func (o *getClusterKubeConfigOptions) mergeKubeConfig(ac *ic.Context, clusterID string, data []byte) error {
+	fetched, err := kubeconfig.Parse(data)
+	if err == nil {
+		err = fetched.RenameContexts(o.contextTmpl, kubeconfig.NewContextNameData(clusterID))
+	}
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Merging kubeconfig",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+	return mergeIntoDefaultKubeConfig(ac, fetched, o.setCurrent)
+}
+
+// mergeIntoDefaultKubeConfig merges fetched into $KUBECONFIG or ~/.kube/config
+func mergeIntoDefaultKubeConfig(ac *ic.Context, fetched *kubeconfig.Config, setCurrent bool) error {
+	path, err := kubeconfig.DefaultPath()
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Merging kubeconfig",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+	target, err := kubeconfig.Load(path)
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Merging kubeconfig",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	result, err := target.Merge(fetched, ac.EC.PFlags.Force)
+	if err != nil {
+		var conflictErr *kubeconfig.ConflictError
+		if goerr.As(err, &conflictErr) {
+			return ac.EC.ErrorHandler.NewGeneralError(
+				fmt.Sprintf("Kubeconfig %s has conflicting entries", path),
+				"Use --force to overwrite them or --context-name to use a different name",
+				err,
+				0,
+			)
+		}
+		return err
+	}
+	if setCurrent && len(fetched.Contexts) > 0 {
+		current := fetched.Contexts[0].Name
+		if fetched.CurrentContext != "" {
+			current = fetched.CurrentContext
+		}
+		if target.CurrentContext != current {
+			target.CurrentContext = current
+			result.Updated = append(result.Updated, fmt.Sprintf("current-context %q", current))
+		}
+	}
+	if !result.Changed() {
+		ui.Info.Printf("Kubeconfig %s is up to date\n", path)
+		return nil
+	}
+
+	_, statErr := os.Stat(path)
+	if err := target.Save(path, true); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Merging kubeconfig",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+	for _, e := range result.Updated {
+		ui.Info.Printf("Updated %s\n", e)
+	}
+	for _, e := range result.Added {
+		ui.Info.Printf("Added %s\n", e)
+	}
+	if statErr == nil {
+		ui.Info.Printf("Previous kubeconfig saved to %s\n", kubeconfig.BackupPath(path))
+	}
+	ui.Success.Printf("Merged kubeconfig into %s\n", path)
+	return nil
+}
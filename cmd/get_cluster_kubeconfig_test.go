@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/neticdk-k8s/ic/internal/apiclient"
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/kubeconfig"
+	"github.com/neticdk-k8s/ic/internal/oidc"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/neticdk/go-common/pkg/cli/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testGetKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: kubernetes
+  cluster:
+    server: https://my-cluster.example.com:6443
+contexts:
+- name: admin@kubernetes
+  context:
+    cluster: kubernetes
+    user: admin
+current-context: admin@kubernetes
+users:
+- name: admin
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: kubectl
+`
+
+func newMockedGetClusterKubeConfigEC(t *testing.T) (*ic.Context, *bytes.Buffer) {
+	got := new(bytes.Buffer)
+	ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+	ec.Stderr = got
+	ec.Stdout = got
+	ui.SetDefaultOutput(got)
+	ac := ic.NewContext()
+	ac.EC = ec
+	mockAuthenticator := authentication.NewMockAuthenticator(t)
+	mockAuthenticator.EXPECT().
+		SetLogger(mock.Anything).
+		Run(func(_ *slog.Logger) {}).
+		Return()
+	mockAuthenticator.EXPECT().
+		Login(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, in authentication.LoginInput) {}).
+		Return(&oidc.TokenSet{
+			AccessToken:  "YOUR_ACCESS_TOKEN",
+			IDToken:      "YOUR_ID_TOKEN",
+			RefreshToken: "YOUR_REFRESH_TOKEN",
+		}, nil)
+	ac.Authenticator = mockAuthenticator
+	mockClientWithResponsesInterface := apiclient.NewMockClientWithResponsesInterface(t)
+	mockClientWithResponsesInterface.EXPECT().
+		GetClusterKubeConfigWithResponse(mock.Anything, "my-cluster.my-provider").
+		Return(
+			&apiclient.GetClusterKubeConfigResponse{
+				Body: []byte(testGetKubeConfig),
+				HTTPResponse: &http.Response{
+					Status:     "200 OK",
+					StatusCode: 200,
+				},
+			}, nil)
+	ac.APIClient = mockClientWithResponsesInterface
+	return ac, got
+}
+
+func Test_GetClusterKubeConfigCommand(t *testing.T) {
+	ac, got := newMockedGetClusterKubeConfigEC(t)
+	cmd := newRootCmd(ac)
+	cmd.SetArgs([]string{"get", "cluster-kubeconfig", "--cluster-id", "my-cluster.my-provider"})
+	err := cmd.ExecuteContext(context.Background())
+	assert.NoError(t, err)
+	assert.Contains(t, got.String(), "server: https://my-cluster.example.com:6443")
+}
+
+func Test_GetClusterKubeConfigCommandMerge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	t.Setenv("KUBECONFIG", path)
+
+	ac, got := newMockedGetClusterKubeConfigEC(t)
+	cmd := newRootCmd(ac)
+	cmd.SetArgs([]string{"get", "cluster-kubeconfig", "--cluster-id", "my-cluster.my-provider", "--merge", "--set-current"})
+	err := cmd.ExecuteContext(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, got.String(), "Merged kubeconfig into "+path)
+
+	merged, err := kubeconfig.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "my-cluster.my-provider", merged.CurrentContext)
+	assert.Equal(t, "my-cluster.my-provider", merged.Contexts[0].Name)
+	_, err = os.Stat(kubeconfig.BackupPath(path))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func Test_GetClusterKubeConfigCommandMergeConflict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	t.Setenv("KUBECONFIG", path)
+	existing := kubeconfig.New()
+	existing.Clusters = []kubeconfig.NamedCluster{
+		{
+			Name:    "my-cluster.my-provider",
+			Cluster: map[string]any{"server": "https://old.example.com:6443"},
+		},
+	}
+	require.NoError(t, existing.Save(path, false))
+
+	ac, _ := newMockedGetClusterKubeConfigEC(t)
+	cmd := newRootCmd(ac)
+	cmd.SetArgs([]string{"get", "cluster-kubeconfig", "--cluster-id", "my-cluster.my-provider", "--merge"})
+	err := cmd.ExecuteContext(context.Background())
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `cluster "my-cluster.my-provider"`)
+
+	ac, _ = newMockedGetClusterKubeConfigEC(t)
+	cmd = newRootCmd(ac)
+	cmd.SetArgs([]string{"get", "cluster-kubeconfig", "--cluster-id", "my-cluster.my-provider", "--merge", "--force"})
+	err = cmd.ExecuteContext(context.Background())
+	require.NoError(t, err)
+	backup, err := kubeconfig.Load(kubeconfig.BackupPath(path))
+	require.NoError(t, err)
+	assert.Equal(t, existing, backup)
+}
+
+func Test_GetClusterKubeConfigCommandSetCurrentWithoutMerge(t *testing.T) {
+	got := new(bytes.Buffer)
+	ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+	ec.Stderr = got
+	ec.Stdout = got
+	ui.SetDefaultOutput(got)
+	ac := ic.NewContext()
+	ac.EC = ec
+	cmd := newRootCmd(ac)
+	cmd.SetArgs([]string{"get", "cluster-kubeconfig", "--cluster-id", "my-cluster.my-provider", "--set-current"})
+	err := cmd.ExecuteContext(context.Background())
+	assert.Error(t, err)
+}
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/render"
 	"github.com/neticdk-k8s/ic/internal/usecases/cluster"
 	"github.com/neticdk/go-common/pkg/cli/cmd"
 	"github.com/neticdk/go-common/pkg/cli/ui"
@@ -12,6 +14,12 @@ import (
 	"github.com/spf13/pflag"
 )
 
+var getClusterNodesFilterNames = []string{
+	"name", "role", "criName", "criVersion", "controlPlane", "topologyRegion",
+	"topologyZone", "memoryAllocatableBytes", "cpuAllocatableMillis",
+	"memoryCapacityBytes", "cpuCapacityMillis",
+}
+
 const getClusterNodesLongDesc = `Get list of nodes for a nodes.
 
 Supported field names for filters:
@@ -56,15 +64,30 @@ type getClusterNodesOptions struct {
 	// =~ (or ~) - matches (case insensitive regular expression)
 	// !~        - does not match (case insensitive expression)
 	Filters []string
+	// Columns are the columns shown in table, csv, tsv and markdown output
+	Columns []string
+	// SortBy is the field of the json output used to sort the list
+	SortBy string
+	// Reverse sorts the list in descending order
+	Reverse bool
+
+	sortBy *render.Field
 }
 
 func (o *getClusterNodesOptions) bindFlags(f *pflag.FlagSet) {
 	f.StringVar(&o.clusterName, "cluster-name", "", "The name of the cluster")
 	f.StringArrayVar(&o.Filters, "filter", []string{}, "Filter output based on conditions")
+	f.StringSliceVar(&o.Columns, "columns", nil, columnsFlagUsage)
+	f.StringVar(&o.SortBy, "sort-by", "", fmt.Sprintf(sortByFlagUsage, "capacity_cpu_millis"))
+	f.BoolVar(&o.Reverse, "reverse", false, reverseFlagUsage)
 }
 
 func (o *getClusterNodesOptions) Complete(_ context.Context, _ *ic.Context) error { return nil }
-func (o *getClusterNodesOptions) Validate(_ context.Context, _ *ic.Context) error { return nil }
+func (o *getClusterNodesOptions) Validate(_ context.Context, ac *ic.Context) error {
+	var err error
+	o.sortBy, err = parseSortBy(ac, o.SortBy)
+	return err
+}
 
 func (o *getClusterNodesOptions) Run(ctx context.Context, ac *ic.Context) error {
 	logger := ac.EC.Logger.WithGroup("ClusterNodes")
@@ -77,7 +100,7 @@ func (o *getClusterNodesOptions) Run(ctx context.Context, ac *ic.Context) error
 
 	searchFields := make(map[string]*qsparser.SearchField)
 	for _, f := range o.Filters {
-		out, err := parseFilter(f)
+		out, err := parseFilter(f, getClusterNodesFilterNames)
 		if err != nil {
 			return err
 		}
@@ -93,6 +116,8 @@ func (o *getClusterNodesOptions) Run(ctx context.Context, ac *ic.Context) error
 			PerPage:     PerPage,
 			Filters:     searchFields,
 			ClusterName: o.clusterName,
+			SortBy:      o.sortBy,
+			Reverse:     o.Reverse,
 		}
 		result, err = cluster.ListClusterNodes(ctx, in)
 		return err
@@ -114,6 +139,7 @@ func (o *getClusterNodesOptions) Run(ctx context.Context, ac *ic.Context) error
 	}
 
 	r := cluster.NewClusterNodesRenderer(result.ClusterNodeListResponse, result.JSONResponse, ac.EC.Stdout, ac.EC.PFlags.NoHeaders)
+	r.Columns = o.Columns
 	if err := r.Render(ac.EC.PFlags.OutputFormat); err != nil {
 		return ac.EC.ErrorHandler.NewGeneralError(
 			"Failed to render output",
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/usecases/cluster"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/neticdk/go-common/pkg/cli/ui"
+	"github.com/neticdk/go-common/pkg/qsparser"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var getClusterPodsFilterNames = []string{
+	"name", "namespace", "nodeName", "owner",
+}
+
+const getClusterPodsLongDesc = `Get list of pods running in a cluster.
+
+Supported field names for filters:
+
+name, namespace, nodeName, owner
+`
+
+const getClusterPodsExample = `
+# get pods for my-cluster.my-provider
+ic get cluster-pods my-cluster.my-provider
+
+# get pods in the namespace 'kube-system'
+ic get cluster-pods my-cluster.my-provider --filter namespace=kube-system
+
+use: 'ic help filters' for more information on using filters`
+
+func getClusterPodsCmd(ac *ic.Context) *cobra.Command {
+	o := &getClusterPodsOptions{}
+	c := cmd.NewSubCommand("cluster-pods", o, ac).
+		WithShortDesc("Get list of pods in a cluster").
+		WithLongDesc(getClusterPodsLongDesc).
+		WithExample(getClusterPodsExample).
+		WithGroupID(groupCluster).
+		WithExactArgs(1).
+		Build()
+	c.Use = "cluster-pods CLUSTER-ID"
+	c.Aliases = []string{"pods"}
+
+	o.bindFlags(c.Flags())
+	return c
+}
+
+type getClusterPodsOptions struct {
+	clusterID string
+	// A filter has the form: fieldName operator value (e.g. name=Peter)
+	//
+	// Supported operators:
+	// == (or =) - equals
+	// != (or !) - not equals
+	// >         - greater than
+	// <         - less than
+	// >=        - greater than or equals
+	// <=        - less than or equals
+	// =~ (or ~) - matches (case insensitive regular expression)
+	// !~        - does not match (case insensitive expression)
+	Filters []string
+	// Columns are the columns shown in table, csv, tsv and markdown output
+	Columns []string
+}
+
+func (o *getClusterPodsOptions) bindFlags(f *pflag.FlagSet) {
+	f.StringArrayVar(&o.Filters, "filter", []string{}, "Filter output based on conditions")
+	f.StringSliceVar(&o.Columns, "columns", nil, columnsFlagUsage)
+}
+
+func (o *getClusterPodsOptions) Complete(_ context.Context, ac *ic.Context) error {
+	o.clusterID = ac.EC.CommandArgs[0]
+	return nil
+}
+
+func (o *getClusterPodsOptions) Validate(_ context.Context, _ *ic.Context) error { return nil }
+
+func (o *getClusterPodsOptions) Run(ctx context.Context, ac *ic.Context) error {
+	logger := ac.EC.Logger.WithGroup("ClusterPods")
+	ac.Authenticator.SetLogger(logger)
+
+	_, err := doLogin(ctx, ac)
+	if err != nil {
+		return err
+	}
+
+	searchFields := make(map[string]*qsparser.SearchField)
+	for _, f := range o.Filters {
+		out, err := parseFilter(f, getClusterPodsFilterNames)
+		if err != nil {
+			return err
+		}
+		searchFields[out.FieldName] = out.SearchField
+	}
+
+	var result *cluster.ListClusterPodsResults
+
+	spinnerText := fmt.Sprintf("Getting pods for cluster %q", o.clusterID)
+	if err := ui.Spin(ac.EC.Spinner, spinnerText, func(_ ui.Spinner) error {
+		in := cluster.ListClusterPodsInput{
+			Logger:    logger,
+			APIClient: ac.APIClient,
+			PerPage:   PerPage,
+			Filters:   searchFields,
+			ClusterID: o.clusterID,
+		}
+		result, err = cluster.ListClusterPods(ctx, in)
+		return err
+	}); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Listing cluster pods",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+	if result.Problem != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			*result.Problem.Title,
+			*result.Problem.Detail,
+			nil,
+			0,
+		)
+	}
+
+	r := cluster.NewClusterPodsRenderer(result.ClusterPodListResponse, result.JSONResponse, ac.EC.Stdout, ac.EC.PFlags.NoHeaders)
+	r.Columns = o.Columns
+	if err := r.Render(ac.EC.PFlags.OutputFormat); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Failed to render output",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	return nil
+}
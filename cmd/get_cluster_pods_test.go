@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/neticdk-k8s/ic/internal/apiclient"
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/oidc"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/neticdk/go-common/pkg/cli/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_GetClusterPodsCommand(t *testing.T) {
+	got := new(bytes.Buffer)
+	ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+	ec.Stderr = got
+	ec.Stdout = got
+	ui.SetDefaultOutput(got)
+	ac := ic.NewContext()
+	ac.EC = ec
+	mockAuthenticator := authentication.NewMockAuthenticator(t)
+	mockAuthenticator.EXPECT().
+		SetLogger(mock.Anything).
+		Run(func(_ *slog.Logger) {}).
+		Return()
+	mockAuthenticator.EXPECT().
+		Login(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, in authentication.LoginInput) {}).
+		Return(&oidc.TokenSet{
+			AccessToken:  "YOUR_ACCESS_TOKEN",
+			IDToken:      "YOUR_ID_TOKEN",
+			RefreshToken: "YOUR_REFRESH_TOKEN",
+		}, nil)
+	ac.Authenticator = mockAuthenticator
+	pods := []string{"my-pod-id"}
+	included := []map[string]interface{}{
+		{
+			"@id":       "my-pod-id",
+			"@type":     "Pod",
+			"name":      "my-pod",
+			"namespace": "my-namespace",
+			"nodeName":  "my-node",
+		},
+	}
+	mockClientWithResponsesInterface := apiclient.NewMockClientWithResponsesInterface(t)
+	mockClientWithResponsesInterface.EXPECT().
+		ListPodsWithResponse(mock.Anything, "my-cluster.my-provider", mock.Anything).
+		Return(
+			&apiclient.ListPodsResponse{
+				Body: make([]byte, 0),
+				HTTPResponse: &http.Response{
+					Status:     "200 OK",
+					StatusCode: 200,
+				},
+				ApplicationldJSONDefault: &apiclient.Pods{
+					Pods:       &pods,
+					Included:   &included,
+					Pagination: &apiclient.Pagination{},
+				},
+			}, nil)
+	apiClient := mockClientWithResponsesInterface
+	ac.APIClient = apiClient
+
+	cmd := newRootCmd(ac)
+
+	t.Run("get cluster-pods", func(t *testing.T) {
+		cmd.SetArgs([]string{"get", "cluster-pods", "my-cluster.my-provider"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+		assert.Contains(t, got.String(), "Logging in")
+		assert.Contains(t, got.String(), "Getting pods")
+		assert.Contains(t, got.String(), "my-pod")
+		assert.Contains(t, got.String(), "my-node")
+	})
+
+	t.Run("get cluster-pods -o json", func(t *testing.T) {
+		cmd.SetArgs([]string{"get", "cluster-pods", "my-cluster.my-provider", "-o", "json"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+		assert.Contains(t, got.String(), "\"name\": \"my-pod\"")
+	})
+
+	t.Run("get cluster-pods with unknown filter", func(t *testing.T) {
+		cmd.SetArgs([]string{"get", "cluster-pods", "my-cluster.my-provider", "--filter", "unknown=value"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown field name")
+	})
+}
@@ -3,8 +3,10 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"io"
 	"log/slog"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/neticdk-k8s/ic/internal/apiclient"
@@ -65,6 +67,7 @@ func Test_GetClusterCommand(t *testing.T) {
 	}
 	name := "my-cluster"
 	providerId := "my-provider-id"
+	pods := "/clusters/my-cluster/pods"
 	mockClientWithResponsesInterface := apiclient.NewMockClientWithResponsesInterface(t)
 	mockClientWithResponsesInterface.EXPECT().
 		GetClusterWithResponse(mock.Anything, mock.Anything).
@@ -79,10 +82,13 @@ func Test_GetClusterCommand(t *testing.T) {
 					Name:     &name,
 					Provider: &providerId,
 					Included: &included,
+					Pods:     &pods,
 				},
 			}, nil)
-	apiClient := mockClientWithResponsesInterface
-	ac.APIClient = apiClient
+	ac.APIClient = &linkFollowingClient{
+		MockClientWithResponsesInterface: mockClientWithResponsesInterface,
+		body:                             `{"count":2,"total":42}`,
+	}
 
 	cmd := newRootCmd(ac)
 
@@ -103,4 +109,30 @@ func Test_GetClusterCommand(t *testing.T) {
 		assert.Contains(t, got.String(), "\"name\": \"my-cluster\"")
 		assert.Contains(t, got.String(), "\"provider_name\": \"my-provider\"")
 	})
+
+	t.Run("get cluster my-cluster --show links", func(t *testing.T) {
+		got.Reset()
+		cmd.SetArgs([]string{"get", "cluster", "my-cluster", "--show", "links", "-o", "plain"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+		assert.NotContains(t, got.String(), "Base Information")
+		assert.Contains(t, got.String(), "42 (/clusters/my-cluster/pods)")
+	})
+
+	t.Run("get cluster my-cluster --show nodes", func(t *testing.T) {
+		cmd.SetArgs([]string{"get", "cluster", "my-cluster", "--show", "nodes"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.ErrorContains(t, err, "Unknown section")
+	})
+}
+
+// linkFollowingClient is a mocked api client which can follow links. All
+// links refer to a collection with body
+type linkFollowingClient struct {
+	*apiclient.MockClientWithResponsesInterface
+	body string
+}
+
+func (c *linkFollowingClient) FollowLink(_ context.Context, _ string, _ ...apiclient.RequestEditorFn) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(c.body))}, nil
 }
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/render"
 	"github.com/neticdk-k8s/ic/internal/usecases/cluster"
 	"github.com/neticdk/go-common/pkg/cli/cmd"
 	"github.com/neticdk/go-common/pkg/cli/ui"
@@ -40,6 +41,9 @@ ic get clusters
 # get clusters in the resilience zone 'platform'
 ic get clusters --filter resilienceZone=platform
 
+# get clusters with the newest kubernetes version first
+ic get clusters --sort-by kubernetes_version --reverse
+
 use: 'ic help filters' for more information on using filters`
 
 // New creates a new "get clusters" command
@@ -69,14 +73,29 @@ type getClustersOptions struct {
 	// =~ (or ~) - matches (case insensitive regular expression)
 	// !~        - does not match (case insensitive expression)
 	Filters []string
+	// Columns are the columns shown in table, csv, tsv and markdown output
+	Columns []string
+	// SortBy is the field of the json output used to sort the list
+	SortBy string
+	// Reverse sorts the list in descending order
+	Reverse bool
+
+	sortBy *render.Field
 }
 
 func (o *getClustersOptions) bindFlags(f *pflag.FlagSet) {
 	f.StringArrayVar(&o.Filters, "filter", []string{}, "Filter output based on conditions")
+	f.StringSliceVar(&o.Columns, "columns", nil, columnsFlagUsage)
+	f.StringVar(&o.SortBy, "sort-by", "", fmt.Sprintf(sortByFlagUsage, "kubernetes_version"))
+	f.BoolVar(&o.Reverse, "reverse", false, reverseFlagUsage)
 }
 
 func (o *getClustersOptions) Complete(_ context.Context, _ *ic.Context) error { return nil }
-func (o *getClustersOptions) Validate(_ context.Context, _ *ic.Context) error { return nil }
+func (o *getClustersOptions) Validate(_ context.Context, ac *ic.Context) error {
+	var err error
+	o.sortBy, err = parseSortBy(ac, o.SortBy)
+	return err
+}
 
 func (o *getClustersOptions) Run(ctx context.Context, ac *ic.Context) error {
 	logger := ac.EC.Logger.WithGroup("Clusters")
@@ -89,7 +108,7 @@ func (o *getClustersOptions) Run(ctx context.Context, ac *ic.Context) error {
 
 	searchFields := make(map[string]*qsparser.SearchField)
 	for _, f := range o.Filters {
-		out, err := parseFilter(f)
+		out, err := parseFilter(f, getClustersFilterNames)
 		if err != nil {
 			return err
 		}
@@ -104,6 +123,8 @@ func (o *getClustersOptions) Run(ctx context.Context, ac *ic.Context) error {
 			APIClient: ac.APIClient,
 			PerPage:   PerPage,
 			Filters:   searchFields,
+			SortBy:    o.sortBy,
+			Reverse:   o.Reverse,
 		}
 		result, err = cluster.ListClusters(ctx, in)
 		return err
@@ -125,6 +146,7 @@ func (o *getClustersOptions) Run(ctx context.Context, ac *ic.Context) error {
 	}
 
 	r := cluster.NewClustersRenderer(result.ClusterListResponse, result.JSONResponse, ac.EC.Stdout, ac.EC.PFlags.NoHeaders)
+	r.Columns = o.Columns
 	if err := r.Render(ac.EC.PFlags.OutputFormat); err != nil {
 		return ac.EC.ErrorHandler.NewGeneralError(
 			"Failed to render output",
@@ -142,14 +164,14 @@ type parseFilterOut struct {
 	SearchField *qsparser.SearchField
 }
 
-func parseFilter(filterArg string) (*parseFilterOut, error) {
+func parseFilter(filterArg string, fieldNames []string) (*parseFilterOut, error) {
 	r := regexp.MustCompile(`^([a-zA-Z0-9]+)(==|!=|>=|<=|=~|!~|=|!|<|>|~| (?i)in | (?i)notin )(.*)$`)
 	m := r.FindStringSubmatch(filterArg)
 	if m == nil {
 		return nil, fmt.Errorf("syntax error in filter: %v", filterArg)
 	}
 	fieldName := m[1]
-	if !slices.Contains(getClustersFilterNames, fieldName) {
+	if !slices.Contains(fieldNames, fieldName) {
 		return nil, fmt.Errorf("unknown field name: %s in %s", fieldName, filterArg)
 	}
 	searchOp := m[2]
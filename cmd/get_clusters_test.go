@@ -39,7 +39,8 @@ func Test_GetClustersCommand(t *testing.T) {
 			RefreshToken: "YOUR_REFRESH_TOKEN",
 		}, nil)
 	ac.Authenticator = mockAuthenticator
-	clusters := []string{"my-cluster"}
+	// server order, lexical version order and version order differ
+	clusters := []string{"my-cluster", "other-cluster", "staging-cluster"}
 	included := []map[string]interface{}{
 		{
 			"@id":   "my-provider-id",
@@ -63,6 +64,30 @@ func Test_GetClustersCommand(t *testing.T) {
 				"version": "v1.2.3",
 			},
 		},
+		{
+			"@id":             "other-cluster-id",
+			"@type":           "Cluster",
+			"name":            "other-cluster",
+			"clusterType":     "shared",
+			"environmentName": "production",
+			"provider":        "my-provider-id",
+			"resilienceZone":  "my-rz-id",
+			"kubernetesVersion": map[string]interface{}{
+				"version": "v1.10.0",
+			},
+		},
+		{
+			"@id":             "staging-cluster-id",
+			"@type":           "Cluster",
+			"name":            "staging-cluster",
+			"clusterType":     "shared",
+			"environmentName": "staging",
+			"provider":        "my-provider-id",
+			"resilienceZone":  "my-rz-id",
+			"kubernetesVersion": map[string]interface{}{
+				"version": "v1.9.0",
+			},
+		},
 	}
 	mockClientWithResponsesInterface := apiclient.NewMockClientWithResponsesInterface(t)
 	mockClientWithResponsesInterface.EXPECT().
@@ -95,9 +120,97 @@ func Test_GetClustersCommand(t *testing.T) {
 	})
 
 	t.Run("get clusters -o json", func(t *testing.T) {
+		got.Reset()
 		cmd.SetArgs([]string{"get", "clusters", "-o", "json"})
 		err := cmd.ExecuteContext(context.Background())
 		assert.NoError(t, err)
 		assert.Contains(t, got.String(), "\"name\": \"my-cluster\"")
 	})
+	t.Run("get clusters -o yaml", func(t *testing.T) {
+		got.Reset()
+		cmd.SetArgs([]string{"get", "clusters", "-o", "yaml"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+		assert.Contains(t, got.String(), "name: my-cluster")
+	})
+
+	t.Run("get clusters -o name", func(t *testing.T) {
+		got.Reset()
+		cmd.SetArgs([]string{"get", "clusters", "-o", "name"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+		assert.Contains(t, got.String(), "my-cluster.my-provider\n")
+		assert.NotContains(t, got.String(), "version")
+	})
+	t.Run("get clusters -o jsonpath", func(t *testing.T) {
+		got.Reset()
+		cmd.SetArgs([]string{"get", "clusters", "-o", "jsonpath={range .clusters[*]}{.name}:{.kubernetes_version}{\"\\n\"}{end}"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+		assert.Contains(t, got.String(), "my-cluster:v1.2.3\n")
+	})
+
+	t.Run("get clusters -o custom-columns", func(t *testing.T) {
+		got.Reset()
+		cmd.SetArgs([]string{"get", "clusters", "-o", "custom-columns=ID:.id,VER:.kubernetes_version"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+		assert.Regexp(t, `ID\s+VER\s*\nmy-cluster.my-provider\s+v1.2.3`, got.String())
+	})
+	t.Run("get clusters -o csv --columns", func(t *testing.T) {
+		got.Reset()
+		cmd := newRootCmd(ac)
+		cmd.SetArgs([]string{"get", "clusters", "-o", "csv", "--columns", "id,environment,version"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+		assert.Contains(t, got.String(), "id,environment,version\nmy-cluster.my-provider,testing,v1.2.3\n")
+	})
+
+	t.Run("get clusters -o markdown", func(t *testing.T) {
+		got.Reset()
+		cmd := newRootCmd(ac)
+		cmd.SetArgs([]string{"get", "clusters", "-o", "markdown", "--columns", "id,type"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+		assert.Contains(t, got.String(), "| id | type |\n| --- | --- |\n| my-cluster.my-provider | dedicated |\n")
+	})
+
+	t.Run("get clusters --columns unknown", func(t *testing.T) {
+		cmd := newRootCmd(ac)
+		cmd.SetArgs([]string{"get", "clusters", "-o", "plain", "--columns", "nodes"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.Error(t, err)
+	})
+
+	t.Run("get clusters --sort-by", func(t *testing.T) {
+		got.Reset()
+		cmd := newRootCmd(ac)
+		cmd.SetArgs([]string{"get", "clusters", "-o", "name", "--sort-by", "kubernetes_version"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+		assert.Contains(t, got.String(), "\nmy-cluster.my-provider\nstaging-cluster.my-provider\nother-cluster.my-provider\n")
+	})
+
+	t.Run("get clusters --sort-by --reverse", func(t *testing.T) {
+		got.Reset()
+		cmd := newRootCmd(ac)
+		cmd.SetArgs([]string{"get", "clusters", "-o", "name", "--sort-by", "{.kubernetes_version}", "--reverse"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+		assert.Contains(t, got.String(), "\nother-cluster.my-provider\nstaging-cluster.my-provider\nmy-cluster.my-provider\n")
+	})
+
+	t.Run("get clusters --sort-by unknown", func(t *testing.T) {
+		cmd := newRootCmd(ac)
+		cmd.SetArgs([]string{"get", "clusters", "--sort-by", "nodes"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.Error(t, err)
+	})
+
+	t.Run("get clusters --sort-by invalid", func(t *testing.T) {
+		cmd := newRootCmd(ac)
+		cmd.SetArgs([]string{"get", "clusters", "--sort-by", "clusters[x"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.Error(t, err)
+	})
 }
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/render"
 	"github.com/neticdk-k8s/ic/internal/usecases/component"
 	"github.com/neticdk/go-common/pkg/cli/cmd"
 	"github.com/neticdk/go-common/pkg/cli/ui"
@@ -22,12 +24,29 @@ func getComponentsCmd(ac *ic.Context) *cobra.Command {
 	return c
 }
 
-type getComponentsOptions struct{}
+type getComponentsOptions struct {
+	// Columns are the columns shown in table, csv, tsv and markdown output
+	Columns []string
+	// SortBy is the field of the json output used to sort the list
+	SortBy string
+	// Reverse sorts the list in descending order
+	Reverse bool
 
-func (o *getComponentsOptions) bindFlags(_ *pflag.FlagSet) {}
+	sortBy *render.Field
+}
+
+func (o *getComponentsOptions) bindFlags(f *pflag.FlagSet) {
+	f.StringSliceVar(&o.Columns, "columns", nil, columnsFlagUsage)
+	f.StringVar(&o.SortBy, "sort-by", "", fmt.Sprintf(sortByFlagUsage, "name"))
+	f.BoolVar(&o.Reverse, "reverse", false, reverseFlagUsage)
+}
 
 func (o *getComponentsOptions) Complete(_ context.Context, _ *ic.Context) error { return nil }
-func (o *getComponentsOptions) Validate(_ context.Context, _ *ic.Context) error { return nil }
+func (o *getComponentsOptions) Validate(_ context.Context, ac *ic.Context) error {
+	var err error
+	o.sortBy, err = parseSortBy(ac, o.SortBy)
+	return err
+}
 
 func (o *getComponentsOptions) Run(ctx context.Context, ac *ic.Context) error {
 	logger := ac.EC.Logger.WithGroup("Components")
@@ -43,6 +62,8 @@ func (o *getComponentsOptions) Run(ctx context.Context, ac *ic.Context) error {
 		in := component.ListComponentsInput{
 			Logger:    logger,
 			APIClient: ac.APIClient,
+			SortBy:    o.sortBy,
+			Reverse:   o.Reverse,
 		}
 		result, err = component.ListComponents(ctx, in)
 		return err
@@ -64,6 +85,7 @@ func (o *getComponentsOptions) Run(ctx context.Context, ac *ic.Context) error {
 	}
 
 	r := component.NewComponentsRenderer(result.ComponentListResponse, result.JSONResponse, ac.EC.Stdout, ac.EC.PFlags.NoHeaders)
+	r.Columns = o.Columns
 	if err := r.Render(ac.EC.PFlags.OutputFormat); err != nil {
 		return ac.EC.ErrorHandler.NewGeneralError(
 			"Failed to render output",
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"text/template"
+
+	"github.com/neticdk-k8s/ic/internal/errors"
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/kubeconfig"
+	"github.com/neticdk-k8s/ic/internal/usecases/cluster"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/neticdk/go-common/pkg/cli/ui"
+	"github.com/neticdk/go-common/pkg/qsparser"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const getKubeConfigsLongDesc = `Get kubeconfigs for all clusters matching the given filters.
+
+By default the kubeconfigs are merged into a single kubeconfig which is written
+to stdout. Use --merge to merge them into $KUBECONFIG or ~/.kube/config or
+--output-dir to write one file per cluster.
+
+Supported field names for filters are the same as for 'ic get clusters'.
+`
+
+const getKubeConfigsExample = `
+# get a merged kubeconfig for all clusters in the resilience zone 'platform'
+ic get kubeconfigs --filter resilienceZone=platform > platform.yaml
+
+# merge kubeconfigs for all clusters into $KUBECONFIG or ~/.kube/config
+ic get kubeconfigs --merge
+
+# write one kubeconfig file per cluster to the directory 'kubeconfigs'
+ic get kubeconfigs --filter providerName=my-provider --output-dir kubeconfigs
+
+use: 'ic help filters' for more information on using filters`
+
+// New creates a new "get kubeconfigs" command
+func getKubeConfigsCmd(ac *ic.Context) *cobra.Command {
+	o := &getKubeConfigsOptions{}
+	c := cmd.NewSubCommand("kubeconfigs", o, ac).
+		WithShortDesc("Get kubeconfigs for a list of clusters").
+		WithLongDesc(getKubeConfigsLongDesc).
+		WithExample(getKubeConfigsExample).
+		WithGroupID(groupCluster).
+		WithNoArgs().
+		Build()
+
+	o.bindFlags(c.Flags())
+	c.MarkFlagsMutuallyExclusive("merge", "output-dir")
+	return c
+}
+
+type getKubeConfigsOptions struct {
+	Filters     []string
+	OutputDir   string
+	Merge       bool
+	ContextName string
+	Concurrency int
+	contextTmpl *template.Template
+}
+
+func (o *getKubeConfigsOptions) bindFlags(f *pflag.FlagSet) {
+	f.StringArrayVar(&o.Filters, "filter", []string{}, "Filter clusters based on conditions")
+	f.StringVar(&o.OutputDir, "output-dir", "", "Write one kubeconfig file per cluster to this directory")
+	f.BoolVar(&o.Merge, "merge", false, "Merge into $KUBECONFIG or ~/.kube/config instead of printing")
+	f.StringVar(&o.ContextName, "context-name", kubeconfig.DefaultContextNameTemplate, "Template used to name contexts. Fields: .ClusterID, .Name, .Provider, .Context")
+	f.IntVar(&o.Concurrency, "concurrency", 5, "Maximum number of kubeconfigs fetched at the same time")
+}
+
+func (o *getKubeConfigsOptions) Complete(_ context.Context, _ *ic.Context) error { return nil }
+
+func (o *getKubeConfigsOptions) Validate(_ context.Context, _ *ic.Context) error {
+	if o.Concurrency < 1 {
+		return &cmd.InvalidArgumentError{
+			Flag:    "concurrency",
+			Val:     fmt.Sprint(o.Concurrency),
+			Context: "must be at least 1",
+		}
+	}
+	tmpl, err := parseContextNameTemplate(o.ContextName)
+	if err != nil {
+		return err
+	}
+	o.contextTmpl = tmpl
+	return nil
+}
+
+func (o *getKubeConfigsOptions) Run(ctx context.Context, ac *ic.Context) error {
+	logger := ac.EC.Logger.WithGroup("ClusterKubeConfig")
+	ac.Authenticator.SetLogger(logger)
+
+	_, err := doLogin(ctx, ac)
+	if err != nil {
+		return err
+	}
+
+	searchFields := make(map[string]*qsparser.SearchField)
+	for _, f := range o.Filters {
+		out, err := parseFilter(f, getClustersFilterNames)
+		if err != nil {
+			return err
+		}
+		searchFields[out.FieldName] = out.SearchField
+	}
+
+	var clusters *cluster.ListClusterResults
+	if err := ui.Spin(ac.EC.Spinner, "Getting clusters", func(_ ui.Spinner) error {
+		in := cluster.ListClustersInput{
+			Logger:    logger,
+			APIClient: ac.APIClient,
+			PerPage:   PerPage,
+			Filters:   searchFields,
+		}
+		clusters, err = cluster.ListClusters(ctx, in)
+		return err
+	}); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Listing clusters",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+	if clusters.Problem != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			*clusters.Problem.Title,
+			*clusters.Problem.Detail,
+			nil,
+			0,
+		)
+	}
+	clusterIDs := make([]string, 0, len(clusters.ClusterListResponse.Clusters))
+	for _, c := range clusters.ClusterListResponse.Clusters {
+		clusterIDs = append(clusterIDs, c.ID)
+	}
+	if len(clusterIDs) == 0 {
+		ui.Info.Println("No clusters found")
+		return nil
+	}
+
+	var result *cluster.GetClusterKubeConfigsResult
+	spinnerText := fmt.Sprintf("Getting kubeconfigs for %d clusters", len(clusterIDs))
+	if err := ui.Spin(ac.EC.Spinner, spinnerText, func(_ ui.Spinner) error {
+		in := cluster.GetClusterKubeConfigsInput{
+			Logger:      logger,
+			APIClient:   ac.APIClient,
+			ClusterIDs:  clusterIDs,
+			Concurrency: o.Concurrency,
+		}
+		result, err = cluster.GetClusterKubeConfigs(ctx, in)
+		return err
+	}); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Getting kubeconfigs",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	fetched := make(map[string]*kubeconfig.Config)
+	failed := 0
+	for _, kc := range result.KubeConfigs {
+		c, err := o.toKubeConfig(kc)
+		if err != nil {
+			ui.Warning.Printf("Skipping %s: %v\n", kc.ClusterID, err)
+			failed++
+			continue
+		}
+		fetched[kc.ClusterID] = c
+	}
+
+	if o.OutputDir != "" {
+		err = o.writeKubeConfigs(ac, clusterIDs, fetched)
+	} else {
+		err = o.mergeKubeConfigs(ac, clusterIDs, fetched)
+	}
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			fmt.Sprintf("Failed to get %d of %d kubeconfigs", failed, len(clusterIDs)),
+			"See warnings for more information",
+			nil,
+			0,
+		)
+	}
+	return nil
+}
+
+func (o *getKubeConfigsOptions) toKubeConfig(kc cluster.ClusterKubeConfig) (*kubeconfig.Config, error) {
+	if kc.Err != nil {
+		return nil, kc.Err
+	}
+	if kc.Problem != nil {
+		return nil, &errors.ProblemError{
+			Title:   "getting cluster kubeconfig",
+			Problem: kc.Problem,
+		}
+	}
+	c, err := kubeconfig.Parse(kc.Response)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.RenameContexts(o.contextTmpl, kubeconfig.NewContextNameData(kc.ClusterID)); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (o *getKubeConfigsOptions) writeKubeConfigs(ac *ic.Context, clusterIDs []string, fetched map[string]*kubeconfig.Config) error {
+	for _, clusterID := range clusterIDs {
+		c, ok := fetched[clusterID]
+		if !ok {
+			continue
+		}
+		path := filepath.Join(o.OutputDir, clusterID+".yaml")
+		if err := c.Save(path, false); err != nil {
+			return ac.EC.ErrorHandler.NewGeneralError(
+				"Writing kubeconfig",
+				"See details for more information",
+				err,
+				0,
+			)
+		}
+	}
+	ui.Success.Printf("Wrote %d kubeconfigs to %s\n", len(fetched), o.OutputDir)
+	return nil
+}
+
+func (o *getKubeConfigsOptions) mergeKubeConfigs(ac *ic.Context, clusterIDs []string, fetched map[string]*kubeconfig.Config) error {
+	merged := kubeconfig.New()
+	for _, clusterID := range clusterIDs {
+		c, ok := fetched[clusterID]
+		if !ok {
+			continue
+		}
+		if _, err := merged.Merge(c, false); err != nil {
+			return ac.EC.ErrorHandler.NewGeneralError(
+				"Merging kubeconfigs",
+				"Use --context-name to give each cluster a unique name",
+				err,
+				0,
+			)
+		}
+	}
+
+	if o.Merge {
+		return mergeIntoDefaultKubeConfig(ac, merged, false)
+	}
+
+	data, err := merged.Bytes()
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Failed to render output",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+	_, err = ac.EC.Stdout.Write(data)
+	return err
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/neticdk-k8s/ic/internal/apiclient"
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/kubeconfig"
+	"github.com/neticdk-k8s/ic/internal/oidc"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/neticdk/go-common/pkg/cli/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockedGetKubeConfigsEC(t *testing.T) (*ic.Context, *bytes.Buffer) {
+	got := new(bytes.Buffer)
+	ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+	ec.Stderr = got
+	ec.Stdout = got
+	ui.SetDefaultOutput(got)
+	ac := ic.NewContext()
+	ac.EC = ec
+	mockAuthenticator := authentication.NewMockAuthenticator(t)
+	mockAuthenticator.EXPECT().
+		SetLogger(mock.Anything).
+		Run(func(_ *slog.Logger) {}).
+		Return()
+	mockAuthenticator.EXPECT().
+		Login(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, in authentication.LoginInput) {}).
+		Return(&oidc.TokenSet{
+			AccessToken:  "YOUR_ACCESS_TOKEN",
+			IDToken:      "YOUR_ID_TOKEN",
+			RefreshToken: "YOUR_REFRESH_TOKEN",
+		}, nil)
+	ac.Authenticator = mockAuthenticator
+	clusters := []string{"cluster-a-id", "cluster-b-id"}
+	included := []map[string]interface{}{
+		{
+			"@id":   "my-provider-id",
+			"@type": "Provider",
+			"name":  "my-provider",
+		},
+		{
+			"@id":      "cluster-a-id",
+			"@type":    "Cluster",
+			"name":     "cluster-a",
+			"provider": "my-provider-id",
+		},
+		{
+			"@id":      "cluster-b-id",
+			"@type":    "Cluster",
+			"name":     "cluster-b",
+			"provider": "my-provider-id",
+		},
+	}
+	mockClientWithResponsesInterface := apiclient.NewMockClientWithResponsesInterface(t)
+	mockClientWithResponsesInterface.EXPECT().
+		ListClustersWithResponse(mock.Anything, mock.Anything).
+		Return(
+			&apiclient.ListClustersResponse{
+				Body: make([]byte, 0),
+				HTTPResponse: &http.Response{
+					Status:     "200 OK",
+					StatusCode: 200,
+				},
+				ApplicationldJSONDefault: &apiclient.Clusters{
+					Clusters:   &clusters,
+					Included:   &included,
+					Pagination: &apiclient.Pagination{},
+				},
+			}, nil)
+	for _, name := range []string{"cluster-a", "cluster-b"} {
+		mockClientWithResponsesInterface.EXPECT().
+			GetClusterKubeConfigWithResponse(mock.Anything, name+".my-provider").
+			Return(
+				&apiclient.GetClusterKubeConfigResponse{
+					Body: []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: kubernetes
+  cluster:
+    server: https://%s.example.com:6443
+contexts:
+- name: admin@kubernetes
+  context:
+    cluster: kubernetes
+    user: admin
+users:
+- name: admin
+  user:
+    token: my-token
+`, name)),
+					HTTPResponse: &http.Response{
+						Status:     "200 OK",
+						StatusCode: 200,
+					},
+				}, nil)
+	}
+	ac.APIClient = mockClientWithResponsesInterface
+	return ac, got
+}
+
+func Test_GetKubeConfigsCommand(t *testing.T) {
+	ac, got := newMockedGetKubeConfigsEC(t)
+	cmd := newRootCmd(ac)
+	cmd.SetArgs([]string{"get", "kubeconfigs", "--filter", "providerName=my-provider"})
+	err := cmd.ExecuteContext(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, got.String(), "Getting kubeconfigs for 2 clusters")
+	assert.Contains(t, got.String(), "name: cluster-a.my-provider")
+	assert.Contains(t, got.String(), "name: cluster-b.my-provider")
+	assert.Contains(t, got.String(), "server: https://cluster-b.example.com:6443")
+}
+
+func Test_GetKubeConfigsCommandOutputDir(t *testing.T) {
+	dir := t.TempDir()
+	ac, got := newMockedGetKubeConfigsEC(t)
+	cmd := newRootCmd(ac)
+	cmd.SetArgs([]string{"get", "kubeconfigs", "--output-dir", dir, "--concurrency", "1"})
+	err := cmd.ExecuteContext(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, got.String(), "Wrote 2 kubeconfigs")
+
+	for _, name := range []string{"cluster-a", "cluster-b"} {
+		c, err := kubeconfig.Load(filepath.Join(dir, name+".my-provider.yaml"))
+		require.NoError(t, err)
+		require.Len(t, c.Contexts, 1)
+		assert.Equal(t, name+".my-provider", c.Contexts[0].Name)
+		assert.Equal(t, fmt.Sprintf("https://%s.example.com:6443", name), c.Clusters[0].Cluster["server"])
+	}
+}
+
+func Test_GetKubeConfigsCommandInvalidParameters(t *testing.T) {
+	got := new(bytes.Buffer)
+	ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+	ec.Stderr = got
+	ec.Stdout = got
+	ui.SetDefaultOutput(got)
+	ac := ic.NewContext()
+	ac.EC = ec
+	cmd := newRootCmd(ac)
+	cmd.SetArgs([]string{"get", "kubeconfigs", "--merge", "--output-dir", "dir"})
+	err := cmd.ExecuteContext(context.Background())
+	assert.ErrorContains(t, err, "none of the others can be")
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/usecases/resource"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/neticdk/go-common/pkg/cli/ui"
+	"github.com/spf13/cobra"
+)
+
+const getResourceLongDesc = `Get a single resource in a cluster.
+
+The resource is given as GROUP/VERSION/NAMESPACE/TYPE/NAME where TYPE is the
+plural name of the Kubernetes resource type (e.g. apps/v1/default/deployments/my-app).
+The core API group is given as core or left empty (e.g. /v1/default/services/my-app).
+
+Only namespaced resources can be addressed as the inventory server API has no
+endpoint for single cluster-scoped resources. Use get resources to list them.
+`
+
+const getResourceExample = `
+# get the deployment my-app in the namespace default
+ic get resource my-cluster.my-provider apps/v1/default/deployments/my-app
+
+# get the service my-app in the namespace default
+ic get resource my-cluster.my-provider core/v1/default/services/my-app
+
+# get the deployment in yaml format
+ic get resource my-cluster.my-provider apps/v1/default/deployments/my-app -o yaml`
+
+func getResourceCmd(ac *ic.Context) *cobra.Command {
+	o := &getResourceOptions{}
+	c := cmd.NewSubCommand("resource", o, ac).
+		WithShortDesc("Get a resource in a cluster").
+		WithLongDesc(getResourceLongDesc).
+		WithExample(getResourceExample).
+		WithGroupID(groupResource).
+		WithExactArgs(2).
+		Build()
+	c.Use = "resource CLUSTER-ID GROUP/VERSION/NAMESPACE/TYPE/NAME"
+
+	return c
+}
+
+type getResourceOptions struct {
+	clusterID    string
+	group        string
+	version      string
+	namespace    string
+	resourceType string
+	name         string
+}
+
+func (o *getResourceOptions) Complete(_ context.Context, ac *ic.Context) error {
+	o.clusterID = ac.EC.CommandArgs[0]
+	o.group, o.version, o.namespace, o.resourceType, o.name = "", "", "", "", ""
+	parts := strings.Split(ac.EC.CommandArgs[1], "/")
+	if len(parts) == 5 {
+		o.group, o.version, o.namespace, o.resourceType, o.name = parts[0], parts[1], parts[2], parts[3], parts[4]
+		if o.group == "" {
+			o.group = coreGroup
+		}
+	}
+	return nil
+}
+
+func (o *getResourceOptions) Validate(_ context.Context, ac *ic.Context) error {
+	for _, v := range []string{o.group, o.version, o.resourceType, o.name} {
+		if v == "" {
+			return fmt.Errorf("invalid resource: %q must be of the form GROUP/VERSION/NAMESPACE/TYPE/NAME", ac.EC.CommandArgs[1])
+		}
+	}
+	if o.namespace == "" {
+		return fmt.Errorf("invalid resource: %q has no namespace; cluster-scoped resources are not supported", ac.EC.CommandArgs[1])
+	}
+	return nil
+}
+
+func (o *getResourceOptions) Run(ctx context.Context, ac *ic.Context) error {
+	logger := ac.EC.Logger.WithGroup("Resources")
+	ac.Authenticator.SetLogger(logger)
+
+	_, err := doLogin(ctx, ac)
+	if err != nil {
+		return err
+	}
+
+	var result *resource.GetResourceResult
+	spinnerText := fmt.Sprintf("Getting %s %s/%s", o.resourceType, o.namespace, o.name)
+	if err := ui.Spin(ac.EC.Spinner, spinnerText, func(_ ui.Spinner) error {
+		in := resource.GetResourceInput{
+			Logger:       logger,
+			APIClient:    ac.APIClient,
+			ClusterID:    o.clusterID,
+			Group:        o.group,
+			Version:      o.version,
+			Namespace:    o.namespace,
+			ResourceType: o.resourceType,
+			Name:         o.name,
+		}
+		result, err = resource.GetResource(ctx, in)
+		return err
+	}); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Getting resource",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+	if result.Problem != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			*result.Problem.Title,
+			*result.Problem.Detail,
+			nil,
+			0,
+		)
+	}
+
+	r := resource.NewResourceRenderer(result.ResourceResponse, result.JSONResponse, ac.EC.Stdout)
+	if err := r.Render(ac.EC.PFlags.OutputFormat); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Failed to render output",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	return nil
+}
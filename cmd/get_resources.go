@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/usecases/resource"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/neticdk/go-common/pkg/cli/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const getResourcesLongDesc = `Get list of resources of a given type in a cluster.
+
+The resource type is given as GROUP/VERSION/TYPE where TYPE is the plural
+name of the Kubernetes resource type (e.g. apps/v1/deployments). The core API
+group is given as core or left empty (e.g. core/v1/services or /v1/services).
+`
+
+const getResourcesExample = `
+# get deployments in my-cluster.my-provider
+ic get resources my-cluster.my-provider apps/v1/deployments
+
+# get services in my-cluster.my-provider
+ic get resources my-cluster.my-provider core/v1/services
+
+# get deployments in yaml format
+ic get resources my-cluster.my-provider apps/v1/deployments -o yaml`
+
+func getResourcesCmd(ac *ic.Context) *cobra.Command {
+	o := &getResourcesOptions{}
+	c := cmd.NewSubCommand("resources", o, ac).
+		WithShortDesc("Get list of resources of a given type in a cluster").
+		WithLongDesc(getResourcesLongDesc).
+		WithExample(getResourcesExample).
+		WithGroupID(groupResource).
+		WithExactArgs(2).
+		Build()
+	c.Use = "resources CLUSTER-ID GROUP/VERSION/TYPE"
+
+	o.bindFlags(c.Flags())
+	return c
+}
+
+type getResourcesOptions struct {
+	clusterID    string
+	group        string
+	version      string
+	resourceType string
+	// Columns are the columns shown in table, csv, tsv and markdown output
+	Columns []string
+}
+
+func (o *getResourcesOptions) bindFlags(f *pflag.FlagSet) {
+	f.StringSliceVar(&o.Columns, "columns", nil, columnsFlagUsage)
+}
+
+// coreGroup is the name of the core API group in the inventory server API
+const coreGroup = "core"
+
+func (o *getResourcesOptions) Complete(_ context.Context, ac *ic.Context) error {
+	o.clusterID = ac.EC.CommandArgs[0]
+	o.group, o.version, o.resourceType = "", "", ""
+	parts := strings.Split(ac.EC.CommandArgs[1], "/")
+	if len(parts) == 3 {
+		o.group, o.version, o.resourceType = parts[0], parts[1], parts[2]
+		if o.group == "" {
+			o.group = coreGroup
+		}
+	}
+	return nil
+}
+
+func (o *getResourcesOptions) Validate(_ context.Context, ac *ic.Context) error {
+	if o.group == "" || o.version == "" || o.resourceType == "" {
+		return fmt.Errorf("invalid resource type: %q must be of the form GROUP/VERSION/TYPE", ac.EC.CommandArgs[1])
+	}
+	return nil
+}
+
+func (o *getResourcesOptions) Run(ctx context.Context, ac *ic.Context) error {
+	logger := ac.EC.Logger.WithGroup("Resources")
+	ac.Authenticator.SetLogger(logger)
+
+	_, err := doLogin(ctx, ac)
+	if err != nil {
+		return err
+	}
+
+	var result *resource.ListResourcesResults
+
+	spinnerText := fmt.Sprintf("Getting %s/%s/%s for cluster %q", o.group, o.version, o.resourceType, o.clusterID)
+	if err := ui.Spin(ac.EC.Spinner, spinnerText, func(_ ui.Spinner) error {
+		in := resource.ListResourcesInput{
+			Logger:       logger,
+			APIClient:    ac.APIClient,
+			PerPage:      PerPage,
+			ClusterID:    o.clusterID,
+			Group:        o.group,
+			Version:      o.version,
+			ResourceType: o.resourceType,
+		}
+		result, err = resource.ListResources(ctx, in)
+		return err
+	}); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Listing resources",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+	if result.Problem != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			*result.Problem.Title,
+			*result.Problem.Detail,
+			nil,
+			0,
+		)
+	}
+
+	r := resource.NewResourcesRenderer(result.ResourceListResponse, result.JSONResponse, ac.EC.Stdout, ac.EC.PFlags.NoHeaders)
+	r.Columns = o.Columns
+	if err := r.Render(ac.EC.PFlags.OutputFormat); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Failed to render output",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/neticdk-k8s/ic/internal/apiclient"
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/oidc"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/neticdk/go-common/pkg/cli/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_GetResourcesCommand(t *testing.T) {
+	got := new(bytes.Buffer)
+	ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+	ec.Stderr = got
+	ec.Stdout = got
+	ui.SetDefaultOutput(got)
+	ac := ic.NewContext()
+	ac.EC = ec
+	mockAuthenticator := authentication.NewMockAuthenticator(t)
+	mockAuthenticator.EXPECT().
+		SetLogger(mock.Anything).
+		Run(func(_ *slog.Logger) {}).
+		Return()
+	mockAuthenticator.EXPECT().
+		Login(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, in authentication.LoginInput) {}).
+		Return(&oidc.TokenSet{
+			AccessToken:  "YOUR_ACCESS_TOKEN",
+			IDToken:      "YOUR_ID_TOKEN",
+			RefreshToken: "YOUR_REFRESH_TOKEN",
+		}, nil).Maybe()
+	ac.Authenticator = mockAuthenticator
+	resources := []string{"my-deployment-id"}
+	included := []map[string]interface{}{
+		{
+			"@id":       "my-deployment-id",
+			"kind":      "Deployment",
+			"name":      "my-deployment",
+			"namespace": "my-namespace",
+			"labels": map[string]interface{}{
+				"app": "my-app",
+			},
+		},
+	}
+	name := "my-deployment"
+	namespace := "my-namespace"
+	kind := "Deployment"
+	mockClientWithResponsesInterface := apiclient.NewMockClientWithResponsesInterface(t)
+	mockClientWithResponsesInterface.EXPECT().
+		ListResourcesByTypeWithResponse(mock.Anything, "my-cluster.my-provider", "apps", "v1", "deployments", mock.Anything).
+		Return(
+			&apiclient.ListResourcesByTypeResponse{
+				Body: make([]byte, 0),
+				HTTPResponse: &http.Response{
+					Status:     "200 OK",
+					StatusCode: 200,
+				},
+				ApplicationldJSONDefault: &apiclient.Resources{
+					Resource:   &resources,
+					Included:   &included,
+					Pagination: &apiclient.Pagination{},
+				},
+			}, nil)
+	mockClientWithResponsesInterface.EXPECT().
+		GetResourceWithResponse(mock.Anything, "my-cluster.my-provider", "apps", "v1", "my-namespace", "deployments", "my-deployment").
+		Return(
+			&apiclient.GetResourceResponse{
+				Body: make([]byte, 0),
+				HTTPResponse: &http.Response{
+					Status:     "200 OK",
+					StatusCode: 200,
+				},
+				ApplicationldJSONDefault: &apiclient.GenericResource{
+					Name:      &name,
+					Namespace: &namespace,
+					Kind:      &kind,
+				},
+			}, nil)
+	mockClientWithResponsesInterface.EXPECT().
+		GetResourceWithResponse(mock.Anything, "my-cluster.my-provider", "core", "v1", "my-namespace", "services", "my-service").
+		Return(
+			&apiclient.GetResourceResponse{
+				Body: make([]byte, 0),
+				HTTPResponse: &http.Response{
+					Status:     "200 OK",
+					StatusCode: 200,
+				},
+				ApplicationldJSONDefault: &apiclient.GenericResource{
+					Name:      &name,
+					Namespace: &namespace,
+					Kind:      &kind,
+				},
+			}, nil)
+	apiClient := mockClientWithResponsesInterface
+	ac.APIClient = apiClient
+
+	cmd := newRootCmd(ac)
+
+	t.Run("get resources", func(t *testing.T) {
+		cmd.SetArgs([]string{"get", "resources", "my-cluster.my-provider", "apps/v1/deployments"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+		assert.Contains(t, got.String(), "my-deployment")
+		assert.Contains(t, got.String(), "app=my-app")
+	})
+
+	t.Run("get resources -o yaml", func(t *testing.T) {
+		cmd.SetArgs([]string{"get", "resources", "my-cluster.my-provider", "apps/v1/deployments", "-o", "yaml"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+		assert.Contains(t, got.String(), "name: my-deployment")
+	})
+
+	t.Run("get resource", func(t *testing.T) {
+		cmd.SetArgs([]string{"get", "resource", "my-cluster.my-provider", "apps/v1/my-namespace/deployments/my-deployment", "-o", "json"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+		assert.Contains(t, got.String(), "\"kind\": \"Deployment\"")
+	})
+
+	t.Run("get resource in the core group", func(t *testing.T) {
+		cmd.SetArgs([]string{"get", "resource", "my-cluster.my-provider", "/v1/my-namespace/services/my-service", "-o", "json"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("get cluster-scoped resource", func(t *testing.T) {
+		cmd.SetArgs([]string{"get", "resource", "my-cluster.my-provider", "core/v1//nodes/my-node"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.ErrorContains(t, err, "cluster-scoped resources are not supported")
+	})
+
+	t.Run("get resources with invalid type", func(t *testing.T) {
+		cmd.SetArgs([]string{"get", "resources", "my-cluster.my-provider", "deployments"})
+		err := cmd.ExecuteContext(context.Background())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "GROUP/VERSION/TYPE")
+	})
+}
@@ -2,12 +2,19 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/jwt"
 	"github.com/neticdk-k8s/ic/internal/oidc"
 	"github.com/neticdk-k8s/ic/internal/usecases/authentication"
 	"github.com/neticdk-k8s/ic/internal/usecases/authentication/authcode"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication/clientcredentials"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication/devicecode"
 	"github.com/neticdk/go-common/pkg/cli/cmd"
 	"github.com/neticdk/go-common/pkg/cli/ui"
 	"github.com/spf13/cobra"
@@ -50,16 +57,34 @@ func doLogin(ctx context.Context, ac *ic.Context) (*oidc.TokenSet, error) {
 		err      error
 	)
 
+	staticToken, err := readStaticToken(ac)
+	if err != nil {
+		return nil, ac.EC.ErrorHandler.NewGeneralError(
+			"Reading token",
+			fmt.Sprintf("Use --token, --token-file or %s", envToken),
+			err,
+			0,
+		)
+	}
+	if staticToken != "" {
+		return useStaticToken(ac, staticToken)
+	}
+
 	loginInput := authentication.LoginInput{
-		Provider:    *ac.OIDCProvider,
-		TokenCache:  ac.TokenCache,
-		AuthOptions: authentication.AuthOptions{},
+		Provider:          *ac.OIDCProvider,
+		TokenCache:        ac.TokenCache,
+		Profile:           ac.Profile,
+		VerifyCachedToken: ac.OIDC.VerifyCachedToken,
+		RefreshSkew:       ac.OIDC.RefreshSkew,
+		AuthOptions:       authentication.AuthOptions{},
 	}
 	switch ac.OIDC.GrantType {
 	case "authcode-browser":
 		loginInput.AuthOptions.AuthCodeBrowser = &authcode.BrowserLoginInput{
 			BindAddress:         ac.OIDC.AuthBindAddr,
 			RedirectURLHostname: ac.OIDC.RedirectURLHostname,
+			ACRValues:           ac.OIDC.ACRValues,
+			Prompt:              ac.OIDC.Prompt,
 		}
 		if err := ui.Spin(ac.EC.Spinner, "Logging in", func(_ ui.Spinner) error {
 			tokenSet, err = ac.Authenticator.Login(ctx, loginInput)
@@ -75,7 +100,44 @@ func doLogin(ctx context.Context, ac *ic.Context) (*oidc.TokenSet, error) {
 	case "authcode-keyboard":
 		loginInput.AuthOptions.AuthCodeKeyboard = &authcode.KeyboardLoginInput{
 			RedirectURI: ac.OIDC.RedirectURIAuthCodeKeyboard,
+			ACRValues:   ac.OIDC.ACRValues,
+			Prompt:      ac.OIDC.Prompt,
+		}
+		tokenSet, err = ac.Authenticator.Login(ctx, loginInput)
+		if err != nil {
+			return nil, ac.EC.ErrorHandler.NewGeneralError(
+				"Logging in",
+				"See details for more information",
+				err,
+				0,
+			)
+		}
+	case "client-credentials":
+		clientSecret, err := readClientSecret(ac)
+		if err != nil {
+			return nil, ac.EC.ErrorHandler.NewGeneralError(
+				"Reading client secret",
+				fmt.Sprintf("Use --oidc-client-secret, --oidc-client-secret-file or %s", envClientSecret),
+				err,
+				0,
+			)
+		}
+		loginInput.AuthOptions.ClientCredentials = &clientcredentials.LoginInput{
+			ClientSecret: clientSecret,
+		}
+		if err := ui.Spin(ac.EC.Spinner, "Logging in", func(_ ui.Spinner) error {
+			tokenSet, err = ac.Authenticator.Login(ctx, loginInput)
+			return err
+		}); err != nil {
+			return nil, ac.EC.ErrorHandler.NewGeneralError(
+				"Logging in",
+				"See details for more information",
+				err,
+				0,
+			)
 		}
+	case "device-code":
+		loginInput.AuthOptions.DeviceCode = &devicecode.LoginInput{}
 		tokenSet, err = ac.Authenticator.Login(ctx, loginInput)
 		if err != nil {
 			return nil, ac.EC.ErrorHandler.NewGeneralError(
@@ -85,9 +147,28 @@ func doLogin(ctx context.Context, ac *ic.Context) (*oidc.TokenSet, error) {
 				0,
 			)
 		}
+	default:
+		return nil, &cmd.InvalidArgumentError{
+			Flag:  "oidc-grant-type",
+			Val:   ac.OIDC.GrantType,
+			OneOf: []string{"authcode-browser", "authcode-keyboard", "client-credentials", "device-code"},
+		}
 	}
 
-	if err := ac.SetupDefaultAPIClient(tokenSet.AccessToken); err != nil {
+	// the server may reject a token before it expires, e.g. after a key
+	// rotation or when it was revoked
+	refreshInput := loginInput
+	refreshInput.ForceRefresh = true
+	refresh := func(ctx context.Context) (string, error) {
+		ac.EC.Logger.Debug("Token rejected by the API server. Refreshing")
+		tokenSet, err := ac.Authenticator.Login(ctx, refreshInput)
+		if err != nil {
+			ac.EC.Logger.Warn("Refreshing rejected token", "err", err)
+			return "", err
+		}
+		return tokenSet.AccessToken, nil
+	}
+	if err := ac.SetupDefaultAPIClient(tokenSet.AccessToken, refresh); err != nil {
 		return nil, ac.EC.ErrorHandler.NewGeneralError(
 			"Setup API client in",
 			"See details for more information",
@@ -98,3 +179,98 @@ func doLogin(ctx context.Context, ac *ic.Context) (*oidc.TokenSet, error) {
 
 	return tokenSet, nil
 }
+
+// warnMissingRole warns if the access token carries roles but not the role
+// given by --write-role, in which case the request is likely to be denied.
+// Nothing is checked if the write role is set to empty. Tokens without role
+// claims are left for the API server to judge
+func warnMissingRole(ac *ic.Context, tokenSet *oidc.TokenSet, action string) {
+	role := ac.WriteRole
+	if role == "" {
+		return
+	}
+	claims, err := jwt.DecodeWithoutVerify(tokenSet.AccessToken)
+	if err != nil || !claims.HasRoleClaims() {
+		return
+	}
+	if claims.HasRealmOrClientRole(role) {
+		return
+	}
+	ui.Warning.Printf("Your token lacks the %q role needed to %s. The request will probably be denied.\n", role, action)
+}
+
+// useStaticToken sets up the API client using a pre-obtained token. OIDC
+// login and the token cache are skipped
+func useStaticToken(ac *ic.Context, token string) (*oidc.TokenSet, error) {
+	ac.EC.Logger.Debug("Using static token")
+	claims, err := jwt.DecodeWithoutVerify(token)
+	if err != nil {
+		return nil, ac.EC.ErrorHandler.NewGeneralError(
+			"Invalid token",
+			"The token must be a JWT",
+			err,
+			0,
+		)
+	}
+	if claims.HasExpiry() && claims.IsExpired() {
+		return nil, ac.EC.ErrorHandler.NewGeneralError(
+			fmt.Sprintf("Token expired at %s", claims.Expiry.Format(time.RFC3339)),
+			"Obtain a new token",
+			nil,
+			0,
+		)
+	}
+
+	if err := ac.SetupDefaultAPIClient(token, nil); err != nil {
+		return nil, ac.EC.ErrorHandler.NewGeneralError(
+			"Setup API client in",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	return &oidc.TokenSet{AccessToken: token}, nil
+}
+
+// readStaticToken returns the static token from (in order of precedence) the
+// flag, the token file or the environment. It returns an empty string if no
+// static token is given
+func readStaticToken(ac *ic.Context) (string, error) {
+	if ac.Token != "" {
+		return ac.Token, nil
+	}
+	if ac.TokenFile != "" {
+		b, err := os.ReadFile(ac.TokenFile)
+		if err != nil {
+			return "", fmt.Errorf("reading token file: %w", err)
+		}
+		if token := strings.TrimSpace(string(b)); token != "" {
+			return token, nil
+		}
+		return "", fmt.Errorf("token file %s is empty", ac.TokenFile)
+	}
+	return os.Getenv(envToken), nil
+}
+
+// readClientSecret returns the client secret from (in order of precedence)
+// the flag, the secret file or the environment
+func readClientSecret(ac *ic.Context) (string, error) {
+	if ac.OIDC.ClientSecret != "" {
+		return ac.OIDC.ClientSecret, nil
+	}
+	if ac.OIDC.ClientSecretFile != "" {
+		b, err := os.ReadFile(ac.OIDC.ClientSecretFile)
+		if err != nil {
+			return "", fmt.Errorf("reading client secret file: %w", err)
+		}
+		if secret := strings.TrimSpace(string(b)); secret != "" {
+			return secret, nil
+		}
+		return "", fmt.Errorf("client secret file %s is empty", ac.OIDC.ClientSecretFile)
+	}
+	if secret := os.Getenv(envClientSecret); secret != "" {
+		return secret, nil
+	}
+	return "", errors.New("client secret not set")
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/neticdk-k8s/ic/internal/ic"
 	"github.com/neticdk-k8s/ic/internal/usecases/authentication"
@@ -30,9 +31,19 @@ func (o *logoutOptions) Run(ctx context.Context, ac *ic.Context) error {
 	logger := ac.EC.Logger.WithGroup("Logout")
 	ac.Authenticator.SetLogger(logger)
 
+	if token, err := readStaticToken(ac); err == nil && token != "" {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Logging out",
+			fmt.Sprintf("Logging out is not possible when using --token, --token-file or %s", envToken),
+			nil,
+			0,
+		)
+	}
+
 	logoutInput := authentication.LogoutInput{
 		Provider:   *ac.OIDCProvider,
 		TokenCache: ac.TokenCache,
+		Profile:    ac.Profile,
 	}
 
 	if err := ui.Spin(ac.EC.Spinner, "Logging out", func(_ ui.Spinner) error {
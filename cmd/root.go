@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/neticdk-k8s/ic/internal/config"
+	"github.com/neticdk-k8s/ic/internal/httpclient"
 	"github.com/neticdk-k8s/ic/internal/ic"
 	"github.com/neticdk/go-common/pkg/cli/cmd"
 	"github.com/spf13/cobra"
@@ -15,11 +18,23 @@ const (
 	defaultConfigFilename = "ic"
 	envPrefix             = "IC"
 	oobRedirectURI        = "urn:ietf:wg:oauth:2.0:oob"
+	envClientSecret       = envPrefix + "_OIDC_CLIENT_SECRET"
+	envToken              = envPrefix + "_TOKEN"
+	envProfile            = envPrefix + "_PROFILE"
+	// envTokenCacheKeyFile is the key file used for the encrypted token cache
+	envTokenCacheKeyFile = envPrefix + "_TOKEN_CACHE_KEY_FILE"
+	// envTokenCachePassphrase is the passphrase used for the encrypted token
+	// cache. It can only be given using the environment
+	envTokenCachePassphrase = envPrefix + "_TOKEN_CACHE_PASSPHRASE"
+	// envTokenCacheOldPassphrase is the passphrase entries are decrypted with
+	// by "auth cache re-encrypt"
+	envTokenCacheOldPassphrase = envPrefix + "_TOKEN_CACHE_OLD_PASSPHRASE"
 
 	groupBase      = "group-base"
 	groupAuth      = "group-auth"
 	groupCluster   = "group-cluster"
 	groupComponent = "group-component"
+	groupResource  = "group-resource"
 	groupOther     = "group-other"
 )
 
@@ -29,19 +44,55 @@ const (
 	LongDesc  = `ic is a tool to manage das Inventar`
 )
 
-func newRootCmd(ac *ic.Context) *cobra.Command {
+// newPersistentFlags returns the global flags bound to ac
+func newPersistentFlags(ac *ic.Context) *pflag.FlagSet {
 	pf := pflag.NewFlagSet("", pflag.ContinueOnError)
 	pf.StringVarP(&ac.APIServer, "api-server", "s", "https://api.k8s.netic.dk", "URL for the inventory server.")
+	pf.StringVar(&ac.APIAudience, "api-audience", "", "Audience the inventory server expects in access tokens. auth status shows if the access token lacks it")
+	pf.StringVar(&ac.WriteRole, "write-role", "inventory-write", "Role needed to create, update and delete resources. Commands changing resources warn if the access token lacks it. Empty disables the check")
+	pf.StringVar(&ac.ConfigFile, "config", config.DefaultPath(), "Path to the configuration file")
+	pf.StringVar(&ac.Profile, "profile", "", fmt.Sprintf("Configuration profile to use. Can also be set using %s", envProfile))
+	pf.StringVar(&ac.Token, "token", "", fmt.Sprintf("Bearer token used instead of OIDC login. Can also be set using %s", envToken))
+	pf.StringVar(&ac.TokenFile, "token-file", "", "File containing a bearer token used instead of OIDC login")
 	pf.StringVar(&ac.OIDC.IssuerURL, "oidc-issuer-url", "https://keycloak.netic.dk/auth/realms/mcs", "Issuer URL for the OIDC Provider")
 	pf.StringVar(&ac.OIDC.ClientID, "oidc-client-id", "inventory-cli", "OIDC client ID")
-	pf.StringVar(&ac.OIDC.GrantType, "oidc-grant-type", "authcode-browser", "OIDC authorization grant type. One of (authcode-browser|authcode-keyboard)")
+	pf.StringVar(&ac.OIDC.GrantType, "oidc-grant-type", "authcode-browser", "OIDC authorization grant type. One of (authcode-browser|authcode-keyboard|client-credentials|device-code)")
 	pf.StringVar(&ac.OIDC.RedirectURLHostname, "oidc-redirect-url-hostname", "localhost", "[authcode-browser] Hostname of the redirect URL")
 	pf.StringVar(&ac.OIDC.AuthBindAddr, "oidc-auth-bind-addr", "localhost:18000", "[authcode-browser] Bind address and port for local server used for OIDC redirect")
 	pf.StringVar(&ac.OIDC.RedirectURIAuthCodeKeyboard, "oidc-redirect-uri-authcode-keyboard", oobRedirectURI, "[authcode-keyboard] Redirect URI when using authcode keyboard")
+	pf.StringSliceVar(&ac.OIDC.Scopes, "oidc-scopes", []string{"profile", "email", "roles", "offline_access"}, "OIDC scopes requested in addition to openid")
+	pf.StringVar(&ac.OIDC.ACRValues, "oidc-acr-values", "", "[authcode-browser|authcode-keyboard] Space separated authentication context class references requested, e.g. for step-up authentication. Cached tokens with another acr are not used")
+	pf.StringVar(&ac.OIDC.Prompt, "oidc-prompt", "", "[authcode-browser|authcode-keyboard] OIDC prompt parameter, e.g. login to force reauthentication")
+	pf.StringVar(&ac.OIDC.ClientSecret, "oidc-client-secret", "", fmt.Sprintf("[client-credentials] OIDC client secret. Can also be set using %s", envClientSecret))
+	pf.StringVar(&ac.OIDC.ClientSecretFile, "oidc-client-secret-file", "", "[client-credentials] File containing the OIDC client secret")
 	pf.StringVar(&ac.OIDC.TokenCacheDir, "oidc-token-cache-dir", getDefaultTokenCacheDir(), "Directory used to store cached tokens")
+	pf.StringVar(&ac.OIDC.TokenCacheType, "token-cache", "fs", "Where tokens are cached. One of (fs|keyring|encrypted-fs). keyring uses the Secret Service keyring over D-Bus and falls back to fs with a warning if no keyring is available")
+	pf.StringVar(&ac.OIDC.TokenCacheKeyFile, "token-cache-key-file", "", fmt.Sprintf("File with the key encrypting the file token cache. Can also be set using %s. A passphrase can be given using %s", envTokenCacheKeyFile, envTokenCachePassphrase))
+	pf.BoolVar(&ac.OIDC.VerifyCachedToken, "oidc-verify-cached-token", false, "Verify cached tokens against the keys of the OIDC provider before using them")
+	pf.DurationVar(&ac.OIDC.RefreshSkew, "oidc-refresh-skew", time.Minute, "Refresh cached tokens expiring within this duration")
+	pf.StringVar(&ac.HTTP.CAFile, "ca-file", "", "PEM file with CA certificates trusted in addition to the system roots for the inventory server and OIDC provider")
+	pf.StringVar(&ac.HTTP.ClientCertFile, "client-cert", "", "PEM file with the client certificate used for mutual TLS")
+	pf.StringVar(&ac.HTTP.ClientKeyFile, "client-key", "", "PEM file with the private key of the client certificate")
+	pf.StringVar(&ac.HTTP.Proxy, "proxy", "", fmt.Sprintf("URL of the proxy used for all requests or %q to not use a proxy. HTTPS_PROXY, HTTP_PROXY and NO_PROXY are used if not set", httpclient.ProxyDirect))
+	return pf
+}
 
+func newRootCmd(ac *ic.Context) *cobra.Command {
+	pf := newPersistentFlags(ac)
 	c := cmd.NewRootCommand(ac.EC).
-		WithInitFunc(func(_ *cobra.Command, _ []string) error {
+		WithInitFunc(func(cmd *cobra.Command, _ []string) error {
+			if !isConfigCmd(cmd) {
+				if err := applyProfile(cmd, ac); err != nil {
+					return fmt.Errorf("applying configuration profile: %w", err)
+				}
+			}
+			if ac.OIDC.TokenCacheKeyFile == "" {
+				ac.OIDC.TokenCacheKeyFile = os.Getenv(envTokenCacheKeyFile)
+			}
+			ac.OIDC.TokenCachePassphrase = os.Getenv(envTokenCachePassphrase)
+			if err := ac.SetupDefaultHTTPClient(); err != nil {
+				return fmt.Errorf("setting up http client: %w", err)
+			}
 			ac.SetupDefaultAuthenticator()
 			ac.SetupDefaultOIDCProvider()
 			if err := ac.SetupDefaultTokenCache(); err != nil {
@@ -71,10 +122,13 @@ func newRootCmd(ac *ic.Context) *cobra.Command {
 		loginCmd(ac),
 		logoutCmd(ac),
 		apiTokenCmd(ac),
+		authCmd(ac),
+		authStatusCmd(ac, "whoami"),
 		getCmd(ac),
 		createCmd(ac),
 		deleteCmd(ac),
 		updateCmd(ac),
+		configCmd(ac),
 		filtersHelpCmd(ac),
 	)
 
@@ -16,12 +16,31 @@ func Test_Wiring(t *testing.T) {
 		"get",
 		"get cluster",
 		"get clusters",
+		"get cluster-pods",
+		"get kubeconfigs",
+		"get resources",
+		"get resource",
 		"get regions",
 		"get partitions",
 		"create cluster",
 		"delete cluster",
+		"update cluster",
+		"update cluster-kubeconfig",
 		"login",
 		"logout",
+		"auth",
+		"auth status",
+		"auth cache",
+		"auth cache list",
+		"auth cache purge",
+		"auth cache re-encrypt",
+		"auth cache show",
+		"auth exec-credential",
+		"whoami",
+		"config",
+		"config get-profiles",
+		"config use-profile",
+		"config set",
 	}
 
 	for _, tc := range testcases {
@@ -119,6 +138,37 @@ func Test_OIDCFlags(t *testing.T) {
 		err := cmd.ExecuteContext(context.Background())
 		assert.NoError(t, err)
 		assert.Equal(t, ac.OIDC.TokenCacheDir, "/tmp")
+		assert.Equal(t, "fs", ac.OIDC.TokenCacheType)
+		assert.Equal(t, "inventory-write", ac.WriteRole)
 		assert.NotNil(t, ac.TokenCache)
 	})
+
+	t.Run("--token-cache", func(t *testing.T) {
+		for _, tc := range []struct {
+			value   string
+			wantErr string
+		}{
+			{value: "fs"},
+			{value: "keyring"},
+			{value: "encrypted-fs", wantErr: "needs a passphrase or key file"},
+			{value: "memory", wantErr: `unknown token cache "memory"`},
+		} {
+			got := new(bytes.Buffer)
+			ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+			ec.Stderr = got
+			ec.Stdout = got
+			ac := ic.NewContext()
+			ac.EC = ec
+			cmd := newRootCmd(ac)
+			cmd.SetArgs([]string{"--token-cache", tc.value, "--oidc-token-cache-dir", t.TempDir()})
+			err := cmd.ExecuteContext(context.Background())
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				continue
+			}
+			assert.NoError(t, err)
+			// keyring falls back to the file cache if unavailable
+			assert.NotNil(t, ac.TokenCache)
+		}
+	})
 }
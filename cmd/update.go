@@ -23,6 +23,7 @@ func updateCmd(ac *ic.Context) *cobra.Command {
 
 	c.AddCommand(
 		updateClusterCmd(ac),
+		updateClusterKubeConfigCmd(ac),
 	)
 
 	c.AddGroup(
@@ -46,5 +47,9 @@ func updateCmdExample() string {
 	b.WriteString("	--resilience-zone new-resilience-zone\n")
 	b.WriteString("\n")
 
+	b.WriteString("  # Upload a new kubeconfig for a cluster\n")
+	b.WriteString("  ic update cluster-kubeconfig mycluster.my-provider --file kubeconfig.yaml\n")
+	b.WriteString("\n")
+
 	return b.String()
 }
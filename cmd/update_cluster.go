@@ -148,10 +148,11 @@ func (o *updateClusterOptions) Run(ctx context.Context, ac *ic.Context) error {
 	logger := ac.EC.Logger.WithGroup("Clusters")
 	ac.Authenticator.SetLogger(logger)
 
-	_, err := doLogin(ctx, ac)
+	tokenSet, err := doLogin(ctx, ac)
 	if err != nil {
 		return err
 	}
+	warnMissingRole(ac, tokenSet, "update clusters")
 
 	var result *cluster.UpdateClusterResult
 	spinnerText := fmt.Sprintf("Updating cluster metadata for %q", o.clusterID)
@@ -212,7 +213,7 @@ func (o *updateClusterOptions) Run(ctx context.Context, ac *ic.Context) error {
 		)
 	}
 
-	r := cluster.NewClusterRenderer(result.ClusterResponse, result.JSONResponse, ac.EC.Stdout)
+	r := cluster.NewClusterRenderer(result.ClusterResponse, result.JSONResponse, ac.EC.Stdout, nil)
 	if err := r.Render(ac.EC.PFlags.OutputFormat); err != nil {
 		return ac.EC.ErrorHandler.NewGeneralError(
 			"Failed to render output",
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/neticdk-k8s/ic/internal/errors"
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/usecases/cluster"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/neticdk/go-common/pkg/cli/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const updateClusterKubeConfigExample = `
+# upload a kubeconfig for a cluster from a file
+ic update cluster-kubeconfig my-cluster.my-provider --file kubeconfig.yaml
+
+# upload a kubeconfig for a cluster from stdin
+cat kubeconfig.yaml | ic update cluster-kubeconfig my-cluster.my-provider --file -`
+
+// New creates a new "update cluster-kubeconfig" command
+func updateClusterKubeConfigCmd(ac *ic.Context) *cobra.Command {
+	o := &updateClusterKubeConfigOptions{}
+	c := cmd.NewSubCommand("cluster-kubeconfig", o, ac).
+		WithShortDesc("Upload a cluster kubeconfig").
+		WithGroupID(groupCluster).
+		WithExample(updateClusterKubeConfigExample).
+		WithExactArgs(1).
+		Build()
+	c.Use = "cluster-kubeconfig CLUSTER-ID"
+	c.Aliases = []string{"kubeconfig"}
+
+	o.bindFlags(c.Flags())
+	c.MarkFlagRequired("file") //nolint:errcheck
+	return c
+}
+
+type updateClusterKubeConfigOptions struct {
+	clusterID string
+	File      string
+}
+
+func (o *updateClusterKubeConfigOptions) bindFlags(f *pflag.FlagSet) {
+	f.StringVar(&o.File, "file", "", "Path to the kubeconfig file. Use - to read from stdin")
+}
+
+func (o *updateClusterKubeConfigOptions) Complete(_ context.Context, ac *ic.Context) error {
+	o.clusterID = ac.EC.CommandArgs[0]
+	return nil
+}
+
+func (o *updateClusterKubeConfigOptions) Validate(_ context.Context, _ *ic.Context) error {
+	if o.File == "" {
+		return &cmd.InvalidArgumentError{
+			Flag:    "file",
+			Val:     o.File,
+			Context: "must be a path to a file or -",
+		}
+	}
+	return nil
+}
+
+func (o *updateClusterKubeConfigOptions) Run(ctx context.Context, ac *ic.Context) error {
+	logger := ac.EC.Logger.WithGroup("ClusterKubeConfig")
+	ac.Authenticator.SetLogger(logger)
+
+	data, err := o.readKubeConfig(ac)
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Reading kubeconfig",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+	kubeConfig, err := cluster.ParseKubeConfig(data)
+	if err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Parsing kubeconfig",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+	if err := cluster.ValidateKubeConfig(kubeConfig); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Invalid kubeconfig",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+
+	tokenSet, err := doLogin(ctx, ac)
+	if err != nil {
+		return err
+	}
+	warnMissingRole(ac, tokenSet, "upload kubeconfigs")
+
+	var result *cluster.UpdateClusterKubeConfigResult
+	spinnerText := fmt.Sprintf("Uploading kubeconfig for %q", o.clusterID)
+	if err := ui.Spin(ac.EC.Spinner, spinnerText, func(s ui.Spinner) error {
+		in := cluster.UpdateClusterKubeConfigInput{
+			Logger:     logger,
+			APIClient:  ac.APIClient,
+			ClusterID:  o.clusterID,
+			KubeConfig: kubeConfig,
+		}
+		result, err = cluster.UpdateClusterKubeConfig(ctx, in)
+		if err == nil {
+			ui.UpdateSpinnerText(s, "Kubeconfig uploaded")
+		}
+		return err
+	}); err != nil {
+		return ac.EC.ErrorHandler.NewGeneralError(
+			"Uploading kubeconfig",
+			"See details for more information",
+			err,
+			0,
+		)
+	}
+	if result.Problem != nil {
+		return &errors.ProblemError{
+			Title:   "uploading cluster kubeconfig",
+			Problem: result.Problem,
+		}
+	}
+
+	return nil
+}
+
+func (o *updateClusterKubeConfigOptions) readKubeConfig(ac *ic.Context) ([]byte, error) {
+	if o.File == "-" {
+		return io.ReadAll(ac.EC.Stdin)
+	}
+	return os.ReadFile(o.File)
+}
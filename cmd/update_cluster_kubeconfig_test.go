@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/neticdk-k8s/ic/internal/apiclient"
+	"github.com/neticdk-k8s/ic/internal/ic"
+	"github.com/neticdk-k8s/ic/internal/oidc"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication"
+	"github.com/neticdk/go-common/pkg/cli/cmd"
+	"github.com/neticdk/go-common/pkg/cli/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testUpdateKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: my-cluster
+  cluster:
+    server: https://my-cluster.example.com:6443
+contexts:
+- name: my-context
+  context:
+    cluster: my-cluster
+    user: my-user
+current-context: my-context
+users:
+- name: my-user
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: kubectl
+`
+
+func Test_UpdateClusterKubeConfigCommand(t *testing.T) {
+	got := new(bytes.Buffer)
+	ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+	ec.Stdin = strings.NewReader(testUpdateKubeConfig)
+	ec.Stderr = got
+	ec.Stdout = got
+	ui.SetDefaultOutput(got)
+	ac := ic.NewContext()
+	ac.EC = ec
+	mockAuthenticator := authentication.NewMockAuthenticator(t)
+	mockAuthenticator.EXPECT().
+		SetLogger(mock.Anything).
+		Run(func(_ *slog.Logger) {}).
+		Return()
+	mockAuthenticator.EXPECT().
+		Login(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, in authentication.LoginInput) {}).
+		Return(&oidc.TokenSet{
+			AccessToken:  "YOUR_ACCESS_TOKEN",
+			IDToken:      "YOUR_ID_TOKEN",
+			RefreshToken: "YOUR_REFRESH_TOKEN",
+		}, nil)
+	ac.Authenticator = mockAuthenticator
+	mockClientWithResponsesInterface := apiclient.NewMockClientWithResponsesInterface(t)
+	mockClientWithResponsesInterface.EXPECT().
+		UpdateClusterKubeConfigWithResponse(mock.Anything, "my-cluster.my-provider", mock.MatchedBy(func(kc apiclient.KubeConfig) bool {
+			return *(*kc.Users)[0].User.Exec.Command == "kubectl"
+		})).
+		Return(
+			&apiclient.UpdateClusterKubeConfigResponse{
+				Body: make([]byte, 0),
+				HTTPResponse: &http.Response{
+					Status:     "204 No Content",
+					StatusCode: 204,
+				},
+			}, nil)
+	ac.APIClient = mockClientWithResponsesInterface
+
+	cmd := newRootCmd(ac)
+	cmd.SetArgs([]string{"update", "cluster-kubeconfig", "my-cluster.my-provider", "--file", "-"})
+	err := cmd.ExecuteContext(context.Background())
+	assert.NoError(t, err)
+	assert.Contains(t, got.String(), "Kubeconfig uploaded")
+}
+
+func Test_UpdateClusterKubeConfigCommandInvalidKubeConfig(t *testing.T) {
+	got := new(bytes.Buffer)
+	ec := cmd.NewExecutionContext(AppName, ShortDesc, "test")
+	ec.Stdin = strings.NewReader("apiVersion: v1\nkind: Config\n")
+	ec.Stderr = got
+	ec.Stdout = got
+	ui.SetDefaultOutput(got)
+	ac := ic.NewContext()
+	ac.EC = ec
+	mockAuthenticator := authentication.NewMockAuthenticator(t)
+	mockAuthenticator.EXPECT().
+		SetLogger(mock.Anything).
+		Run(func(_ *slog.Logger) {}).
+		Return()
+	ac.Authenticator = mockAuthenticator
+
+	cmd := newRootCmd(ac)
+	cmd.SetArgs([]string{"update", "cluster-kubeconfig", "my-cluster.my-provider", "--file", "-"})
+	err := cmd.ExecuteContext(context.Background())
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "no clusters defined")
+}
@@ -3,10 +3,13 @@ package apiclient
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"sync"
 )
 
 type bearerToken struct {
+	mu    sync.RWMutex
 	token string
 }
 
@@ -17,6 +20,86 @@ func NewBearerTokenProvider(token string) *bearerToken {
 
 // WithAuthHeader adds Authorization: Bearer header to the request
 func (s *bearerToken) WithAuthHeader(_ context.Context, req *http.Request) error {
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.token))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.Token()))
 	return nil
 }
+
+// Token returns the current token
+func (s *bearerToken) Token() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.token
+}
+
+// TokenRefreshFunc obtains a new token when the server rejects the current one
+type TokenRefreshFunc func(ctx context.Context) (string, error)
+
+type refreshingDoer struct {
+	doer     HttpRequestDoer
+	provider *bearerToken
+	refresh  TokenRefreshFunc
+	// mu serializes refreshes so concurrent requests rejected with the same
+	// token only refresh it once
+	mu sync.Mutex
+}
+
+// NewRefreshingDoer creates a HttpRequestDoer retrying requests rejected with
+// 401 Unauthorized once. Before retrying the token of provider is replaced by a
+// token obtained using refresh. The original response is returned if the token
+// cannot be refreshed
+func NewRefreshingDoer(doer HttpRequestDoer, provider *bearerToken, refresh TokenRefreshFunc) *refreshingDoer {
+	return &refreshingDoer{
+		doer:     doer,
+		provider: provider,
+		refresh:  refresh,
+	}
+}
+
+// Do performs req
+func (d *refreshingDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.doer.Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	// the body has been consumed and can only be sent again if it can be
+	// recreated
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	token, err := d.refreshToken(req)
+	if err != nil {
+		return resp, nil
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	retry.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	return d.doer.Do(retry)
+}
+
+// refreshToken returns a token to retry req with. The token is only refreshed
+// if req was sent with the current token
+func (d *refreshingDoer) refreshToken(req *http.Request) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	current := d.provider.Token()
+	if req.Header.Get("Authorization") != fmt.Sprintf("Bearer %s", current) {
+		return current, nil
+	}
+	token, err := d.refresh(req.Context())
+	if err != nil {
+		return "", err
+	}
+	d.provider.mu.Lock()
+	d.provider.token = token
+	d.provider.mu.Unlock()
+	return token, nil
+}
@@ -0,0 +1,82 @@
+package apiclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshingDoer(t *testing.T) {
+	// server accepts VALID_TOKEN and echoes the request body
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer VALID_TOKEN" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.Copy(w, r.Body)
+	}))
+	defer server.Close()
+
+	newRequest := func(t *testing.T, provider *bearerToken) *http.Request {
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("YOUR_BODY"))
+		assert.NoError(t, err)
+		assert.NoError(t, provider.WithAuthHeader(context.Background(), req))
+		return req
+	}
+
+	t.Run("RetriesWithRefreshedToken", func(t *testing.T) {
+		provider := NewBearerTokenProvider("REJECTED_TOKEN")
+		refreshes := 0
+		doer := NewRefreshingDoer(server.Client(), provider, func(_ context.Context) (string, error) {
+			refreshes++
+			return "VALID_TOKEN", nil
+		})
+
+		resp, err := doer.Do(newRequest(t, provider))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "YOUR_BODY", string(body))
+		assert.Equal(t, "VALID_TOKEN", provider.Token())
+
+		resp, err = doer.Do(newRequest(t, provider))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, 1, refreshes, "later requests use the refreshed token")
+	})
+
+	t.Run("RetriesOnce", func(t *testing.T) {
+		provider := NewBearerTokenProvider("REJECTED_TOKEN")
+		refreshes := 0
+		doer := NewRefreshingDoer(server.Client(), provider, func(_ context.Context) (string, error) {
+			refreshes++
+			return "OTHER_REJECTED_TOKEN", nil
+		})
+
+		resp, err := doer.Do(newRequest(t, provider))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t, 1, refreshes)
+	})
+
+	t.Run("RefreshError", func(t *testing.T) {
+		provider := NewBearerTokenProvider("REJECTED_TOKEN")
+		doer := NewRefreshingDoer(server.Client(), provider, func(_ context.Context) (string, error) {
+			return "", errors.New("refresh token expired")
+		})
+
+		resp, err := doer.Do(newRequest(t, provider))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t, "REJECTED_TOKEN", provider.Token())
+	})
+}
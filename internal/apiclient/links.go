@@ -0,0 +1,55 @@
+package apiclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// LinkFollower requests the resources referenced by links in responses, e.g.
+// the collections linked from a cluster
+type LinkFollower interface {
+	// FollowLink requests link. Relative links are resolved against the
+	// server URL. Links to other hosts are refused as the request editors
+	// add credentials
+	FollowLink(ctx context.Context, link string, reqEditors ...RequestEditorFn) (*http.Response, error)
+}
+
+// FollowLink requests link
+func (c *Client) FollowLink(ctx context.Context, link string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	serverURL, err := url.Parse(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	// keep the path of the server URL like the generated requests do
+	if strings.HasPrefix(link, "/") {
+		link = "." + link
+	}
+	linkURL, err := serverURL.Parse(link)
+	if err != nil {
+		return nil, fmt.Errorf("parsing link: %w", err)
+	}
+	if linkURL.Scheme != serverURL.Scheme || linkURL.Host != serverURL.Host {
+		return nil, fmt.Errorf("link %s does not refer to the api server", link)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, linkURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+// FollowLink requests link using the wrapped client
+func (c *ClientWithResponses) FollowLink(ctx context.Context, link string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	f, ok := c.ClientInterface.(LinkFollower)
+	if !ok {
+		return nil, errors.New("client cannot follow links")
+	}
+	return f.FollowLink(ctx, link, reqEditors...)
+}
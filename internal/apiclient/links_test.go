@@ -0,0 +1,42 @@
+package apiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_FollowLink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer YOUR_TOKEN" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	provider := NewBearerTokenProvider("YOUR_TOKEN")
+	client, err := NewClientWithResponses(server.URL+"/api", WithRequestEditorFn(provider.WithAuthHeader))
+	assert.NoError(t, err)
+
+	for link, want := range map[string]string{
+		"/clusters/my-cluster/pods":                  "/api/clusters/my-cluster/pods",
+		server.URL + "/api/clusters/my-cluster/pods": "/api/clusters/my-cluster/pods",
+	} {
+		resp, err := client.FollowLink(context.Background(), link)
+		if assert.NoError(t, err, link) {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode, link)
+			assert.Equal(t, want, string(body), link)
+		}
+	}
+
+	_, err = client.FollowLink(context.Background(), "https://evil.example.com/clusters")
+	assert.ErrorContains(t, err, "does not refer to the api server")
+}
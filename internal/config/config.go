@@ -0,0 +1,148 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	newDirPermissions  = 0o700
+	newFilePermissions = 0o600
+)
+
+// Config is the ic configuration file
+type Config struct {
+	// CurrentProfile is the profile used when no profile is given
+	CurrentProfile string `json:"current-profile,omitempty"`
+	// Profiles are the named profiles
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+}
+
+// Profile holds settings by name. The names are the names of the command line
+// flags they set
+type Profile map[string]string
+
+// ProfileNotFoundError is returned when a profile does not exist
+type ProfileNotFoundError struct {
+	Name string
+}
+
+func (e *ProfileNotFoundError) Error() string {
+	return fmt.Sprintf("profile %q not found", e.Name)
+}
+
+// New returns an empty configuration
+func New() *Config {
+	return &Config{
+		Profiles: map[string]Profile{},
+	}
+}
+
+// DefaultPath returns the default location of the configuration file
+func DefaultPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "ic", "config.yaml")
+	}
+	return filepath.Join(configDir, "ic", "config.yaml")
+}
+
+// Load reads the configuration file at path. A missing file yields an empty
+// configuration
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+	c := New()
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	if c.Profiles == nil {
+		c.Profiles = map[string]Profile{}
+	}
+	return c, nil
+}
+
+// Save writes the configuration file to path
+func (c *Config) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("encoding config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), newDirPermissions); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, newFilePermissions); err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	return nil
+}
+
+// ProfileNames returns the names of all profiles in sorted order
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Profile returns the named profile
+func (c *Config) Profile(name string) (Profile, error) {
+	p, ok := c.Profiles[name]
+	if !ok {
+		return nil, &ProfileNotFoundError{Name: name}
+	}
+	return p, nil
+}
+
+// UseProfile sets the current profile
+func (c *Config) UseProfile(name string) error {
+	if _, err := c.Profile(name); err != nil {
+		return err
+	}
+	c.CurrentProfile = name
+	return nil
+}
+
+// Set sets key to value in the named profile. The profile is created if it
+// does not exist. An empty value removes the key
+func (c *Config) Set(profile, key, value string) error {
+	if err := ValidateProfileName(profile); err != nil {
+		return err
+	}
+	p, ok := c.Profiles[profile]
+	if !ok {
+		p = Profile{}
+		c.Profiles[profile] = p
+	}
+	if value == "" {
+		delete(p, key)
+		return nil
+	}
+	p[key] = value
+	return nil
+}
+
+// ValidateProfileName checks that name can be used as a profile name
+func ValidateProfileName(name string) error {
+	if name == "" {
+		return errors.New("profile name must not be empty")
+	}
+	if strings.ContainsAny(name, " \t\n/\\") {
+		return fmt.Errorf("profile name %q must not contain whitespace or slashes", name)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("Missing", func(t *testing.T) {
+		c, err := Load(filepath.Join(t.TempDir(), "config.yaml"))
+		assert.NoError(t, err)
+		assert.Equal(t, New(), c)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		data := `current-profile: staging
+profiles:
+  staging:
+    api-server: https://api.staging.example.com
+  local:
+    api-server: http://localhost:8087
+`
+		assert.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+		c, err := Load(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "staging", c.CurrentProfile)
+		assert.Equal(t, []string{"local", "staging"}, c.ProfileNames())
+		p, err := c.Profile("local")
+		assert.NoError(t, err)
+		assert.Equal(t, Profile{"api-server": "http://localhost:8087"}, p)
+	})
+
+	t.Run("UnknownField", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		assert.NoError(t, os.WriteFile(path, []byte("current-context: staging\n"), 0o600))
+		_, err := Load(path)
+		assert.ErrorContains(t, err, "parsing config")
+	})
+}
+
+func TestConfig_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ic", "config.yaml")
+	c := New()
+	assert.NoError(t, c.Set("staging", "api-server", "https://api.staging.example.com"))
+	assert.NoError(t, c.UseProfile("staging"))
+	assert.NoError(t, c.Save(path))
+
+	fi, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+
+	got, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, c, got)
+}
+
+func TestConfig_Set(t *testing.T) {
+	c := New()
+	assert.NoError(t, c.Set("local", "api-server", "http://localhost:8087"))
+	assert.NoError(t, c.Set("local", "oidc-client-id", "my-client"))
+	assert.Equal(t, Profile{"api-server": "http://localhost:8087", "oidc-client-id": "my-client"}, c.Profiles["local"])
+
+	assert.NoError(t, c.Set("local", "oidc-client-id", ""))
+	assert.Equal(t, Profile{"api-server": "http://localhost:8087"}, c.Profiles["local"])
+
+	assert.Error(t, c.Set("", "api-server", "x"))
+	assert.Error(t, c.Set("my profile", "api-server", "x"))
+}
+
+func TestConfig_UseProfile(t *testing.T) {
+	c := New()
+	err := c.UseProfile("missing")
+	var notFound *ProfileNotFoundError
+	assert.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "", c.CurrentProfile)
+}
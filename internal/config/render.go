@@ -0,0 +1,62 @@
+package config
+
+import (
+	"io"
+
+	"github.com/neticdk-k8s/ic/internal/render"
+)
+
+type profilesRenderer struct {
+	render.Base
+	config *Config
+}
+
+// NewProfilesRenderer creates a new renderer of the profiles in a config
+func NewProfilesRenderer(config *Config, writer io.Writer, noHeaders bool) *profilesRenderer {
+	profiles := profilesJSON(config)
+	pr := &profilesRenderer{
+		Base:   render.Base{Writer: writer, NoHeaders: noHeaders, Value: profiles, Items: profiles},
+		config: config,
+	}
+	pr.Table = pr.table
+	pr.Names = config.ProfileNames
+	return pr
+}
+
+var profileColumns = []render.Column{
+	{Name: "current"},
+	{Name: "name"},
+	{Name: "api-server"},
+	{Name: "oidc-issuer-url"},
+}
+
+func (r *profilesRenderer) table() *render.Table {
+	t := &render.Table{Columns: profileColumns}
+	for _, name := range r.config.ProfileNames() {
+		current := ""
+		if name == r.config.CurrentProfile {
+			current = "*"
+		}
+		p := r.config.Profiles[name]
+		t.Rows = append(t.Rows, []string{current, name, p["api-server"], p["oidc-issuer-url"]})
+	}
+	return t
+}
+
+type profileJSON struct {
+	Name     string  `json:"name"`
+	Current  bool    `json:"current"`
+	Settings Profile `json:"settings"`
+}
+
+func profilesJSON(config *Config) []profileJSON {
+	profiles := make([]profileJSON, 0, len(config.Profiles))
+	for _, name := range config.ProfileNames() {
+		profiles = append(profiles, profileJSON{
+			Name:     name,
+			Current:  name == config.CurrentProfile,
+			Settings: config.Profiles[name],
+		})
+	}
+	return profiles
+}
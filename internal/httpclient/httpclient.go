@@ -0,0 +1,103 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// ProxyDirect disables the use of a proxy, including proxies given by the
+// environment
+const ProxyDirect = "direct"
+
+// Config holds the settings of the HTTP client shared by the inventory API
+// and OIDC clients
+type Config struct {
+	// CAFile is a PEM file with root certificates trusted in addition to the
+	// system roots. Optional
+	CAFile string
+	// ClientCertFile is a PEM file with the client certificate used for
+	// mutual TLS. It must be given along with ClientKeyFile
+	ClientCertFile string
+	// ClientKeyFile is a PEM file with the private key of ClientCertFile
+	ClientKeyFile string
+	// Proxy is the URL of the proxy used for all requests or ProxyDirect. The
+	// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables are used if
+	// empty
+	Proxy string
+}
+
+// New creates an HTTP client from cfg
+func New(cfg Config) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	proxy, err := proxyFunc(cfg.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	transport.Proxy = proxy
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Transport: transport}, nil
+}
+
+func proxyFunc(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	switch proxy {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case ProxyDirect:
+		return nil, nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("parsing proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("proxy URL %q must use one of (http|https|socks5) or be %q", proxy, ProxyDirect)
+	}
+	return http.ProxyURL(u), nil
+}
+
+func newTLSConfig(cfg Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	switch {
+	case cfg.ClientCertFile == "" && cfg.ClientKeyFile == "":
+	case cfg.ClientCertFile == "" || cfg.ClientKeyFile == "":
+		return nil, errors.New("a client certificate and key must be given together")
+	default:
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,132 @@
+package httpclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("could not write %s: %s", name, err)
+	}
+	return p
+}
+
+// newClientCert returns a self-signed client certificate and the files
+// holding it and its key
+func newClientCert(t *testing.T) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("could not generate key: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "YOUR_CLIENT"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("could not create certificate: %s", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("could not parse certificate: %s", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("could not marshal key: %s", err)
+	}
+	return cert, writePEM(t, "client.crt", "CERTIFICATE", der), writePEM(t, "client.key", "PRIVATE KEY", keyDER)
+}
+
+func TestNew(t *testing.T) {
+	clientCert, clientCertFile, clientKeyFile := newClientCert(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+	caFile := writePEM(t, "ca.crt", "CERTIFICATE", server.Certificate().Raw)
+
+	t.Run("MutualTLS", func(t *testing.T) {
+		client, err := New(Config{CAFile: caFile, ClientCertFile: clientCertFile, ClientKeyFile: clientKeyFile})
+		assert.NoError(t, err)
+		resp, err := client.Get(server.URL)
+		if assert.NoError(t, err) {
+			resp.Body.Close()
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		}
+	})
+
+	t.Run("UnknownAuthority", func(t *testing.T) {
+		client, err := New(Config{ClientCertFile: clientCertFile, ClientKeyFile: clientKeyFile})
+		assert.NoError(t, err)
+		_, err = client.Get(server.URL)
+		assert.ErrorContains(t, err, "certificate")
+	})
+
+	t.Run("NoClientCertificate", func(t *testing.T) {
+		client, err := New(Config{CAFile: caFile})
+		assert.NoError(t, err)
+		_, err = client.Get(server.URL)
+		assert.Error(t, err)
+	})
+
+	t.Run("InvalidCAFile", func(t *testing.T) {
+		_, err := New(Config{CAFile: clientKeyFile})
+		assert.ErrorContains(t, err, "no certificates found")
+	})
+
+	t.Run("ClientKeyMissing", func(t *testing.T) {
+		_, err := New(Config{ClientCertFile: clientCertFile})
+		assert.ErrorContains(t, err, "must be given together")
+	})
+}
+
+func TestNew_Proxy(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://inventory.example.com", nil)
+
+	t.Run("URL", func(t *testing.T) {
+		client, err := New(Config{Proxy: "http://proxy.example.com:3128"})
+		assert.NoError(t, err)
+		proxyURL, err := client.Transport.(*http.Transport).Proxy(req)
+		assert.NoError(t, err)
+		assert.Equal(t, "http://proxy.example.com:3128", proxyURL.String())
+	})
+
+	t.Run("Direct", func(t *testing.T) {
+		t.Setenv("HTTPS_PROXY", "http://proxy.example.com:3128")
+		client, err := New(Config{Proxy: ProxyDirect})
+		assert.NoError(t, err)
+		assert.Nil(t, client.Transport.(*http.Transport).Proxy)
+	})
+
+	t.Run("InvalidScheme", func(t *testing.T) {
+		_, err := New(Config{Proxy: "proxy.example.com:3128"})
+		assert.Error(t, err)
+	})
+}
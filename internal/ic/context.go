@@ -2,13 +2,18 @@ package ic
 
 import (
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/neticdk-k8s/ic/internal/apiclient"
+	"github.com/neticdk-k8s/ic/internal/httpclient"
 	"github.com/neticdk-k8s/ic/internal/oidc"
 	"github.com/neticdk-k8s/ic/internal/reader"
 	"github.com/neticdk-k8s/ic/internal/tokencache"
 	"github.com/neticdk-k8s/ic/internal/usecases/authentication"
 	"github.com/neticdk-k8s/ic/internal/usecases/authentication/authcode"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication/clientcredentials"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication/devicecode"
 	"github.com/neticdk/go-common/pkg/cli/cmd"
 )
 
@@ -20,7 +25,17 @@ type OIDCConfig struct {
 	RedirectURLHostname         string
 	RedirectURIAuthCodeKeyboard string
 	AuthBindAddr                string
+	Scopes                      []string
+	ACRValues                   string
+	Prompt                      string
 	TokenCacheDir               string
+	TokenCacheType              string
+	TokenCacheKeyFile           string
+	TokenCachePassphrase        string
+	ClientSecret                string
+	ClientSecretFile            string
+	VerifyCachedToken           bool
+	RefreshSkew                 time.Duration
 }
 
 type Context struct {
@@ -29,9 +44,37 @@ type Context struct {
 	// APIServer is the inventory api server endpoint
 	APIServer string
 
+	// APIAudience is the audience the inventory server expects in access
+	// tokens. It is not checked if empty
+	APIAudience string
+
+	// WriteRole is the role needed to create, update and delete resources.
+	// It is not checked if empty
+	WriteRole string
+
 	// APIClient is an inventory server api client
 	APIClient apiclient.ClientWithResponsesInterface
 
+	// HTTP is the settings of HTTPClient
+	HTTP httpclient.Config
+
+	// HTTPClient is the HTTP client shared by the api client and the OIDC
+	// client
+	HTTPClient *http.Client
+
+	// ConfigFile is the path to the configuration file
+	ConfigFile string
+
+	// Profile is the name of the active configuration profile. It is empty if
+	// no profile is used
+	Profile string
+
+	// Token is a pre-obtained bearer token used instead of OIDC login
+	Token string
+
+	// TokenFile is a file holding a pre-obtained bearer token
+	TokenFile string
+
 	// OIDC is the OIDC settings
 	OIDC OIDCConfig
 
@@ -50,20 +93,43 @@ func NewContext() *Context {
 }
 
 // SetupDefaultAPIClient sets up ec.APIClient from flags if it's not already set
-func (ac *Context) SetupDefaultAPIClient(token string) (err error) {
+// Requests rejected with 401 Unauthorized are retried once with a token
+// obtained using refresh unless refresh is nil
+func (ac *Context) SetupDefaultAPIClient(token string, refresh apiclient.TokenRefreshFunc) (err error) {
 	if ac.APIClient != nil {
 		return
 	}
 
+	if err = ac.SetupDefaultHTTPClient(); err != nil {
+		return
+	}
+
 	provider := apiclient.NewBearerTokenProvider(token)
-	ac.APIClient, err = apiclient.NewClientWithResponses(
-		ac.APIServer,
-		apiclient.WithRequestEditorFn(provider.WithAuthHeader))
+	var doer apiclient.HttpRequestDoer = ac.HTTPClient
+	if refresh != nil {
+		doer = apiclient.NewRefreshingDoer(ac.HTTPClient, provider, refresh)
+	}
+	opts := []apiclient.ClientOption{
+		apiclient.WithRequestEditorFn(provider.WithAuthHeader),
+		apiclient.WithHTTPClient(doer),
+	}
+	ac.APIClient, err = apiclient.NewClientWithResponses(ac.APIServer, opts...)
 	return
 }
 
-// SetupDefaultAuthenticator sets up ec.Authenticator from flags if it's not already set
+// SetupDefaultHTTPClient sets up ec.HTTPClient from flags if it's not already set
 // It should be called from rootCmd.PersistentPreRunE
+func (ac *Context) SetupDefaultHTTPClient() (err error) {
+	if ac.HTTPClient != nil {
+		return
+	}
+
+	ac.HTTPClient, err = httpclient.New(ac.HTTP)
+	return
+}
+
+// SetupDefaultAuthenticator sets up ec.Authenticator from flags if it's not already set
+// It should be called from rootCmd.PersistentPreRunE after SetupDefaultHTTPClient
 func (ac *Context) SetupDefaultAuthenticator() {
 	if ac.Authenticator != nil {
 		return
@@ -71,9 +137,11 @@ func (ac *Context) SetupDefaultAuthenticator() {
 
 	authn := authentication.NewAuthentication(
 		ac.EC.Logger,
-		nil,
+		&oidc.Factory{Logger: ac.EC.Logger, HTTPClient: ac.HTTPClient},
 		&authcode.Browser{Logger: ac.EC.Logger},
-		&authcode.Keyboard{Reader: reader.NewReader(), Logger: ac.EC.Logger})
+		&authcode.Keyboard{Reader: reader.NewReader(), Writer: ac.EC.Stderr, Logger: ac.EC.Logger},
+		&clientcredentials.ClientCredentials{Logger: ac.EC.Logger},
+		&devicecode.DeviceCode{Writer: ac.EC.Stderr, Logger: ac.EC.Logger})
 	ac.Authenticator = authentication.NewAuthenticator(ac.EC.Logger, authn)
 }
 
@@ -86,7 +154,7 @@ func (ac *Context) SetupDefaultOIDCProvider() {
 	ac.OIDCProvider = &oidc.Provider{
 		IssuerURL:   ac.OIDC.IssuerURL,
 		ClientID:    ac.OIDC.ClientID,
-		ExtraScopes: []string{"profile", "email", "roles", "offline_access"},
+		ExtraScopes: ac.OIDC.Scopes,
 	}
 }
 
@@ -97,9 +165,72 @@ func (ac *Context) SetupDefaultTokenCache() (err error) {
 		return
 	}
 
-	if ac.TokenCache, err = tokencache.NewFSCache(ac.OIDC.TokenCacheDir); err != nil {
+	switch ac.OIDC.TokenCacheType {
+	case "keyring":
+		keyringCache, err := tokencache.NewKeyringCache(ac.OIDC.TokenCacheDir)
+		if err == nil {
+			ac.TokenCache = keyringCache
+			return nil
+		}
+		ac.warnKeyringFallback(err)
+	case "fs":
+	case "encrypted-fs":
+		if ac.tokenCacheKeySource().IsZero() {
+			return fmt.Errorf("the encrypted-fs token cache needs a passphrase or key file")
+		}
+	default:
+		return fmt.Errorf("unknown token cache %q. Use one of (keyring|fs|encrypted-fs)", ac.OIDC.TokenCacheType)
+	}
+
+	if ac.TokenCache, err = ac.NewFileTokenCache(); err != nil {
 		return fmt.Errorf("creating token cache: %w", err)
 	}
 
 	return
 }
+
+// NewFileTokenCache creates the file token cache in the token cache directory.
+// Entries are encrypted if a passphrase or key file is given
+func (ac *Context) NewFileTokenCache() (tokencache.FileCache, error) {
+	keySource := ac.tokenCacheKeySource()
+	if keySource.IsZero() {
+		return tokencache.NewFSCache(ac.OIDC.TokenCacheDir)
+	}
+	c, err := tokencache.NewEncryptedFSCache(ac.OIDC.TokenCacheDir, keySource)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewEntryTokenCache creates the token cache given by --token-cache for
+// listing and removing entries. The file cache is used if the keyring is not
+// available
+func (ac *Context) NewEntryTokenCache() (tokencache.EntryCache, error) {
+	if ac.OIDC.TokenCacheType == "keyring" {
+		keyringCache, err := tokencache.NewKeyringCache(ac.OIDC.TokenCacheDir)
+		if err == nil {
+			return keyringCache, nil
+		}
+		ac.warnKeyringFallback(err)
+	}
+	return ac.NewFileTokenCache()
+}
+
+// warnKeyringFallback warns that tokens are cached in files as the keyring
+// is not available. The warning is logged to stderr so it does not mix with
+// output such as exec credentials
+func (ac *Context) warnKeyringFallback(err error) {
+	where := "plaintext files"
+	if !ac.tokenCacheKeySource().IsZero() {
+		where = "encrypted files"
+	}
+	ac.EC.Logger.Warn(fmt.Sprintf("Keyring not available - caching tokens in %s in %s instead", where, ac.OIDC.TokenCacheDir), "err", err)
+}
+
+func (ac *Context) tokenCacheKeySource() tokencache.KeySource {
+	return tokencache.KeySource{
+		KeyFile:    ac.OIDC.TokenCacheKeyFile,
+		Passphrase: ac.OIDC.TokenCachePassphrase,
+	}
+}
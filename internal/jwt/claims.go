@@ -1,15 +1,72 @@
 package jwt
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 // Claims represents claims of an ID token.
 type Claims struct {
-	Subject string
-	Expiry  time.Time
-	Pretty  string // string representation for debug and logging
+	Subject           string
+	Expiry            time.Time
+	IssuedAt          time.Time // zero if not present
+	NotBefore         time.Time // zero if not present
+	Issuer            string
+	Audience          []string
+	Email             string
+	PreferredUsername string
+	ACR               string // authentication context class reference
+	Groups            []string
+	Roles             []string            // keycloak realm roles
+	ResourceAccess    map[string][]string // keycloak client roles by client id
+	Pretty            string              // string representation for debug and logging
+}
+
+// HasExpiry returns false if the token has no exp claim and never expires.
+func (c *Claims) HasExpiry() bool {
+	return c.Expiry.Unix() != 0
 }
 
 // IsExpired returns true if the token is expired.
 func (c *Claims) IsExpired() bool {
 	return c.Expiry.Before(time.Now())
 }
+
+// ExpiresWithin returns true if the token is expired or expires within d.
+func (c *Claims) ExpiresWithin(d time.Duration) bool {
+	return c.Expiry.Before(time.Now().Add(d))
+}
+
+// HasRoleClaims returns true if the token carries realm or client roles.
+func (c *Claims) HasRoleClaims() bool {
+	return len(c.Roles) > 0 || len(c.ResourceAccess) > 0
+}
+
+// HasRole returns true if role is one of the realm roles.
+func (c *Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+// HasClientRole returns true if role is one of the roles of the client.
+func (c *Claims) HasClientRole(clientID, role string) bool {
+	return slices.Contains(c.ResourceAccess[clientID], role)
+}
+
+// HasRealmOrClientRole returns true if role is one of the realm roles or one
+// of the roles of any client.
+func (c *Claims) HasRealmOrClientRole(role string) bool {
+	if c.HasRole(role) {
+		return true
+	}
+	for client := range c.ResourceAccess {
+		if c.HasClientRole(client, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAudience returns true if aud is one of the audiences.
+func (c *Claims) HasAudience(aud string) bool {
+	return slices.Contains(c.Audience, aud)
+}
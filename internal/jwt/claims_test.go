@@ -27,3 +27,32 @@ func TestClaims_IsExpired(t *testing.T) {
 		assert.False(t, got, "IsExpired() wants false but is true")
 	})
 }
+
+func TestClaims_HasExpiry(t *testing.T) {
+	assert.True(t, (&jwt.Claims{Expiry: time.Now()}).HasExpiry())
+	assert.False(t, (&jwt.Claims{Expiry: time.Unix(0, 0)}).HasExpiry())
+}
+
+func TestClaims_Helpers(t *testing.T) {
+	claims := jwt.Claims{
+		Audience: []string{"inventory-api", "account"},
+		Groups:   []string{"admins"},
+		Roles:    []string{"inventory-write"},
+		ResourceAccess: map[string][]string{
+			"inventory-api": {"cluster-admin"},
+		},
+	}
+
+	assert.True(t, claims.HasRoleClaims())
+	assert.True(t, claims.HasRole("inventory-write"))
+	assert.False(t, claims.HasRole("cluster-admin"))
+	assert.True(t, claims.HasClientRole("inventory-api", "cluster-admin"))
+	assert.False(t, claims.HasClientRole("account", "cluster-admin"))
+	assert.True(t, claims.HasRealmOrClientRole("inventory-write"))
+	assert.True(t, claims.HasRealmOrClientRole("cluster-admin"))
+	assert.False(t, claims.HasRealmOrClientRole("inventory-read"))
+	assert.True(t, claims.HasAudience("account"))
+	assert.False(t, claims.HasAudience("other"))
+
+	assert.False(t, (&jwt.Claims{}).HasRoleClaims())
+}
@@ -26,8 +26,22 @@ func DecodeWithoutVerify(s string) (*Claims, error) {
 		return nil, fmt.Errorf("could not decode the payload: %w", err)
 	}
 	var claims struct {
-		Subject   string `json:"sub,omitempty"`
-		ExpiresAt int64  `json:"exp,omitempty"`
+		Subject           string           `json:"sub,omitempty"`
+		ExpiresAt         int64            `json:"exp,omitempty"`
+		IssuedAt          int64            `json:"iat,omitempty"`
+		NotBefore         int64            `json:"nbf,omitempty"`
+		Issuer            string           `json:"iss,omitempty"`
+		Audience          jwt.ClaimStrings `json:"aud,omitempty"`
+		Email             string           `json:"email,omitempty"`
+		PreferredUsername string           `json:"preferred_username,omitempty"`
+		ACR               string           `json:"acr,omitempty"`
+		Groups            []string         `json:"groups,omitempty"`
+		RealmAccess       struct {
+			Roles []string `json:"roles,omitempty"`
+		} `json:"realm_access,omitempty"`
+		ResourceAccess map[string]struct {
+			Roles []string `json:"roles,omitempty"`
+		} `json:"resource_access,omitempty"`
 	}
 	if err := json.NewDecoder(bytes.NewReader(payload)).Decode(&claims); err != nil {
 		return nil, fmt.Errorf("could not decode the json of token: %w", err)
@@ -36,13 +50,38 @@ func DecodeWithoutVerify(s string) (*Claims, error) {
 	if err := json.Indent(&prettyJson, payload, "", "  "); err != nil {
 		return nil, fmt.Errorf("could not indent the json of token: %w", err)
 	}
+	var resourceAccess map[string][]string
+	if len(claims.ResourceAccess) > 0 {
+		resourceAccess = make(map[string][]string, len(claims.ResourceAccess))
+		for client, access := range claims.ResourceAccess {
+			resourceAccess[client] = access.Roles
+		}
+	}
 	return &Claims{
-		Subject: claims.Subject,
-		Expiry:  time.Unix(claims.ExpiresAt, 0),
-		Pretty:  prettyJson.String(),
+		Subject:           claims.Subject,
+		Expiry:            time.Unix(claims.ExpiresAt, 0),
+		IssuedAt:          unixTime(claims.IssuedAt),
+		NotBefore:         unixTime(claims.NotBefore),
+		Issuer:            claims.Issuer,
+		Audience:          claims.Audience,
+		Email:             claims.Email,
+		PreferredUsername: claims.PreferredUsername,
+		ACR:               claims.ACR,
+		Groups:            claims.Groups,
+		Roles:             claims.RealmAccess.Roles,
+		ResourceAccess:    resourceAccess,
+		Pretty:            prettyJson.String(),
 	}, nil
 }
 
+// unixTime returns the zero time for 0 so absent claims can be told apart
+func unixTime(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
 // DecodePayloadAsRawJSON extracts the payload and returns the raw JSON.
 func DecodePayloadAsRawJSON(s string) ([]byte, error) {
 	parts := strings.SplitN(s, ".", 3)
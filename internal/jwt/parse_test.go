@@ -23,6 +23,7 @@ func TestDecode(t *testing.T) {
 		want := &Claims{
 			Subject: "",
 			Expiry:  time.Unix(1300819380, 0),
+			Issuer:  "joe",
 			Pretty: `{
   "iss": "joe",
   "exp": 1300819380,
@@ -32,6 +33,35 @@ func TestDecode(t *testing.T) {
 		assert.Equal(t, want, got)
 	})
 
+	t.Run("KeycloakToken", func(t *testing.T) {
+		const (
+			header = "eyJhbGciOiJub25lIn0"
+			// {"sub":"user","exp":1300819380,"iat":1300815780,"nbf":1300815780,
+			//  "iss":"https://keycloak.example.com/realms/mcs","aud":"inventory-api",
+			//  "email":"user@example.com","preferred_username":"jdoe","acr":"gold",
+			//  "groups":["admins"],
+			//  "realm_access":{"roles":["inventory-write"]},
+			//  "resource_access":{"inventory-api":{"roles":["cluster-admin"]}}}
+			payload = "eyJzdWIiOiJ1c2VyIiwiZXhwIjoxMzAwODE5MzgwLCJpYXQiOjEzMDA4MTU3ODAsIm5iZiI6MTMwMDgxNTc4MCwiaXNzIjoiaHR0cHM6Ly9rZXljbG9hay5leGFtcGxlLmNvbS9yZWFsbXMvbWNzIiwiYXVkIjoiaW52ZW50b3J5LWFwaSIsImVtYWlsIjoidXNlckBleGFtcGxlLmNvbSIsInByZWZlcnJlZF91c2VybmFtZSI6Impkb2UiLCJhY3IiOiJnb2xkIiwiZ3JvdXBzIjpbImFkbWlucyJdLCJyZWFsbV9hY2Nlc3MiOnsicm9sZXMiOlsiaW52ZW50b3J5LXdyaXRlIl19LCJyZXNvdXJjZV9hY2Nlc3MiOnsiaW52ZW50b3J5LWFwaSI6eyJyb2xlcyI6WyJjbHVzdGVyLWFkbWluIl19fX0"
+			token   = header + "." + payload + "."
+		)
+		got, err := DecodeWithoutVerify(token)
+		if err != nil {
+			t.Fatalf("Decode error: %s", err)
+		}
+		assert.Equal(t, "user", got.Subject)
+		assert.Equal(t, "https://keycloak.example.com/realms/mcs", got.Issuer)
+		assert.Equal(t, []string{"inventory-api"}, got.Audience)
+		assert.Equal(t, "user@example.com", got.Email)
+		assert.Equal(t, "jdoe", got.PreferredUsername)
+		assert.Equal(t, "gold", got.ACR)
+		assert.Equal(t, time.Unix(1300815780, 0), got.IssuedAt)
+		assert.Equal(t, time.Unix(1300815780, 0), got.NotBefore)
+		assert.Equal(t, []string{"admins"}, got.Groups)
+		assert.Equal(t, []string{"inventory-write"}, got.Roles)
+		assert.Equal(t, map[string][]string{"inventory-api": {"cluster-admin"}}, got.ResourceAccess)
+	})
+
 	t.Run("InvalidToken", func(t *testing.T) {
 		decodedToken, err := DecodeWithoutVerify("HEADER.INVALID_TOKEN.SIGNATURE")
 		assert.Error(t, err, "wants non-nil but got nil")
@@ -0,0 +1,81 @@
+package kubeconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// ExecInfoEnv is the environment variable client-go passes the
+	// ExecCredential input to credential plugins in
+	ExecInfoEnv = "KUBERNETES_EXEC_INFO"
+
+	// ExecCredentialAPIVersion is the ExecCredential version used if client-go
+	// does not ask for a specific version
+	ExecCredentialAPIVersion        = "client.authentication.k8s.io/v1"
+	execCredentialAPIVersionV1beta1 = "client.authentication.k8s.io/v1beta1"
+	execCredentialKind              = "ExecCredential"
+)
+
+// ExecCredential is the input and output of a client-go credential plugin
+//
+// See https://kubernetes.io/docs/reference/access-authn-authz/authentication/#client-go-credential-plugins
+type ExecCredential struct {
+	Kind       string                `json:"kind"`
+	APIVersion string                `json:"apiVersion"`
+	Spec       ExecCredentialSpec    `json:"spec"`
+	Status     *ExecCredentialStatus `json:"status,omitempty"`
+}
+
+// ExecCredentialSpec holds the information client-go passes to the plugin
+type ExecCredentialSpec struct {
+	// Interactive is true if the plugin may use standard input
+	Interactive bool `json:"interactive,omitempty"`
+}
+
+// ExecCredentialStatus holds the credential returned by the plugin
+type ExecCredentialStatus struct {
+	// ExpirationTimestamp is when the token expires in RFC 3339 format.
+	// client-go runs the plugin again once it has passed
+	ExpirationTimestamp string `json:"expirationTimestamp,omitempty"`
+	Token               string `json:"token"`
+}
+
+// ParseExecInfo parses the ExecCredential client-go passes in ExecInfoEnv. An
+// interactive ExecCredential of the default version is returned if execInfo is
+// empty, e.g. when the plugin is run by hand
+func ParseExecInfo(execInfo string) (*ExecCredential, error) {
+	if execInfo == "" {
+		return &ExecCredential{
+			Kind:       execCredentialKind,
+			APIVersion: ExecCredentialAPIVersion,
+			Spec:       ExecCredentialSpec{Interactive: true},
+		}, nil
+	}
+	var in ExecCredential
+	if err := json.Unmarshal([]byte(execInfo), &in); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ExecInfoEnv, err)
+	}
+	switch in.APIVersion {
+	case ExecCredentialAPIVersion, execCredentialAPIVersionV1beta1:
+	default:
+		return nil, fmt.Errorf("unsupported ExecCredential version %q. Use %s", in.APIVersion, ExecCredentialAPIVersion)
+	}
+	return &in, nil
+}
+
+// WithToken returns the response to the ExecCredential carrying token. The
+// response has the same version as the request
+func (c *ExecCredential) WithToken(token string, expiry time.Time) *ExecCredential {
+	status := &ExecCredentialStatus{Token: token}
+	if !expiry.IsZero() {
+		status.ExpirationTimestamp = expiry.UTC().Format(time.RFC3339)
+	}
+	return &ExecCredential{
+		Kind:       execCredentialKind,
+		APIVersion: c.APIVersion,
+		Spec:       c.Spec,
+		Status:     status,
+	}
+}
@@ -0,0 +1,50 @@
+package kubeconfig
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExecInfo(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		got, err := ParseExecInfo("")
+		assert.NoError(t, err)
+		assert.Equal(t, ExecCredentialAPIVersion, got.APIVersion)
+		assert.True(t, got.Spec.Interactive)
+	})
+
+	t.Run("V1beta1", func(t *testing.T) {
+		got, err := ParseExecInfo(`{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false}}`)
+		assert.NoError(t, err)
+		assert.Equal(t, "client.authentication.k8s.io/v1beta1", got.APIVersion)
+		assert.False(t, got.Spec.Interactive)
+	})
+
+	t.Run("UnsupportedVersion", func(t *testing.T) {
+		_, err := ParseExecInfo(`{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1alpha1"}`)
+		assert.ErrorContains(t, err, "unsupported ExecCredential version")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := ParseExecInfo("{")
+		assert.ErrorContains(t, err, "invalid KUBERNETES_EXEC_INFO")
+	})
+}
+
+func TestExecCredential_WithToken(t *testing.T) {
+	in, err := ParseExecInfo(`{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1","spec":{"interactive":true}}`)
+	assert.NoError(t, err)
+	expiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+
+	b, err := json.Marshal(in.WithToken("YOUR_TOKEN", expiry))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"kind": "ExecCredential",
+		"apiVersion": "client.authentication.k8s.io/v1",
+		"spec": {"interactive": true},
+		"status": {"token": "YOUR_TOKEN", "expirationTimestamp": "2030-01-02T02:04:05Z"}
+	}`, string(b))
+}
@@ -0,0 +1,294 @@
+package kubeconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultContextNameTemplate is the default template used to name merged
+// contexts
+const DefaultContextNameTemplate = "{{.Name}}.{{.Provider}}"
+
+// Config is a kubeconfig document
+//
+// Cluster and user entries are kept as generic maps so fields unknown to ic
+// survive a merge
+type Config struct {
+	Kind           string         `json:"kind,omitempty"`
+	APIVersion     string         `json:"apiVersion,omitempty"`
+	Preferences    map[string]any `json:"preferences"`
+	Clusters       []NamedCluster `json:"clusters"`
+	Users          []NamedUser    `json:"users"`
+	Contexts       []NamedContext `json:"contexts"`
+	CurrentContext string         `json:"current-context"`
+	Extensions     []any          `json:"extensions,omitempty"`
+}
+
+// NamedCluster is a named cluster entry
+type NamedCluster struct {
+	Name    string         `json:"name"`
+	Cluster map[string]any `json:"cluster"`
+}
+
+// NamedUser is a named user entry
+type NamedUser struct {
+	Name string         `json:"name"`
+	User map[string]any `json:"user"`
+}
+
+// NamedContext is a named context entry
+type NamedContext struct {
+	Name    string  `json:"name"`
+	Context Context `json:"context"`
+}
+
+// Context binds a cluster and a user
+type Context struct {
+	Cluster    string `json:"cluster"`
+	User       string `json:"user"`
+	Namespace  string `json:"namespace,omitempty"`
+	Extensions []any  `json:"extensions,omitempty"`
+}
+
+// ContextNameData is the data given to the context name template
+type ContextNameData struct {
+	// ClusterID is the id of the cluster (name.provider)
+	ClusterID string
+	// Name is the name of the cluster
+	Name string
+	// Provider is the name of the cluster provider
+	Provider string
+	// Context is the name of the context in the fetched kubeconfig
+	Context string
+}
+
+// NewContextNameData creates template data from a cluster id
+func NewContextNameData(clusterID string) ContextNameData {
+	name, provider, _ := strings.Cut(clusterID, ".")
+	return ContextNameData{
+		ClusterID: clusterID,
+		Name:      name,
+		Provider:  provider,
+	}
+}
+
+// New returns an empty kubeconfig
+func New() *Config {
+	return &Config{
+		Kind:        "Config",
+		APIVersion:  "v1",
+		Preferences: map[string]any{},
+	}
+}
+
+// Parse parses a kubeconfig document in yaml or json format
+func Parse(data []byte) (*Config, error) {
+	c := New()
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("parsing kubeconfig: %w", err)
+	}
+	return c, nil
+}
+
+// Load reads the kubeconfig at path. A missing file yields an empty
+// kubeconfig
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading kubeconfig: %w", err)
+	}
+	return Parse(data)
+}
+
+// Bytes returns the kubeconfig as yaml
+func (c *Config) Bytes() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+// Save writes the kubeconfig to path. If backup is true an existing file is
+// copied to path.bak first
+func (c *Config) Save(path string, backup bool) error {
+	data, err := c.Bytes()
+	if err != nil {
+		return fmt.Errorf("encoding kubeconfig: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("creating kubeconfig directory: %w", err)
+	}
+	if backup {
+		old, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := os.WriteFile(BackupPath(path), old, 0o600); err != nil {
+				return fmt.Errorf("writing kubeconfig backup: %w", err)
+			}
+		case !errors.Is(err, fs.ErrNotExist):
+			return fmt.Errorf("reading kubeconfig: %w", err)
+		}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("writing kubeconfig: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck
+		return fmt.Errorf("writing kubeconfig: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing kubeconfig: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("writing kubeconfig: %w", err)
+	}
+	return nil
+}
+
+// BackupPath returns the path of the backup made by Save
+func BackupPath(path string) string {
+	return path + ".bak"
+}
+
+// DefaultPath returns the kubeconfig file to merge into. This is the first
+// file in $KUBECONFIG or ~/.kube/config
+func DefaultPath() (string, error) {
+	for _, p := range filepath.SplitList(os.Getenv("KUBECONFIG")) {
+		if p != "" {
+			return p, nil
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("finding home directory: %w", err)
+	}
+	return filepath.Join(home, ".kube", "config"), nil
+}
+
+// RenameContexts renames contexts using tmpl. Clusters and users are given the
+// name of the first context referencing them
+func (c *Config) RenameContexts(tmpl *template.Template, data ContextNameData) error {
+	clusterNames := make(map[string]string)
+	userNames := make(map[string]string)
+	seen := make(map[string]bool)
+	for i, ctx := range c.Contexts {
+		data.Context = ctx.Name
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, data); err != nil {
+			return fmt.Errorf("executing context name template: %w", err)
+		}
+		name := b.String()
+		if name == "" {
+			return fmt.Errorf("context name template gives an empty name for context %q", ctx.Name)
+		}
+		if seen[name] {
+			return fmt.Errorf("context name template gives duplicate name %q", name)
+		}
+		seen[name] = true
+		if _, ok := clusterNames[ctx.Context.Cluster]; !ok {
+			clusterNames[ctx.Context.Cluster] = name
+		}
+		if _, ok := userNames[ctx.Context.User]; !ok {
+			userNames[ctx.Context.User] = name
+		}
+		if c.CurrentContext == ctx.Name {
+			c.CurrentContext = name
+		}
+		c.Contexts[i].Name = name
+		c.Contexts[i].Context.Cluster = clusterNames[ctx.Context.Cluster]
+		c.Contexts[i].Context.User = userNames[ctx.Context.User]
+	}
+	for i, cl := range c.Clusters {
+		if name, ok := clusterNames[cl.Name]; ok {
+			c.Clusters[i].Name = name
+		}
+	}
+	for i, u := range c.Users {
+		if name, ok := userNames[u.Name]; ok {
+			c.Users[i].Name = name
+		}
+	}
+	return nil
+}
+
+// MergeResult describes the changes made by Merge
+type MergeResult struct {
+	Added     []string
+	Updated   []string
+	Unchanged []string
+}
+
+// Changed returns true if the merge changed the kubeconfig
+func (r *MergeResult) Changed() bool {
+	return len(r.Added) > 0 || len(r.Updated) > 0
+}
+
+// ConflictError is returned by Merge when entries exist with different
+// content and overwriting is not allowed
+type ConflictError struct {
+	Conflicts []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflicting entries: %s", strings.Join(e.Conflicts, ", "))
+}
+
+// Merge merges the clusters, users and contexts of src into c. Existing
+// entries with different content are only replaced if overwrite is true.
+// Nothing is changed if a conflict is found
+func (c *Config) Merge(src *Config, overwrite bool) (*MergeResult, error) {
+	r := &MergeResult{}
+	conflicts := make([]string, 0)
+	clusters, cc := mergeEntries(c.Clusters, src.Clusters, "cluster", func(e NamedCluster) string { return e.Name }, r)
+	users, uc := mergeEntries(c.Users, src.Users, "user", func(e NamedUser) string { return e.Name }, r)
+	contexts, xc := mergeEntries(c.Contexts, src.Contexts, "context", func(e NamedContext) string { return e.Name }, r)
+	conflicts = append(conflicts, cc...)
+	conflicts = append(conflicts, uc...)
+	conflicts = append(conflicts, xc...)
+	if len(conflicts) > 0 && !overwrite {
+		return nil, &ConflictError{Conflicts: conflicts}
+	}
+	c.Clusters, c.Users, c.Contexts = clusters, users, contexts
+	return r, nil
+}
+
+func mergeEntries[T any](dst, src []T, kind string, name func(T) string, r *MergeResult) ([]T, []string) {
+	merged := append(make([]T, 0, len(dst)+len(src)), dst...)
+	conflicts := make([]string, 0)
+	for _, s := range src {
+		desc := fmt.Sprintf("%s %q", kind, name(s))
+		i := indexOf(merged, name(s), name)
+		switch {
+		case i < 0:
+			merged = append(merged, s)
+			r.Added = append(r.Added, desc)
+		case reflect.DeepEqual(merged[i], s):
+			r.Unchanged = append(r.Unchanged, desc)
+		default:
+			merged[i] = s
+			r.Updated = append(r.Updated, desc)
+			conflicts = append(conflicts, desc)
+		}
+	}
+	return merged, conflicts
+}
+
+func indexOf[T any](entries []T, n string, name func(T) string) int {
+	for i, e := range entries {
+		if name(e) == n {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,146 @@
+package kubeconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fetchedKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: kubernetes
+  cluster:
+    server: https://my-cluster.example.com:6443
+contexts:
+- name: admin@kubernetes
+  context:
+    cluster: kubernetes
+    user: admin
+current-context: admin@kubernetes
+users:
+- name: admin
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: kubectl
+`
+
+const existingKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: other
+  cluster:
+    server: https://other.example.com:6443
+    some-unknown-field: keep-me
+contexts:
+- name: other
+  context:
+    cluster: other
+    user: other
+current-context: other
+users:
+- name: other
+  user:
+    token: my-token
+`
+
+func fetched(t *testing.T) *Config {
+	t.Helper()
+	c, err := Parse([]byte(fetchedKubeConfig))
+	require.NoError(t, err)
+	tmpl := template.Must(template.New("").Parse(DefaultContextNameTemplate))
+	require.NoError(t, c.RenameContexts(tmpl, NewContextNameData("my-cluster.my-provider")))
+	return c
+}
+
+func TestRenameContexts(t *testing.T) {
+	c := fetched(t)
+	assert.Equal(t, "my-cluster.my-provider", c.Contexts[0].Name)
+	assert.Equal(t, "my-cluster.my-provider", c.Contexts[0].Context.Cluster)
+	assert.Equal(t, "my-cluster.my-provider", c.Contexts[0].Context.User)
+	assert.Equal(t, "my-cluster.my-provider", c.Clusters[0].Name)
+	assert.Equal(t, "my-cluster.my-provider", c.Users[0].Name)
+	assert.Equal(t, "my-cluster.my-provider", c.CurrentContext)
+
+	t.Run("template using original context", func(t *testing.T) {
+		c, err := Parse([]byte(fetchedKubeConfig))
+		require.NoError(t, err)
+		tmpl := template.Must(template.New("").Parse("{{.Provider}}-{{.Context}}"))
+		require.NoError(t, c.RenameContexts(tmpl, NewContextNameData("my-cluster.my-provider")))
+		assert.Equal(t, "my-provider-admin@kubernetes", c.Contexts[0].Name)
+	})
+}
+
+func TestMerge(t *testing.T) {
+	t.Run("into existing", func(t *testing.T) {
+		target, err := Parse([]byte(existingKubeConfig))
+		require.NoError(t, err)
+		r, err := target.Merge(fetched(t), false)
+		require.NoError(t, err)
+		assert.Len(t, r.Added, 3)
+		assert.True(t, r.Changed())
+		assert.Len(t, target.Clusters, 2)
+		assert.Len(t, target.Users, 2)
+		assert.Len(t, target.Contexts, 2)
+		assert.Equal(t, "other", target.CurrentContext)
+		assert.Equal(t, "keep-me", target.Clusters[0].Cluster["some-unknown-field"])
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		target := fetched(t)
+		r, err := target.Merge(fetched(t), false)
+		require.NoError(t, err)
+		assert.False(t, r.Changed())
+		assert.Len(t, r.Unchanged, 3)
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		target := fetched(t)
+		target.Clusters[0].Cluster["server"] = "https://changed.example.com"
+		_, err := target.Merge(fetched(t), false)
+		var conflictErr *ConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, []string{`cluster "my-cluster.my-provider"`}, conflictErr.Conflicts)
+		assert.Equal(t, "https://changed.example.com", target.Clusters[0].Cluster["server"])
+	})
+
+	t.Run("conflict with overwrite", func(t *testing.T) {
+		target := fetched(t)
+		target.Clusters[0].Cluster["server"] = "https://changed.example.com"
+		r, err := target.Merge(fetched(t), true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{`cluster "my-cluster.my-provider"`}, r.Updated)
+		assert.Equal(t, "https://my-cluster.example.com:6443", target.Clusters[0].Cluster["server"])
+	})
+}
+
+func TestSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".kube", "config")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, []byte(existingKubeConfig), 0o600))
+
+	target, err := Load(path)
+	require.NoError(t, err)
+	_, err = target.Merge(fetched(t), false)
+	require.NoError(t, err)
+	require.NoError(t, target.Save(path, true))
+
+	backup, err := os.ReadFile(BackupPath(path))
+	require.NoError(t, err)
+	assert.Equal(t, existingKubeConfig, string(backup))
+
+	saved, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, target, saved)
+
+	t.Run("missing file", func(t *testing.T) {
+		c, err := Load(filepath.Join(t.TempDir(), "config"))
+		require.NoError(t, err)
+		assert.Equal(t, New(), c)
+	})
+}
@@ -2,10 +2,14 @@ package oidc
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
 	"time"
 
 	gooidc "github.com/coreos/go-oidc/v3/oidc"
@@ -14,11 +18,30 @@ import (
 	"github.com/int128/oauth2cli/oauth2params"
 	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
 )
 
 const (
 	logoutRetryMinWait = time.Duration(2) * time.Second
 	logoutRetryMaxWait = time.Duration(30) * time.Second
+
+	deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+	// defaultDeviceCodeInterval is the polling interval used when the provider
+	// does not specify one (RFC 8628 section 3.2)
+	defaultDeviceCodeInterval = time.Duration(5) * time.Second
+
+	// pkceMethodS256 is the only PKCE code challenge method used. The plain
+	// method does not protect the authorization code
+	pkceMethodS256 = "S256"
+)
+
+var (
+	// ErrAuthorizationPending is returned by ExchangeDeviceCode when the user
+	// has not yet completed the authorization
+	ErrAuthorizationPending = errors.New("authorization pending")
+	// ErrSlowDown is returned by ExchangeDeviceCode when the client is polling
+	// too often
+	ErrSlowDown = errors.New("slow down")
 )
 
 // Client represents an OIDC Client
@@ -33,13 +56,35 @@ type Client interface {
 	GetAuthCodeURL(ctx context.Context, in GetAuthCodeURLInput) (string, error)
 	// ExchangeAuthCode converts an authorization code into a TokenSet
 	ExchangeAuthCode(ctx context.Context, in ExchangeAuthCodeInput) (*TokenSet, error)
+	// GetTokenByClientCredentials performs the Client Credentials Grant Flow
+	// and returns a token received from the provider
+	GetTokenByClientCredentials(ctx context.Context, in GetTokenByClientCredentialsInput) (*TokenSet, error)
+	// GetDeviceAuthorization starts the Device Authorization Grant Flow and
+	// returns the codes used by the user and the client
+	GetDeviceAuthorization(ctx context.Context) (*DeviceAuthorization, error)
+	// ExchangeDeviceCode makes a single attempt at converting a device code
+	// into a TokenSet
+	ExchangeDeviceCode(ctx context.Context, in ExchangeDeviceCodeInput) (*TokenSet, error)
+	// VerifyTokenSet verifies the signature, issuer and audience of a
+	// previously obtained TokenSet
+	VerifyTokenSet(ctx context.Context, tokenSet TokenSet) error
 }
 
 type client struct {
 	provider          *gooidc.Provider
 	oauth2config      oauth2.Config
 	providerLogoutURL string
-	logger            *slog.Logger
+	// codeChallengeMethods are the PKCE methods supported by the provider. It
+	// is empty if the provider does not advertise them
+	codeChallengeMethods []string
+	// httpClient is used for all requests to the provider
+	httpClient *http.Client
+	logger     *slog.Logger
+}
+
+// withHTTPClient returns ctx carrying the HTTP client used by oauth2
+func (c *client) withHTTPClient(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, c.httpClient)
 }
 
 // Refresh creates an updated TokenSet by means of refreshing an oauth2 token
@@ -48,7 +93,7 @@ func (c *client) Refresh(ctx context.Context, refreshToken string) (*TokenSet, e
 		Expiry:       time.Now(),
 		RefreshToken: refreshToken,
 	}
-	source := c.oauth2config.TokenSource(ctx, currentToken)
+	source := c.oauth2config.TokenSource(c.withHTTPClient(ctx), currentToken)
 	token, err := source.Token()
 	if err != nil {
 		return nil, fmt.Errorf("refreshing token: %w", err)
@@ -88,7 +133,10 @@ func (c *client) Logout(idToken string) error {
 
 func (c *client) logoutWithRetries(logoutURL string) (*http.Response, error) {
 	client := retryablehttp.NewClient()
-	client.HTTPClient.Timeout = time.Duration(2) * time.Second
+	client.HTTPClient = &http.Client{
+		Transport: c.httpClient.Transport,
+		Timeout:   time.Duration(2) * time.Second,
+	}
 	client.Logger = c.logger
 	client.RetryWaitMin = logoutRetryMinWait
 	client.RetryWaitMax = logoutRetryMaxWait
@@ -111,6 +159,11 @@ type GetTokenByAuthCodeInput struct {
 	State string
 	// OIDC Nonce
 	Nonce string
+	// ACRValues are the requested authentication context class references,
+	// space separated in order of preference. Optional
+	ACRValues string
+	// Prompt is the OIDC prompt parameter, e.g. login. Optional
+	Prompt string
 }
 
 // GetTokenByAuthCode performs the Authorization Code Grant Flow and returns
@@ -119,10 +172,10 @@ type GetTokenByAuthCodeInput struct {
 // It does this by creating a local http server used for serving the RedirectURL
 // and opening a browser where the user logs in
 func (c *client) GetTokenByAuthCode(ctx context.Context, in GetTokenByAuthCodeInput, localServerReadyChan chan<- string) (*TokenSet, error) {
-	authCodeOptions := append(
-		in.PKCEParams.AuthCodeOptions(),
-		oauth2.AccessTypeOffline,
-		gooidc.Nonce(in.Nonce))
+	authCodeOptions, err := c.authCodeOptions(in.PKCEParams, in.Nonce, in.ACRValues, in.Prompt)
+	if err != nil {
+		return nil, err
+	}
 
 	cfg := oauth2cli.Config{
 		OAuth2Config:           c.oauth2config,
@@ -137,7 +190,7 @@ func (c *client) GetTokenByAuthCode(ctx context.Context, in GetTokenByAuthCodeIn
 		},
 	}
 
-	token, err := oauth2cli.GetToken(ctx, cfg)
+	token, err := oauth2cli.GetToken(c.withHTTPClient(ctx), cfg)
 	if err != nil {
 		return nil, fmt.Errorf("oauth2 error: %w", err)
 	}
@@ -156,6 +209,11 @@ type GetAuthCodeURLInput struct {
 	State string
 	// OIDC Nonce
 	Nonce string
+	// ACRValues are the requested authentication context class references,
+	// space separated in order of preference. Optional
+	ACRValues string
+	// Prompt is the OIDC prompt parameter, e.g. login. Optional
+	Prompt string
 }
 
 // GetAuthCodeURL returns a URL to OAuth 2.0 provider's consent page
@@ -163,14 +221,36 @@ func (c *client) GetAuthCodeURL(_ context.Context, in GetAuthCodeURLInput) (stri
 	cfg := c.oauth2config
 	cfg.RedirectURL = in.RedirectURI
 
-	requestOptions := append(
-		in.PKCEParams.AuthCodeOptions(),
-		oauth2.AccessTypeOffline,
-		gooidc.Nonce(in.Nonce))
+	requestOptions, err := c.authCodeOptions(in.PKCEParams, in.Nonce, in.ACRValues, in.Prompt)
+	if err != nil {
+		return "", err
+	}
 
 	return cfg.AuthCodeURL(in.State, requestOptions...), nil
 }
 
+// authCodeOptions returns the parameters of authorization requests. PKCE using
+// S256 is required
+func (c *client) authCodeOptions(pkce *oauth2params.PKCE, nonce, acrValues, prompt string) ([]oauth2.AuthCodeOption, error) {
+	if pkce == nil || pkce.CodeChallengeMethod != pkceMethodS256 {
+		return nil, fmt.Errorf("PKCE using %s is required", pkceMethodS256)
+	}
+	if len(c.codeChallengeMethods) > 0 && !slices.Contains(c.codeChallengeMethods, pkceMethodS256) {
+		return nil, fmt.Errorf("provider does not support PKCE using %s (supports %s)", pkceMethodS256, strings.Join(c.codeChallengeMethods, ", "))
+	}
+	options := append(
+		pkce.AuthCodeOptions(),
+		oauth2.AccessTypeOffline,
+		gooidc.Nonce(nonce))
+	if acrValues != "" {
+		options = append(options, oauth2.SetAuthURLParam("acr_values", acrValues))
+	}
+	if prompt != "" {
+		options = append(options, oauth2.SetAuthURLParam("prompt", prompt))
+	}
+	return options, nil
+}
+
 // ExchangeAuthCodeInput holds the input parameters for ExchangeAuthCode()
 type ExchangeAuthCodeInput struct {
 	Code string
@@ -184,10 +264,13 @@ type ExchangeAuthCodeInput struct {
 
 // ExchangeAuthCode converts an authorization code into a TokenSet
 func (c *client) ExchangeAuthCode(ctx context.Context, in ExchangeAuthCodeInput) (*TokenSet, error) {
+	if in.PKCEParams == nil {
+		return nil, fmt.Errorf("PKCE using %s is required", pkceMethodS256)
+	}
 	cfg := c.oauth2config
 	cfg.RedirectURL = in.RedirectURI
 
-	token, err := cfg.Exchange(ctx, in.Code, in.PKCEParams.TokenRequestOptions()...)
+	token, err := cfg.Exchange(c.withHTTPClient(ctx), in.Code, in.PKCEParams.TokenRequestOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("exchanging code: %w", err)
 	}
@@ -195,6 +278,167 @@ func (c *client) ExchangeAuthCode(ctx context.Context, in ExchangeAuthCodeInput)
 	return c.verifyToken(token, in.Nonce)
 }
 
+// GetTokenByClientCredentialsInput is the input given to GetTokenByClientCredentials
+type GetTokenByClientCredentialsInput struct {
+	// ClientSecret is the secret of the confidential client
+	ClientSecret string
+}
+
+// GetTokenByClientCredentials performs the Client Credentials Grant Flow and
+// returns a token received from the provider.
+//
+// The token endpoint is not required to return an ID token for this flow. If
+// one is returned it is verified like for the other flows.
+func (c *client) GetTokenByClientCredentials(ctx context.Context, in GetTokenByClientCredentialsInput) (*TokenSet, error) {
+	cfg := clientcredentials.Config{
+		ClientID:     c.oauth2config.ClientID,
+		ClientSecret: in.ClientSecret,
+		TokenURL:     c.oauth2config.Endpoint.TokenURL,
+		// refresh tokens are not used with client credentials
+		Scopes: slices.DeleteFunc(slices.Clone(c.oauth2config.Scopes), func(s string) bool {
+			return s == "offline_access"
+		}),
+		AuthStyle: c.oauth2config.Endpoint.AuthStyle,
+	}
+	token, err := cfg.Token(c.withHTTPClient(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2 error: %w", err)
+	}
+	if _, ok := token.Extra("id_token").(string); ok {
+		return c.verifyToken(token, "")
+	}
+	return &TokenSet{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+	}, nil
+}
+
+// DeviceAuthorization is the response to a device authorization request
+type DeviceAuthorization struct {
+	// DeviceCode is the code used by the client when polling for a token
+	DeviceCode string
+	// UserCode is the code the user enters at VerificationURI
+	UserCode string
+	// VerificationURI is where the user authorizes the device
+	VerificationURI string
+	// VerificationURIComplete is VerificationURI including the user code. It
+	// is optional
+	VerificationURIComplete string
+	// Expiry is when the device code expires
+	Expiry time.Time
+	// Interval is the minimum time between polling requests
+	Interval time.Duration
+}
+
+// GetDeviceAuthorization starts the Device Authorization Grant Flow and
+// returns the codes used by the user and the client
+func (c *client) GetDeviceAuthorization(ctx context.Context) (*DeviceAuthorization, error) {
+	if c.oauth2config.Endpoint.DeviceAuthURL == "" {
+		return nil, errors.New("provider does not support the device authorization grant")
+	}
+
+	response, err := c.oauth2config.DeviceAuth(c.withHTTPClient(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2 error: %w", err)
+	}
+
+	interval := time.Duration(response.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultDeviceCodeInterval
+	}
+
+	return &DeviceAuthorization{
+		DeviceCode:              response.DeviceCode,
+		UserCode:                response.UserCode,
+		VerificationURI:         response.VerificationURI,
+		VerificationURIComplete: response.VerificationURIComplete,
+		Expiry:                  response.Expiry,
+		Interval:                interval,
+	}, nil
+}
+
+// ExchangeDeviceCodeInput holds the input parameters for ExchangeDeviceCode()
+type ExchangeDeviceCodeInput struct {
+	// DeviceCode is the code returned by GetDeviceAuthorization
+	DeviceCode string
+}
+
+// TokenError is an error response from the token endpoint
+type TokenError struct {
+	Code        string
+	Description string
+}
+
+func (e *TokenError) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("oauth2 error: %s", e.Code)
+	}
+	return fmt.Sprintf("oauth2 error: %s: %s", e.Code, e.Description)
+}
+
+// deviceTokenResponse is a token endpoint response (RFC 6749 section 5)
+type deviceTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	RefreshToken     string `json:"refresh_token"`
+	IDToken          string `json:"id_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// ExchangeDeviceCode makes a single attempt at converting a device code into
+// a TokenSet.
+//
+// ErrAuthorizationPending and ErrSlowDown are returned while the user has not
+// completed the authorization. Polling is left to the caller.
+func (c *client) ExchangeDeviceCode(ctx context.Context, in ExchangeDeviceCodeInput) (*TokenSet, error) {
+	form := url.Values{
+		"grant_type":  {deviceCodeGrantType},
+		"device_code": {in.DeviceCode},
+		"client_id":   {c.oauth2config.ClientID},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.oauth2config.Endpoint.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("creating token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting token: %w", err)
+	}
+	defer res.Body.Close()
+
+	var response deviceTokenResponse
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decoding token response (status code %d): %w", res.StatusCode, err)
+	}
+
+	switch {
+	case response.Error == "authorization_pending":
+		return nil, ErrAuthorizationPending
+	case response.Error == "slow_down":
+		return nil, ErrSlowDown
+	case response.Error != "":
+		return nil, &TokenError{Code: response.Error, Description: response.ErrorDescription}
+	case res.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("token request failed with status code: %d", res.StatusCode)
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  response.AccessToken,
+		TokenType:    response.TokenType,
+		RefreshToken: response.RefreshToken,
+	}
+	if response.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
+	}
+	token = token.WithExtra(map[string]any{"id_token": response.IDToken})
+
+	return c.verifyToken(token, "")
+}
+
 func (c *client) verifyToken(token *oauth2.Token, nonce string) (*TokenSet, error) {
 	idToken, ok := token.Extra("id_token").(string)
 	if !ok {
@@ -221,3 +465,40 @@ func (c *client) verifyToken(token *oauth2.Token, nonce string) (*TokenSet, erro
 		RefreshToken: token.RefreshToken,
 	}, nil
 }
+
+// VerifyTokenSet verifies the signature, issuer and audience of a previously
+// obtained TokenSet, e.g. one read from the token cache.
+//
+// The ID token is verified against the client ID and the access token against
+// its at_hash claim. Without an ID token only the signature and issuer of the
+// access token are verified. Expiry is not checked as expired tokens are
+// refreshed rather than used.
+//
+// The keys are fetched from the jwks_uri of the provider and kept in memory
+// only. Keys stored next to the cached tokens could be replaced along with
+// them.
+func (c *client) VerifyTokenSet(ctx context.Context, tokenSet TokenSet) error {
+	if tokenSet.IDToken == "" {
+		verifier := c.provider.Verifier(&gooidc.Config{
+			SkipClientIDCheck: true,
+			SkipExpiryCheck:   true,
+		})
+		if _, err := verifier.Verify(ctx, tokenSet.AccessToken); err != nil {
+			return fmt.Errorf("verifying access token: %w", err)
+		}
+		return nil
+	}
+
+	verifier := c.provider.Verifier(&gooidc.Config{
+		ClientID:        c.oauth2config.ClientID,
+		SkipExpiryCheck: true,
+	})
+	verifiedIDToken, err := verifier.Verify(ctx, tokenSet.IDToken)
+	if err != nil {
+		return fmt.Errorf("verifying id token: %w", err)
+	}
+	if err := verifiedIDToken.VerifyAccessToken(tokenSet.AccessToken); err != nil {
+		return fmt.Errorf("verifying access token: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package oidc
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/int128/oauth2cli/oauth2params"
+	"github.com/neticdk-k8s/ic/internal/testing/issuer"
+	testingJWT "github.com/neticdk-k8s/ic/internal/testing/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_VerifyTokenSet(t *testing.T) {
+	ctx := context.TODO()
+	server := issuer.New(t, "YOUR_CLIENT_ID")
+	factory := &Factory{Logger: slog.Default()}
+	client, err := factory.New(ctx, Provider{IssuerURL: server.URL, ClientID: "YOUR_CLIENT_ID"})
+	if err != nil {
+		t.Fatalf("could not create client: %s", err)
+	}
+	accessToken := server.AccessToken()
+	idToken := server.IDToken(accessToken)
+
+	t.Run("Valid", func(t *testing.T) {
+		err := client.VerifyTokenSet(ctx, TokenSet{AccessToken: accessToken, IDToken: idToken})
+		assert.NoError(t, err)
+	})
+
+	t.Run("ValidAccessTokenOnly", func(t *testing.T) {
+		err := client.VerifyTokenSet(ctx, TokenSet{AccessToken: accessToken})
+		assert.NoError(t, err)
+	})
+
+	t.Run("ReplacedAccessToken", func(t *testing.T) {
+		err := client.VerifyTokenSet(ctx, TokenSet{AccessToken: server.AccessToken() + "x", IDToken: idToken})
+		assert.ErrorContains(t, err, "verifying access token")
+	})
+
+	t.Run("WrongIssuer", func(t *testing.T) {
+		forged := testingJWT.EncodeF(t, func(claims *testingJWT.Claims) {
+			claims.Issuer = "https://evil.example.com"
+			claims.Audience = []string{"YOUR_CLIENT_ID"}
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+		})
+		err := client.VerifyTokenSet(ctx, TokenSet{AccessToken: forged, IDToken: forged})
+		assert.ErrorContains(t, err, "verifying id token")
+	})
+
+	t.Run("WrongAudience", func(t *testing.T) {
+		forged := testingJWT.EncodeF(t, func(claims *testingJWT.Claims) {
+			claims.Issuer = server.URL
+			claims.Audience = []string{"OTHER_CLIENT_ID"}
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+		})
+		err := client.VerifyTokenSet(ctx, TokenSet{AccessToken: forged, IDToken: forged})
+		assert.ErrorContains(t, err, "verifying id token")
+	})
+
+	t.Run("BadSignature", func(t *testing.T) {
+		err := client.VerifyTokenSet(ctx, TokenSet{AccessToken: accessToken, IDToken: idToken[:len(idToken)-4] + "AAAA"})
+		assert.ErrorContains(t, err, "verifying id token")
+	})
+
+	t.Run("ExpiredIsNotChecked", func(t *testing.T) {
+		expired := testingJWT.EncodeF(t, func(claims *testingJWT.Claims) {
+			claims.Issuer = server.URL
+			claims.Audience = []string{"YOUR_CLIENT_ID"}
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		})
+		err := client.VerifyTokenSet(ctx, TokenSet{AccessToken: expired})
+		assert.NoError(t, err)
+	})
+}
+
+func TestClient_AuthCode(t *testing.T) {
+	ctx := context.TODO()
+	redirectURI := "http://localhost:8000"
+
+	newClient := func(t *testing.T, server *issuer.Server, scopes ...string) Client {
+		factory := &Factory{Logger: slog.Default()}
+		client, err := factory.New(ctx, Provider{IssuerURL: server.URL, ClientID: "YOUR_CLIENT_ID", ExtraScopes: scopes})
+		if err != nil {
+			t.Fatalf("could not create client: %s", err)
+		}
+		return client
+	}
+	newPKCE := func(t *testing.T) *oauth2params.PKCE {
+		pkce, err := oauth2params.NewPKCE()
+		if err != nil {
+			t.Fatalf("could not create PKCE parameters: %s", err)
+		}
+		return pkce
+	}
+	// authorize visits authCodeURL like a browser would and returns the code
+	// and state of the redirect
+	authorize := func(t *testing.T, authCodeURL string) (string, string) {
+		httpClient := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}}
+		resp, err := httpClient.Get(authCodeURL)
+		if err != nil {
+			t.Fatalf("authorization request failed: %s", err)
+		}
+		defer resp.Body.Close()
+		location, err := url.Parse(resp.Header.Get("Location"))
+		if err != nil || resp.StatusCode != http.StatusFound {
+			t.Fatalf("authorization request was not redirected: %d", resp.StatusCode)
+		}
+		return location.Query().Get("code"), location.Query().Get("state")
+	}
+
+	t.Run("AuthCodeURL", func(t *testing.T) {
+		server := issuer.New(t, "YOUR_CLIENT_ID")
+		client := newClient(t, server, "email", "roles")
+		pkce := newPKCE(t)
+		authCodeURL, err := client.GetAuthCodeURL(ctx, GetAuthCodeURLInput{
+			RedirectURI: redirectURI,
+			PKCEParams:  pkce,
+			State:       "YOUR_STATE",
+			Nonce:       "YOUR_NONCE",
+			ACRValues:   "gold silver",
+			Prompt:      "login",
+		})
+		assert.NoError(t, err)
+		u, err := url.Parse(authCodeURL)
+		assert.NoError(t, err)
+		query := u.Query()
+		assert.Equal(t, "S256", query.Get("code_challenge_method"))
+		assert.Equal(t, pkce.CodeChallenge, query.Get("code_challenge"))
+		assert.Equal(t, "YOUR_STATE", query.Get("state"))
+		assert.Equal(t, "YOUR_NONCE", query.Get("nonce"))
+		assert.Equal(t, "gold silver", query.Get("acr_values"))
+		assert.Equal(t, "login", query.Get("prompt"))
+		assert.Equal(t, "email roles openid", query.Get("scope"))
+	})
+
+	t.Run("PKCERequired", func(t *testing.T) {
+		server := issuer.New(t, "YOUR_CLIENT_ID")
+		client := newClient(t, server)
+		_, err := client.GetAuthCodeURL(ctx, GetAuthCodeURLInput{RedirectURI: redirectURI})
+		assert.ErrorContains(t, err, "PKCE using S256 is required")
+		_, err = client.ExchangeAuthCode(ctx, ExchangeAuthCodeInput{Code: "YOUR_CODE", RedirectURI: redirectURI})
+		assert.ErrorContains(t, err, "PKCE using S256 is required")
+	})
+
+	t.Run("S256NotSupported", func(t *testing.T) {
+		server := issuer.New(t, "YOUR_CLIENT_ID")
+		server.CodeChallengeMethods = []string{"plain"}
+		client := newClient(t, server)
+		_, err := client.GetAuthCodeURL(ctx, GetAuthCodeURLInput{RedirectURI: redirectURI, PKCEParams: newPKCE(t)})
+		assert.ErrorContains(t, err, "provider does not support PKCE using S256 (supports plain)")
+	})
+
+	t.Run("Exchange", func(t *testing.T) {
+		server := issuer.New(t, "YOUR_CLIENT_ID")
+		server.ACR = "gold"
+		client := newClient(t, server)
+		pkce := newPKCE(t)
+		authCodeURL, err := client.GetAuthCodeURL(ctx, GetAuthCodeURLInput{
+			RedirectURI: redirectURI, PKCEParams: pkce, State: "YOUR_STATE", Nonce: "YOUR_NONCE",
+		})
+		assert.NoError(t, err)
+		code, state := authorize(t, authCodeURL)
+		assert.Equal(t, "YOUR_STATE", state)
+
+		tokenSet, err := client.ExchangeAuthCode(ctx, ExchangeAuthCodeInput{
+			Code: code, PKCEParams: pkce, Nonce: "YOUR_NONCE", RedirectURI: redirectURI,
+		})
+		if assert.NoError(t, err) {
+			claims, err := tokenSet.DecodeWithoutVerify()
+			assert.NoError(t, err)
+			assert.Equal(t, "gold", claims.ACR)
+		}
+	})
+
+	t.Run("WrongCodeVerifier", func(t *testing.T) {
+		server := issuer.New(t, "YOUR_CLIENT_ID")
+		client := newClient(t, server)
+		authCodeURL, err := client.GetAuthCodeURL(ctx, GetAuthCodeURLInput{
+			RedirectURI: redirectURI, PKCEParams: newPKCE(t), Nonce: "YOUR_NONCE",
+		})
+		assert.NoError(t, err)
+		code, _ := authorize(t, authCodeURL)
+
+		_, err = client.ExchangeAuthCode(ctx, ExchangeAuthCodeInput{
+			Code: code, PKCEParams: newPKCE(t), Nonce: "YOUR_NONCE", RedirectURI: redirectURI,
+		})
+		assert.ErrorContains(t, err, "invalid_grant")
+	})
+
+	t.Run("NonceMismatch", func(t *testing.T) {
+		server := issuer.New(t, "YOUR_CLIENT_ID")
+		server.Nonce = "OTHER_NONCE"
+		client := newClient(t, server)
+		pkce := newPKCE(t)
+		authCodeURL, err := client.GetAuthCodeURL(ctx, GetAuthCodeURLInput{
+			RedirectURI: redirectURI, PKCEParams: pkce, Nonce: "YOUR_NONCE",
+		})
+		assert.NoError(t, err)
+		code, _ := authorize(t, authCodeURL)
+
+		_, err = client.ExchangeAuthCode(ctx, ExchangeAuthCodeInput{
+			Code: code, PKCEParams: pkce, Nonce: "YOUR_NONCE", RedirectURI: redirectURI,
+		})
+		assert.ErrorContains(t, err, "verifying nonce")
+	})
+
+	t.Run("StateMismatch", func(t *testing.T) {
+		server := issuer.New(t, "YOUR_CLIENT_ID")
+		server.State = "OTHER_STATE"
+		client := newClient(t, server)
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		readyChan := make(chan string, 1)
+		go func() {
+			select {
+			case u := <-readyChan:
+				// act as the browser following the redirects back to the
+				// local server
+				if resp, err := http.Get(u); err == nil {
+					resp.Body.Close()
+				}
+			case <-ctx.Done():
+			}
+		}()
+		_, err := client.GetTokenByAuthCode(ctx, GetTokenByAuthCodeInput{
+			BindAddress: "127.0.0.1:0",
+			PKCEParams:  newPKCE(t),
+			State:       "YOUR_STATE",
+			Nonce:       "YOUR_NONCE",
+		}, readyChan)
+		assert.ErrorContains(t, err, "state does not match")
+	})
+}
+
+// countingTransport counts the requests made through it
+type countingTransport struct {
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestFactory_HTTPClient(t *testing.T) {
+	ctx := context.TODO()
+	server := issuer.New(t, "YOUR_CLIENT_ID")
+	transport := &countingTransport{}
+	factory := &Factory{Logger: slog.Default(), HTTPClient: &http.Client{Transport: transport}}
+	client, err := factory.New(ctx, Provider{IssuerURL: server.URL, ClientID: "YOUR_CLIENT_ID"})
+	if err != nil {
+		t.Fatalf("could not create client: %s", err)
+	}
+	assert.Equal(t, 1, transport.requests, "discovery")
+
+	_, err = client.GetTokenByClientCredentials(ctx, GetTokenByClientCredentialsInput{ClientSecret: "YOUR_CLIENT_SECRET"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, transport.requests, "token and keys")
+}
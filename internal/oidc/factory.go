@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"slices"
 
 	gooidc "github.com/coreos/go-oidc/v3/oidc"
 	"golang.org/x/oauth2"
@@ -19,16 +21,26 @@ type FactoryClient interface {
 
 type Factory struct {
 	Logger *slog.Logger
+	// HTTPClient is used for all requests to the provider. http.DefaultClient
+	// is used if nil
+	HTTPClient *http.Client
 }
 
 // New creates a new OIDC Client
 func (f *Factory) New(ctx context.Context, p Provider) (Client, error) {
-	provider, err := gooidc.NewProvider(ctx, p.IssuerURL)
+	httpClient := f.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	provider, err := gooidc.NewProvider(gooidc.ClientContext(ctx, httpClient), p.IssuerURL)
 	if err != nil {
 		return nil, fmt.Errorf("setting up provider: %w", err)
 	}
 
-	var providerLogoutURL string
+	var (
+		providerLogoutURL    string
+		codeChallengeMethods []string
+	)
 
 	claims := make(map[string]any)
 	if err := provider.Claims(&claims); err == nil {
@@ -38,19 +50,32 @@ func (f *Factory) New(ctx context.Context, p Provider) (Client, error) {
 				providerLogoutURL = val
 			}
 		}
+		if methods, ok := claims["code_challenge_methods_supported"].([]any); ok {
+			for _, m := range methods {
+				if val, ok := m.(string); ok {
+					codeChallengeMethods = append(codeChallengeMethods, val)
+				}
+			}
+		}
 	}
 
+	scopes := slices.Clone(p.ExtraScopes)
+	if !slices.Contains(scopes, gooidc.ScopeOpenID) {
+		scopes = append(scopes, gooidc.ScopeOpenID)
+	}
 	oauth2config := oauth2.Config{
 		ClientID: p.ClientID,
 		Endpoint: provider.Endpoint(),
-		Scopes:   append(p.ExtraScopes, gooidc.ScopeOpenID),
+		Scopes:   scopes,
 	}
 
 	return &client{
-		provider,
-		oauth2config,
-		providerLogoutURL,
-		f.Logger,
+		provider:             provider,
+		oauth2config:         oauth2config,
+		providerLogoutURL:    providerLogoutURL,
+		codeChallengeMethods: codeChallengeMethods,
+		httpClient:           httpClient,
+		logger:               f.Logger,
 	}, nil
 }
 
@@ -80,6 +80,65 @@ func (_c *MockClient_ExchangeAuthCode_Call) RunAndReturn(run func(context.Contex
 	return _c
 }
 
+// ExchangeDeviceCode provides a mock function with given fields: ctx, in
+func (_m *MockClient) ExchangeDeviceCode(ctx context.Context, in ExchangeDeviceCodeInput) (*TokenSet, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExchangeDeviceCode")
+	}
+
+	var r0 *TokenSet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, ExchangeDeviceCodeInput) (*TokenSet, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ExchangeDeviceCodeInput) *TokenSet); ok {
+		r0 = rf(ctx, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*TokenSet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ExchangeDeviceCodeInput) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockClient_ExchangeDeviceCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExchangeDeviceCode'
+type MockClient_ExchangeDeviceCode_Call struct {
+	*mock.Call
+}
+
+// ExchangeDeviceCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - in ExchangeDeviceCodeInput
+func (_e *MockClient_Expecter) ExchangeDeviceCode(ctx interface{}, in interface{}) *MockClient_ExchangeDeviceCode_Call {
+	return &MockClient_ExchangeDeviceCode_Call{Call: _e.mock.On("ExchangeDeviceCode", ctx, in)}
+}
+
+func (_c *MockClient_ExchangeDeviceCode_Call) Run(run func(ctx context.Context, in ExchangeDeviceCodeInput)) *MockClient_ExchangeDeviceCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(ExchangeDeviceCodeInput))
+	})
+	return _c
+}
+
+func (_c *MockClient_ExchangeDeviceCode_Call) Return(_a0 *TokenSet, _a1 error) *MockClient_ExchangeDeviceCode_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockClient_ExchangeDeviceCode_Call) RunAndReturn(run func(context.Context, ExchangeDeviceCodeInput) (*TokenSet, error)) *MockClient_ExchangeDeviceCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetAuthCodeURL provides a mock function with given fields: ctx, in
 func (_m *MockClient) GetAuthCodeURL(ctx context.Context, in GetAuthCodeURLInput) (string, error) {
 	ret := _m.Called(ctx, in)
@@ -137,6 +196,64 @@ func (_c *MockClient_GetAuthCodeURL_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
+// GetDeviceAuthorization provides a mock function with given fields: ctx
+func (_m *MockClient) GetDeviceAuthorization(ctx context.Context) (*DeviceAuthorization, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeviceAuthorization")
+	}
+
+	var r0 *DeviceAuthorization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*DeviceAuthorization, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *DeviceAuthorization); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeviceAuthorization)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockClient_GetDeviceAuthorization_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDeviceAuthorization'
+type MockClient_GetDeviceAuthorization_Call struct {
+	*mock.Call
+}
+
+// GetDeviceAuthorization is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockClient_Expecter) GetDeviceAuthorization(ctx interface{}) *MockClient_GetDeviceAuthorization_Call {
+	return &MockClient_GetDeviceAuthorization_Call{Call: _e.mock.On("GetDeviceAuthorization", ctx)}
+}
+
+func (_c *MockClient_GetDeviceAuthorization_Call) Run(run func(ctx context.Context)) *MockClient_GetDeviceAuthorization_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockClient_GetDeviceAuthorization_Call) Return(_a0 *DeviceAuthorization, _a1 error) *MockClient_GetDeviceAuthorization_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockClient_GetDeviceAuthorization_Call) RunAndReturn(run func(context.Context) (*DeviceAuthorization, error)) *MockClient_GetDeviceAuthorization_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetTokenByAuthCode provides a mock function with given fields: ctx, in, localServerReadyChan
 func (_m *MockClient) GetTokenByAuthCode(ctx context.Context, in GetTokenByAuthCodeInput, localServerReadyChan chan<- string) (*TokenSet, error) {
 	ret := _m.Called(ctx, in, localServerReadyChan)
@@ -197,6 +314,65 @@ func (_c *MockClient_GetTokenByAuthCode_Call) RunAndReturn(run func(context.Cont
 	return _c
 }
 
+// GetTokenByClientCredentials provides a mock function with given fields: ctx, in
+func (_m *MockClient) GetTokenByClientCredentials(ctx context.Context, in GetTokenByClientCredentialsInput) (*TokenSet, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTokenByClientCredentials")
+	}
+
+	var r0 *TokenSet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, GetTokenByClientCredentialsInput) (*TokenSet, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, GetTokenByClientCredentialsInput) *TokenSet); ok {
+		r0 = rf(ctx, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*TokenSet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, GetTokenByClientCredentialsInput) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockClient_GetTokenByClientCredentials_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTokenByClientCredentials'
+type MockClient_GetTokenByClientCredentials_Call struct {
+	*mock.Call
+}
+
+// GetTokenByClientCredentials is a helper method to define mock.On call
+//   - ctx context.Context
+//   - in GetTokenByClientCredentialsInput
+func (_e *MockClient_Expecter) GetTokenByClientCredentials(ctx interface{}, in interface{}) *MockClient_GetTokenByClientCredentials_Call {
+	return &MockClient_GetTokenByClientCredentials_Call{Call: _e.mock.On("GetTokenByClientCredentials", ctx, in)}
+}
+
+func (_c *MockClient_GetTokenByClientCredentials_Call) Run(run func(ctx context.Context, in GetTokenByClientCredentialsInput)) *MockClient_GetTokenByClientCredentials_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(GetTokenByClientCredentialsInput))
+	})
+	return _c
+}
+
+func (_c *MockClient_GetTokenByClientCredentials_Call) Return(_a0 *TokenSet, _a1 error) *MockClient_GetTokenByClientCredentials_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockClient_GetTokenByClientCredentials_Call) RunAndReturn(run func(context.Context, GetTokenByClientCredentialsInput) (*TokenSet, error)) *MockClient_GetTokenByClientCredentials_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Logout provides a mock function with given fields: idToken
 func (_m *MockClient) Logout(idToken string) error {
 	ret := _m.Called(idToken)
@@ -302,6 +478,53 @@ func (_c *MockClient_Refresh_Call) RunAndReturn(run func(context.Context, string
 	return _c
 }
 
+// VerifyTokenSet provides a mock function with given fields: ctx, tokenSet
+func (_m *MockClient) VerifyTokenSet(ctx context.Context, tokenSet TokenSet) error {
+	ret := _m.Called(ctx, tokenSet)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyTokenSet")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, TokenSet) error); ok {
+		r0 = rf(ctx, tokenSet)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockClient_VerifyTokenSet_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyTokenSet'
+type MockClient_VerifyTokenSet_Call struct {
+	*mock.Call
+}
+
+// VerifyTokenSet is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tokenSet TokenSet
+func (_e *MockClient_Expecter) VerifyTokenSet(ctx interface{}, tokenSet interface{}) *MockClient_VerifyTokenSet_Call {
+	return &MockClient_VerifyTokenSet_Call{Call: _e.mock.On("VerifyTokenSet", ctx, tokenSet)}
+}
+
+func (_c *MockClient_VerifyTokenSet_Call) Run(run func(ctx context.Context, tokenSet TokenSet)) *MockClient_VerifyTokenSet_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(TokenSet))
+	})
+	return _c
+}
+
+func (_c *MockClient_VerifyTokenSet_Call) Return(_a0 error) *MockClient_VerifyTokenSet_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockClient_VerifyTokenSet_Call) RunAndReturn(run func(context.Context, TokenSet) error) *MockClient_VerifyTokenSet_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockClient creates a new instance of MockClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockClient(t interface {
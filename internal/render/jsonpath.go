@@ -0,0 +1,90 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// ParseJSONPath parses a kubectl JSONPath template, e.g.
+//
+//	{range .clusters[*]}{.name}{"\t"}{.kubernetes_version}{"\n"}{end}
+//
+// The template is evaluated by the JSONPath implementation of kubectl and
+// missing fields produce no output.
+//
+// See https://kubernetes.io/docs/reference/kubectl/jsonpath/
+func ParseJSONPath(template string) (*jsonpath.JSONPath, error) {
+	jp := jsonpath.New("output").AllowMissingKeys(true)
+	if err := jp.Parse(template); err != nil {
+		return nil, err
+	}
+	return jp, nil
+}
+
+var relaxedJSONPathRegexp = regexp.MustCompile(`^\{\.?([^{}]+)\}$|^\.?([^{}]+)$`)
+
+// parseRelaxedJSONPath parses a JSONPath expression where the leading dot and
+// surrounding braces are optional as with kubectl custom columns and sort-by,
+// e.g. kubernetes_version or {.capacity.worker.node_count}
+func parseRelaxedJSONPath(expr string) (*jsonpath.JSONPath, error) {
+	m := relaxedJSONPathRegexp.FindStringSubmatch(expr)
+	if m == nil {
+		return nil, fmt.Errorf("unexpected path %q, expected a 'name1.name2' or '.name1.name2' or '{name1.name2}' or '{.name1.name2}'", expr)
+	}
+	field := m[1]
+	if field == "" {
+		field = m[2]
+	}
+	return ParseJSONPath(fmt.Sprintf("{.%s}", field))
+}
+
+// findValues returns the values of jp in data
+func findValues(jp *jsonpath.JSONPath, data any) ([]any, error) {
+	results, err := jp.FindResults(data)
+	if err != nil {
+		return nil, err
+	}
+	values := []any{}
+	for _, result := range results {
+		for _, v := range result {
+			values = append(values, v.Interface())
+		}
+	}
+	return values, nil
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// formatValue formats v for output. Strings are printed as is and objects
+// and arrays as JSON
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(body)
+}
@@ -0,0 +1,63 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONPath(t *testing.T) {
+	data, err := DecodeJSON([]byte(`{
+		"clusters": [
+			{"name": "a", "kubernetes_version": "v1.30.2", "capacity": {"worker": {"nodes": 3, "cpu": 12000}}},
+			{"name": "b", "kubernetes_version": "v1.29.8", "capacity": {"worker": {"nodes": 10, "cpu": 40000}}, "labels": {"app.kubernetes.io/name": "b"}},
+			{"name": "c", "kubernetes_version": "v1.31.0", "links": ["/clusters/c/pods"]}
+		]
+	}`))
+	assert.NoError(t, err)
+
+	for template, want := range map[string]string{
+		"{.clusters[0].name}":                                                     "a",
+		"{.clusters[-1].name}":                                                    "c",
+		"{.clusters[*].name}":                                                     "a b c",
+		"{.clusters[0,2].name}":                                                   "a c",
+		"{.clusters[1:].name}":                                                    "b c",
+		"{.clusters[:1].name}":                                                    "a",
+		"{..nodes}":                                                               "3 10",
+		"{.clusters[0].capacity.*.cpu}":                                           "12000",
+		"{.clusters[0].capacity.worker}":                                          `{"cpu":12000,"nodes":3}`,
+		`{.clusters[1].labels.app\.kubernetes\.io/name}`:                          "b",
+		"{.clusters[0]['name','kubernetes_version']}":                             "a v1.30.2",
+		"{.clusters[?(@.name=='b')].kubernetes_version}":                          "v1.29.8",
+		"{.clusters[?(@.name!='b')].name}":                                        "a c",
+		"{.clusters[?(@.capacity.worker.nodes>3)].name}":                          "b",
+		"{.clusters[?(@.capacity.worker.nodes<=3)].name}":                         "a",
+		"{.clusters[?(@.links)].name}":                                            "c",
+		"{.clusters[?(@.links[0]=='/clusters/c/pods')].name}":                     "c",
+		"{.clusters[0].missing}":                                                  "",
+		"{range .clusters[*]}{.name}{\"\\t\"}{.kubernetes_version}{\"\\n\"}{end}": "a\tv1.30.2\nb\tv1.29.8\nc\tv1.31.0\n",
+		"names: {$.clusters[*].name}":                                             "names: a b c",
+	} {
+		jp, err := ParseJSONPath(template)
+		if !assert.NoError(t, err, template) {
+			continue
+		}
+		got := new(bytes.Buffer)
+		assert.NoError(t, jp.Execute(got, data), template)
+		assert.Equal(t, want, got.String(), template)
+	}
+
+	for _, template := range []string{
+		"{.clusters[0].name",
+		"{.clusters[0.name}",
+		"{.clusters[x]}",
+	} {
+		_, err := ParseJSONPath(template)
+		assert.Error(t, err, template)
+	}
+
+	jp, err := ParseJSONPath("{clusters}")
+	assert.NoError(t, err)
+	assert.ErrorContains(t, jp.Execute(new(bytes.Buffer), data), "unrecognized identifier clusters")
+}
@@ -0,0 +1,198 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Output formats
+const (
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatTable = "table"
+	FormatPlain = "plain"
+	// FormatName prints the names (IDs) of the rendered objects, one per line
+	FormatName = "name"
+
+	FormatCSV      = "csv"
+	FormatTSV      = "tsv"
+	FormatMarkdown = "markdown"
+
+	// FormatJSONPath is given as jsonpath=TEMPLATE
+	FormatJSONPath = "jsonpath"
+	// FormatGoTemplate is given as go-template=TEMPLATE
+	FormatGoTemplate = "go-template"
+	// FormatCustomColumns is given as custom-columns=HEADER:PATH,...
+	FormatCustomColumns = "custom-columns"
+)
+
+// ErrNamesNotSupported is returned when rendering names of output which does
+// not consist of named objects
+var ErrNamesNotSupported = errors.New("name output is not supported for this command")
+
+// Renderer renders output in one of the output formats
+type Renderer interface {
+	// Render renders the output in format
+	Render(format string) error
+}
+
+// Base implements Renderer. JSON, YAML, JSONPath, Go templates and custom
+// columns are rendered from the JSON encoding of the output. Lists set Table
+// which renders table, plain, CSV, TSV and markdown output. Other output sets
+// Text which renders table and plain text. Names are rendered by Names.
+// Renderers embed it and set Table or Text and Names
+type Base struct {
+	Writer    io.Writer
+	NoHeaders bool
+	// Columns are the names of the columns of Table to render. The default
+	// columns are rendered if empty
+	Columns []string
+	// JSON is the JSON encoding of the output. Value is encoded if nil
+	JSON []byte
+	// Value is the output. It is only used if JSON is nil
+	Value any
+	// Items are the objects of list output. Custom columns are printed with a
+	// row per item or a single row for the output if nil
+	Items any
+	// Text renders the output as table and plain text
+	Text func() error
+	// Table returns the tabular output of lists
+	Table func() *Table
+	// Names returns the names of the rendered objects. Name output is not
+	// supported if nil
+	Names func() []string
+}
+
+// Render renders the output in format
+func (b *Base) Render(format string) error {
+	if name, arg, ok := strings.Cut(format, "="); ok {
+		return b.renderTemplate(name, arg)
+	}
+	switch format {
+	case FormatJSON:
+		body, err := b.json()
+		if err != nil {
+			return err
+		}
+		return PrettyPrintJSON(body, b.Writer)
+	case FormatYAML:
+		body, err := b.json()
+		if err != nil {
+			return err
+		}
+		return PrettyPrintYAML(body, b.Writer)
+	case FormatPlain, FormatTable:
+		if b.Table == nil {
+			return b.Text()
+		}
+		t, err := b.selectTable(format)
+		if err != nil {
+			return err
+		}
+		PrintTable(t, b.Writer, b.NoHeaders)
+		return nil
+	case FormatCSV, FormatTSV, FormatMarkdown:
+		t, err := b.selectTable(format)
+		if err != nil {
+			return err
+		}
+		switch format {
+		case FormatCSV:
+			return PrintCSV(t, b.Writer, b.NoHeaders)
+		case FormatTSV:
+			return PrintTSV(t, b.Writer, b.NoHeaders)
+		}
+		PrintMarkdown(t, b.Writer)
+		return nil
+	case FormatName:
+		if b.Names == nil {
+			return ErrNamesNotSupported
+		}
+		for _, name := range b.Names() {
+			fmt.Fprintln(b.Writer, name)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+// selectTable returns the columns of Table selected by Columns
+func (b *Base) selectTable(format string) (*Table, error) {
+	if b.Table == nil {
+		return nil, fmt.Errorf("%s output is not supported for this command", format)
+	}
+	return b.Table().Select(b.Columns)
+}
+
+func (b *Base) renderTemplate(format, arg string) error {
+	switch format {
+	case FormatJSONPath:
+		jp, err := ParseJSONPath(arg)
+		if err != nil {
+			return fmt.Errorf("parsing jsonpath: %w", err)
+		}
+		data, err := b.data()
+		if err != nil {
+			return err
+		}
+		return jp.Execute(b.Writer, data)
+	case FormatGoTemplate:
+		data, err := b.data()
+		if err != nil {
+			return err
+		}
+		return PrintGoTemplate(arg, data, b.Writer)
+	case FormatCustomColumns:
+		items, err := b.items()
+		if err != nil {
+			return err
+		}
+		return PrintCustomColumns(arg, items, b.Writer, b.NoHeaders)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+// data returns the generic JSON decoding of the output
+func (b *Base) data() (any, error) {
+	body, err := b.json()
+	if err != nil {
+		return nil, err
+	}
+	return DecodeJSON(body)
+}
+
+func (b *Base) items() ([]any, error) {
+	if b.Items == nil {
+		data, err := b.data()
+		if err != nil {
+			return nil, err
+		}
+		return []any{data}, nil
+	}
+	body, err := json.Marshal(b.Items)
+	if err != nil {
+		return nil, fmt.Errorf("json encode error: %w", err)
+	}
+	data, err := DecodeJSON(body)
+	if err != nil {
+		return nil, err
+	}
+	items, _ := data.([]any)
+	return items, nil
+}
+
+func (b *Base) json() ([]byte, error) {
+	if b.JSON != nil {
+		return b.JSON, nil
+	}
+	body, err := json.Marshal(b.Value)
+	if err != nil {
+		return nil, fmt.Errorf("json encode error: %w", err)
+	}
+	return body, nil
+}
@@ -0,0 +1,109 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBase_Render(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+	items := []item{{Name: "a"}, {Name: "b"}}
+	newBase := func(w *bytes.Buffer) *Base {
+		return &Base{
+			Writer: w,
+			Value:  items,
+			Text: func() error {
+				w.WriteString("text\n")
+				return nil
+			},
+			Names: func() []string { return []string{"a", "b"} },
+		}
+	}
+
+	for format, want := range map[string]string{
+		FormatJSON:  "[\n  {\n    \"name\": \"a\"\n  },\n  {\n    \"name\": \"b\"\n  }\n]\n",
+		FormatYAML:  "- name: a\n- name: b\n",
+		FormatPlain: "text\n",
+		FormatTable: "text\n",
+		FormatName:  "a\nb\n",
+	} {
+		got := new(bytes.Buffer)
+		assert.NoError(t, newBase(got).Render(format), format)
+		assert.Equal(t, want, got.String(), format)
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		got := new(bytes.Buffer)
+		b := newBase(got)
+		b.JSON = []byte(`{"name":"c"}`)
+		assert.NoError(t, b.Render(FormatYAML))
+		assert.Equal(t, "name: c\n", got.String())
+	})
+
+	t.Run("NamesNotSupported", func(t *testing.T) {
+		b := newBase(new(bytes.Buffer))
+		b.Names = nil
+		assert.ErrorIs(t, b.Render(FormatName), ErrNamesNotSupported)
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		assert.ErrorContains(t, newBase(new(bytes.Buffer)).Render("xml"), "unknown format: xml")
+	})
+
+	t.Run("Templates", func(t *testing.T) {
+		for format, want := range map[string]string{
+			"jsonpath={range .items[*]}{.name}{\"\\n\"}{end}": "a\nb\n",
+			"go-template={{range .items}}{{.name}} {{end}}":   "a b ",
+		} {
+			got := new(bytes.Buffer)
+			b := newBase(got)
+			b.Value = map[string]any{"items": items}
+			b.Items = items
+			assert.NoError(t, b.Render(format), format)
+			assert.Equal(t, want, got.String(), format)
+		}
+	})
+
+	t.Run("CustomColumns", func(t *testing.T) {
+		got := new(bytes.Buffer)
+		b := newBase(got)
+		b.Items = items
+		assert.NoError(t, b.Render("custom-columns=NAME:.name,MISSING:.missing"))
+		assert.Equal(t, [][]string{{"NAME", "MISSING"}, {"a", "<none>"}, {"b", "<none>"}}, tableFields(got.String()))
+	})
+
+	t.Run("CustomColumnsSingleObject", func(t *testing.T) {
+		got := new(bytes.Buffer)
+		b := newBase(got)
+		b.NoHeaders = true
+		b.JSON = []byte(`{"name":"c","nodes":3}`)
+		assert.NoError(t, b.Render("custom-columns=NAME:name,NODES:{.nodes}"))
+		assert.Equal(t, [][]string{{"c", "3"}}, tableFields(got.String()))
+	})
+
+	t.Run("InvalidTemplates", func(t *testing.T) {
+		for _, format := range []string{
+			"jsonpath={.name",
+			"go-template={{.name}",
+			"custom-columns=",
+			"custom-columns=NAME",
+			"template={.name}",
+		} {
+			assert.Error(t, newBase(new(bytes.Buffer)).Render(format), format)
+		}
+	})
+}
+
+// tableFields returns the fields of each line of a table
+func tableFields(table string) [][]string {
+	rows := [][]string{}
+	for _, line := range strings.Split(strings.TrimSpace(table), "\n") {
+		rows = append(rows, strings.Fields(line))
+	}
+	return rows
+}
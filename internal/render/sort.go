@@ -0,0 +1,168 @@
+package render
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/semver"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// Field is a field of the JSON output given as a JSONPath where the leading
+// dot and braces are optional, e.g. kubernetes_version or
+// {.capacity.worker.node_count}
+type Field struct {
+	expr string
+	path *jsonpath.JSONPath
+}
+
+// ParseField parses a field
+func ParseField(expr string) (*Field, error) {
+	path, err := parseRelaxedJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &Field{expr: expr, path: path}, nil
+}
+
+// String returns the field as given
+func (f *Field) String() string {
+	return f.expr
+}
+
+// value returns the first value of the field in data or nil
+func (f *Field) value(data any) (any, error) {
+	values, err := findValues(f.path, data)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range values {
+		if v != nil {
+			return v, nil
+		}
+	}
+	return nil, nil
+}
+
+// SortBy sorts items by the value of field in their JSON encoding. Numbers
+// are ordered by value and strings in natural order where versions are
+// ordered as semantic versions, e.g. v1.9.0 < v1.10.0-rc.1 < v1.10.0. Items
+// without the field are ordered last. reverse gives descending order. An
+// error is returned if no item has the field
+func SortBy[T any](items []T, field *Field, reverse bool) error {
+	if field == nil || len(items) == 0 {
+		return nil
+	}
+	type keyed struct {
+		item T
+		key  any
+	}
+	keys := make([]keyed, 0, len(items))
+	found := false
+	for _, item := range items {
+		body, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("json encode error: %w", err)
+		}
+		data, err := DecodeJSON(body)
+		if err != nil {
+			return err
+		}
+		key, err := field.value(data)
+		if err != nil {
+			return err
+		}
+		found = found || key != nil
+		keys = append(keys, keyed{item: item, key: key})
+	}
+	if !found {
+		return fmt.Errorf("field %q not found", field)
+	}
+	slices.SortStableFunc(keys, func(a, b keyed) int {
+		switch {
+		case a.key == nil && b.key == nil:
+			return 0
+		case a.key == nil:
+			return 1
+		case b.key == nil:
+			return -1
+		}
+		c := CompareValues(a.key, b.key)
+		if reverse {
+			return -c
+		}
+		return c
+	})
+	for i, k := range keys {
+		items[i] = k.item
+	}
+	return nil
+}
+
+// CompareValues compares JSON values. Numbers are compared by value, strings
+// using CompareVersions and other values by their JSON encoding
+func CompareValues(a, b any) int {
+	af, aNum := toFloat(a)
+	bf, bNum := toFloat(b)
+	if aNum && bNum {
+		return cmp.Compare(af, bf)
+	}
+	return CompareVersions(formatValue(a), formatValue(b))
+}
+
+// CompareVersions compares semantic versions like Kubernetes versions with or
+// without the leading v. Other strings are compared in natural order where
+// runs of digits are compared by value, e.g. node-2 < node-10
+func CompareVersions(a, b string) int {
+	if va, vb := canonicalVersion(a), canonicalVersion(b); semver.IsValid(va) && semver.IsValid(vb) {
+		if c := semver.Compare(va, vb); c != 0 {
+			return c
+		}
+	}
+	return compareNatural(a, b)
+}
+
+func canonicalVersion(v string) string {
+	if v != "" && isDigit(v[0]) {
+		return "v" + v
+	}
+	return v
+}
+
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			var an, bn string
+			an, a = cutDigits(a)
+			bn, b = cutDigits(b)
+			an, bn = strings.TrimLeft(an, "0"), strings.TrimLeft(bn, "0")
+			if c := cmp.Compare(len(an), len(bn)); c != 0 {
+				return c
+			}
+			if c := strings.Compare(an, bn); c != 0 {
+				return c
+			}
+			continue
+		}
+		if c := cmp.Compare(a[0], b[0]); c != 0 {
+			return c
+		}
+		a, b = a[1:], b[1:]
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func cutDigits(s string) (string, string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
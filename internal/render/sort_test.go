@@ -0,0 +1,70 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	for _, ordered := range [][]string{
+		{"v1.9.0", "v1.10.0-rc.1", "v1.10.0", "v1.10.1"},
+		{"1.2.3", "v1.10.0"},
+		{"node-2", "node-10", "node-10a", "node-11"},
+		{"", "a", "b"},
+	} {
+		for i := 1; i < len(ordered); i++ {
+			assert.Equal(t, -1, CompareVersions(ordered[i-1], ordered[i]), ordered)
+			assert.Equal(t, 1, CompareVersions(ordered[i], ordered[i-1]), ordered)
+		}
+	}
+	assert.Equal(t, 0, CompareVersions("node-01", "node-01"))
+}
+
+func TestSortBy(t *testing.T) {
+	type node struct {
+		Name string  `json:"name"`
+		CPU  float64 `json:"cpu,omitempty"`
+	}
+	nodes := func() []node {
+		return []node{{"node-10", 4000}, {"node-2", 16000}, {"node-3", 0}, {"node-1", 8000}}
+	}
+	names := func(nodes []node) []string {
+		s := []string{}
+		for _, n := range nodes {
+			s = append(s, n.Name)
+		}
+		return s
+	}
+
+	for expr, want := range map[string][]string{
+		"name":   {"node-1", "node-2", "node-3", "node-10"},
+		"cpu":    {"node-10", "node-1", "node-2", "node-3"},
+		"{.cpu}": {"node-10", "node-1", "node-2", "node-3"},
+	} {
+		field, err := ParseField(expr)
+		if !assert.NoError(t, err, expr) {
+			continue
+		}
+		got := nodes()
+		assert.NoError(t, SortBy(got, field, false), expr)
+		assert.Equal(t, want, names(got), expr)
+	}
+
+	field, err := ParseField("cpu")
+	assert.NoError(t, err)
+	got := nodes()
+	assert.NoError(t, SortBy(got, field, true))
+	assert.Equal(t, []string{"node-2", "node-1", "node-10", "node-3"}, names(got))
+
+	got = nodes()
+	assert.NoError(t, SortBy(got, nil, false))
+	assert.Equal(t, names(nodes()), names(got))
+
+	field, err = ParseField("memory")
+	assert.NoError(t, err)
+	assert.ErrorContains(t, SortBy(nodes(), field, false), `field "memory" not found`)
+
+	_, err = ParseField("name[x")
+	assert.Error(t, err)
+}
@@ -0,0 +1,148 @@
+package render
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/neticdk-k8s/ic/internal/ui"
+)
+
+// Column is a column of tabular output
+type Column struct {
+	// Name selects the column, e.g. with --columns
+	Name string
+	// Header is the header of the column. Name is used if empty
+	Header string
+	// Optional columns are only shown when selected
+	Optional bool
+}
+
+func (c Column) header() string {
+	if c.Header == "" {
+		return c.Name
+	}
+	return c.Header
+}
+
+// Table is the tabular output of a list with a row per item and a value per
+// column in each row
+type Table struct {
+	Columns []Column
+	Rows    [][]string
+}
+
+// ColumnNames returns the names of the columns of t
+func (t *Table) ColumnNames() []string {
+	names := make([]string, 0, len(t.Columns))
+	for _, c := range t.Columns {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// Select returns a table with the columns given by name in the given order.
+// The non-optional columns are selected if names is empty
+func (t *Table) Select(names []string) (*Table, error) {
+	indexes := []int{}
+	if len(names) == 0 {
+		for i, c := range t.Columns {
+			if !c.Optional {
+				indexes = append(indexes, i)
+			}
+		}
+	}
+	for _, name := range names {
+		i := slices.IndexFunc(t.Columns, func(c Column) bool { return c.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown column %q, must be one of (%s)", name, strings.Join(t.ColumnNames(), "|"))
+		}
+		indexes = append(indexes, i)
+	}
+
+	selected := &Table{
+		Columns: make([]Column, 0, len(indexes)),
+		Rows:    make([][]string, 0, len(t.Rows)),
+	}
+	for _, i := range indexes {
+		selected.Columns = append(selected.Columns, t.Columns[i])
+	}
+	for _, row := range t.Rows {
+		r := make([]string, 0, len(indexes))
+		for _, i := range indexes {
+			r = append(r, row[i])
+		}
+		selected.Rows = append(selected.Rows, r)
+	}
+	return selected, nil
+}
+
+func (t *Table) headers() []string {
+	headers := make([]string, 0, len(t.Columns))
+	for _, c := range t.Columns {
+		headers = append(headers, c.header())
+	}
+	return headers
+}
+
+// PrintTable prints t as a text table
+func PrintTable(t *Table, writer io.Writer, noHeaders bool) {
+	var headers []string
+	if !noHeaders {
+		headers = t.headers()
+	}
+	table := ui.NewTable(writer, headers)
+	table.AppendBulk(t.Rows)
+	table.Render()
+}
+
+// PrintCSV prints t as comma separated values. Values are quoted as
+// described in RFC 4180
+func PrintCSV(t *Table, writer io.Writer, noHeaders bool) error {
+	return printDelimited(t, writer, noHeaders, ',')
+}
+
+// PrintTSV prints t as tab separated values. Values containing tabs, quotes
+// or newlines are quoted like in CSV
+func PrintTSV(t *Table, writer io.Writer, noHeaders bool) error {
+	return printDelimited(t, writer, noHeaders, '\t')
+}
+
+func printDelimited(t *Table, writer io.Writer, noHeaders bool, comma rune) error {
+	w := csv.NewWriter(writer)
+	w.Comma = comma
+	if !noHeaders {
+		if err := w.Write(t.headers()); err != nil {
+			return err
+		}
+	}
+	if err := w.WriteAll(t.Rows); err != nil {
+		return err
+	}
+	return w.Error()
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+// PrintMarkdown prints t as a GitHub flavored markdown table. The headers are
+// always printed as markdown tables require them
+func PrintMarkdown(t *Table, writer io.Writer) {
+	printRow := func(values []string) {
+		escaped := make([]string, 0, len(values))
+		for _, v := range values {
+			escaped = append(escaped, markdownEscaper.Replace(v))
+		}
+		fmt.Fprintf(writer, "| %s |\n", strings.Join(escaped, " | "))
+	}
+	printRow(t.headers())
+	separators := make([]string, len(t.Columns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	printRow(separators)
+	for _, row := range t.Rows {
+		printRow(row)
+	}
+}
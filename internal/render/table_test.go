@@ -0,0 +1,78 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTable(t *testing.T) {
+	table := &Table{
+		Columns: []Column{
+			{Name: "name"},
+			{Name: "cpu", Header: "cpu (cap)"},
+			{Name: "description", Optional: true},
+		},
+		Rows: [][]string{
+			{"a", "4000m", `quoted "a", with comma`},
+			{"b", "8000m", "pipe | and\nnewline"},
+		},
+	}
+
+	t.Run("Select", func(t *testing.T) {
+		got, err := table.Select(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"name", "cpu"}, got.ColumnNames())
+		assert.Equal(t, [][]string{{"a", "4000m"}, {"b", "8000m"}}, got.Rows)
+
+		got, err = table.Select([]string{"description", "name"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"description", "name"}, got.ColumnNames())
+		assert.Equal(t, []string{`quoted "a", with comma`, "a"}, got.Rows[0])
+
+		_, err = table.Select([]string{"memory"})
+		assert.ErrorContains(t, err, `unknown column "memory", must be one of (name|cpu|description)`)
+	})
+
+	all, err := table.Select([]string{"name", "cpu", "description"})
+	assert.NoError(t, err)
+
+	t.Run("CSV", func(t *testing.T) {
+		got := new(bytes.Buffer)
+		assert.NoError(t, PrintCSV(all, got, false))
+		assert.Equal(t, "name,cpu (cap),description\n"+
+			"a,4000m,\"quoted \"\"a\"\", with comma\"\n"+
+			"b,8000m,\"pipe | and\nnewline\"\n", got.String())
+	})
+
+	t.Run("TSV", func(t *testing.T) {
+		got := new(bytes.Buffer)
+		assert.NoError(t, PrintTSV(all, got, true))
+		assert.Equal(t, "a\t4000m\t\"quoted \"\"a\"\", with comma\"\n"+
+			"b\t8000m\t\"pipe | and\nnewline\"\n", got.String())
+	})
+
+	t.Run("Markdown", func(t *testing.T) {
+		got := new(bytes.Buffer)
+		PrintMarkdown(all, got)
+		assert.Equal(t, "| name | cpu (cap) | description |\n"+
+			"| --- | --- | --- |\n"+
+			"| a | 4000m | quoted \"a\", with comma |\n"+
+			"| b | 8000m | pipe \\| and<br>newline |\n", got.String())
+	})
+
+	t.Run("Render", func(t *testing.T) {
+		got := new(bytes.Buffer)
+		b := &Base{Writer: got, Columns: []string{"cpu"}, Table: func() *Table { return table }}
+		assert.NoError(t, b.Render(FormatCSV))
+		assert.Equal(t, "cpu (cap)\n4000m\n8000m\n", got.String())
+
+		got.Reset()
+		assert.NoError(t, b.Render(FormatTable))
+		assert.Equal(t, [][]string{{"CPU", "(CAP)"}, {"4000m"}, {"8000m"}}, tableFields(got.String()))
+
+		b = &Base{Writer: got, Text: func() error { return nil }}
+		assert.ErrorContains(t, b.Render(FormatMarkdown), "markdown output is not supported for this command")
+	})
+}
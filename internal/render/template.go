@@ -0,0 +1,131 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/neticdk-k8s/ic/internal/ui"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// DecodeJSON decodes body into generic maps and slices as used by JSONPath,
+// Go templates and custom columns. Like kubectl, integers are decoded as
+// int64 and other numbers as float64
+func DecodeJSON(body []byte) (any, error) {
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return nil, fmt.Errorf("json decode error: %w", err)
+	}
+	return convertNumbers(v), nil
+}
+
+// convertNumbers replaces the json.Number values of v by int64 or float64
+func convertNumbers(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = convertNumbers(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = convertNumbers(e)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	}
+	return v
+}
+
+// PrintGoTemplate prints data using the Go template text
+//
+// See https://pkg.go.dev/text/template
+func PrintGoTemplate(text string, data any, writer io.Writer) error {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return fmt.Errorf("parsing go-template: %w", err)
+	}
+	return tmpl.Execute(writer, data)
+}
+
+// customColumn is a column of custom-columns output
+type customColumn struct {
+	header string
+	path   *jsonpath.JSONPath
+}
+
+// parseCustomColumns parses a custom-columns spec, e.g.
+// NAME:.name,VERSION:.kubernetes_version
+func parseCustomColumns(spec string) ([]customColumn, error) {
+	columns := []customColumn{}
+	for _, part := range strings.Split(spec, ",") {
+		header, expr, ok := strings.Cut(part, ":")
+		if !ok || header == "" || expr == "" {
+			return nil, fmt.Errorf("custom column %q must be on the form HEADER:PATH", part)
+		}
+		path, err := parseRelaxedJSONPath(expr)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, customColumn{header: header, path: path})
+	}
+	return columns, nil
+}
+
+// noValue is printed in custom columns without a value
+const noValue = "<none>"
+
+// PrintCustomColumns prints a table with a row per item and the columns given
+// by spec, e.g. NAME:.name,VERSION:.kubernetes_version
+func PrintCustomColumns(spec string, items []any, writer io.Writer, noHeaders bool) error {
+	if spec == "" {
+		return errors.New("custom-columns must be given as custom-columns=HEADER:PATH,...")
+	}
+	columns, err := parseCustomColumns(spec)
+	if err != nil {
+		return err
+	}
+	var headers []string
+	if !noHeaders {
+		for _, c := range columns {
+			headers = append(headers, c.header)
+		}
+	}
+	table := ui.NewTable(writer, headers)
+	table.SetAutoFormatHeaders(false)
+	for _, item := range items {
+		row := make([]string, 0, len(columns))
+		for _, c := range columns {
+			values, err := findValues(c.path, item)
+			if err != nil {
+				return err
+			}
+			formatted := []string{}
+			for _, v := range values {
+				if v != nil {
+					formatted = append(formatted, formatValue(v))
+				}
+			}
+			if len(formatted) == 0 {
+				row = append(row, noValue)
+				continue
+			}
+			row = append(row, strings.Join(formatted, ","))
+		}
+		table.Append(row)
+	}
+	table.Render()
+	return nil
+}
@@ -0,0 +1,18 @@
+package render
+
+import (
+	"fmt"
+	"io"
+
+	"sigs.k8s.io/yaml"
+)
+
+// PrettyPrintYAML prints JSON as YAML
+func PrettyPrintYAML(body []byte, writer io.Writer) error {
+	yamlData, err := yaml.JSONToYAML(body)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(writer, string(yamlData))
+	return nil
+}
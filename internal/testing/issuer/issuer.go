@@ -0,0 +1,247 @@
+package issuer
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	testingJWT "github.com/neticdk-k8s/ic/internal/testing/jwt"
+)
+
+// Server is a fake OIDC issuer signing tokens with testingJWT.PrivateKey
+type Server struct {
+	*httptest.Server
+
+	// ClientID is used as the audience of issued tokens
+	ClientID string
+	// DeviceAuthorization is returned by the device authorization endpoint.
+	// The device authorization endpoint is not advertised if it is nil
+	DeviceAuthorization map[string]any
+	// TokenErrors are returned in order by the token endpoint before a token
+	// is issued
+	TokenErrors []string
+	// CodeChallengeMethods are advertised as the supported PKCE methods. S256
+	// is advertised if nil
+	CodeChallengeMethods []string
+	// State replaces the state returned by the authorization endpoint if set
+	State string
+	// Nonce replaces the nonce of ID tokens issued for authorization codes if
+	// set
+	Nonce string
+	// ACR is the acr claim of issued tokens
+	ACR string
+
+	t             *testing.T
+	mu            sync.Mutex
+	tokenRequests []url.Values
+	authRequests  []url.Values
+	// codes are the authorization requests by the codes issued for them
+	codes map[string]url.Values
+}
+
+// New starts a fake OIDC issuer which is closed when the test ends
+func New(t *testing.T, clientID string) *Server {
+	s := &Server{
+		ClientID: clientID,
+		t:        t,
+		codes:    map[string]url.Values{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /keys", s.keys)
+	mux.HandleFunc("GET /auth", s.auth)
+	mux.HandleFunc("POST /device", s.device)
+	mux.HandleFunc("POST /token", s.token)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// TokenRequests returns the forms posted to the token endpoint
+func (s *Server) TokenRequests() []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]url.Values{}, s.tokenRequests...)
+}
+
+// AuthRequests returns the queries of requests to the authorization endpoint
+func (s *Server) AuthRequests() []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]url.Values{}, s.authRequests...)
+}
+
+// AccessToken returns a signed access token issued by the server
+func (s *Server) AccessToken() string {
+	return testingJWT.EncodeF(s.t, s.claims)
+}
+
+// IDToken returns a signed ID token issued by the server for accessToken
+func (s *Server) IDToken(accessToken string) string {
+	return s.idToken(accessToken, "")
+}
+
+func (s *Server) idToken(accessToken, nonce string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return testingJWT.EncodeF(s.t, func(claims *testingJWT.Claims) {
+		s.claims(claims)
+		claims.AccessTokenHash = base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2])
+		claims.Nonce = nonce
+	})
+}
+
+func (s *Server) claims(claims *testingJWT.Claims) {
+	claims.Issuer = s.URL
+	claims.Subject = "YOUR_SUBJECT"
+	claims.Audience = []string{s.ClientID}
+	claims.ACR = s.ACR
+	claims.IssuedAt = jwt.NewNumericDate(time.Now())
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+}
+
+func (s *Server) discovery(w http.ResponseWriter, _ *http.Request) {
+	config := map[string]any{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/auth",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/keys",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	}
+	if s.DeviceAuthorization != nil {
+		config["device_authorization_endpoint"] = s.URL + "/device"
+	}
+	config["code_challenge_methods_supported"] = []string{"S256"}
+	if s.CodeChallengeMethods != nil {
+		config["code_challenge_methods_supported"] = s.CodeChallengeMethods
+	}
+	writeJSON(w, http.StatusOK, config)
+}
+
+func (s *Server) keys(w http.ResponseWriter, _ *http.Request) {
+	key := testingJWT.PrivateKey.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]any{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+}
+
+// auth issues an authorization code without asking the user and redirects to
+// the redirect URI. Authorization requests without PKCE are rejected
+func (s *Server) auth(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	s.mu.Lock()
+	s.authRequests = append(s.authRequests, query)
+	s.mu.Unlock()
+
+	if query.Get("client_id") != s.ClientID || query.Get("code_challenge") == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_request"})
+		return
+	}
+	code := rand.Text()
+	s.mu.Lock()
+	s.codes[code] = query
+	s.mu.Unlock()
+
+	state := query.Get("state")
+	if s.State != "" {
+		state = s.State
+	}
+	redirectURL, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_request"})
+		return
+	}
+	redirectURL.RawQuery = url.Values{"code": {code}, "state": {state}}.Encode()
+	w.Header().Set("Location", redirectURL.String())
+	w.WriteHeader(http.StatusFound)
+}
+
+// exchangeCode returns the authorization request of the code in form if the
+// PKCE code verifier matches the challenge
+func (s *Server) exchangeCode(form url.Values) (url.Values, bool) {
+	s.mu.Lock()
+	authRequest, ok := s.codes[form.Get("code")]
+	delete(s.codes, form.Get("code"))
+	s.mu.Unlock()
+	if !ok || authRequest.Get("redirect_uri") != form.Get("redirect_uri") {
+		return nil, false
+	}
+	sum := sha256.Sum256([]byte(form.Get("code_verifier")))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+	if authRequest.Get("code_challenge_method") == "plain" {
+		challenge = form.Get("code_verifier")
+	}
+	return authRequest, challenge == authRequest.Get("code_challenge")
+}
+
+func (s *Server) device(w http.ResponseWriter, r *http.Request) {
+	if s.DeviceAuthorization == nil || r.ParseForm() != nil || r.PostForm.Get("client_id") != s.ClientID {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_client"})
+		return
+	}
+	writeJSON(w, http.StatusOK, s.DeviceAuthorization)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_request"})
+		return
+	}
+
+	s.mu.Lock()
+	s.tokenRequests = append(s.tokenRequests, r.PostForm)
+	var tokenError string
+	if len(s.TokenErrors) > 0 {
+		tokenError, s.TokenErrors = s.TokenErrors[0], s.TokenErrors[1:]
+	}
+	s.mu.Unlock()
+
+	if tokenError != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": tokenError})
+		return
+	}
+
+	var nonce string
+	if r.PostForm.Get("grant_type") == "authorization_code" {
+		authRequest, ok := s.exchangeCode(r.PostForm)
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_grant"})
+			return
+		}
+		nonce = authRequest.Get("nonce")
+		if s.Nonce != "" {
+			nonce = s.Nonce
+		}
+	}
+
+	accessToken := s.AccessToken()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token":  accessToken,
+		"id_token":      s.idToken(accessToken, nonce),
+		"refresh_token": "YOUR_REFRESH_TOKEN",
+		"token_type":    "Bearer",
+		"expires_in":    3600,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -22,10 +22,20 @@ type Claims struct {
 	jwt.RegisteredClaims
 	// aud claim is either a string or an array of strings.
 	// https://tools.ietf.org/html/rfc7519#section-4.1.3
-	Audience      []string `json:"aud,omitempty"`
-	Nonce         string   `json:"nonce,omitempty"`
-	Groups        []string `json:"groups,omitempty"`
-	EmailVerified bool     `json:"email_verified,omitempty"`
+	Audience          []string          `json:"aud,omitempty"`
+	Nonce             string            `json:"nonce,omitempty"`
+	AccessTokenHash   string            `json:"at_hash,omitempty"`
+	Groups            []string          `json:"groups,omitempty"`
+	EmailVerified     bool              `json:"email_verified,omitempty"`
+	PreferredUsername string            `json:"preferred_username,omitempty"`
+	ACR               string            `json:"acr,omitempty"`
+	RealmAccess       *Access           `json:"realm_access,omitempty"`
+	ResourceAccess    map[string]Access `json:"resource_access,omitempty"`
+}
+
+// Access is the keycloak representation of roles
+type Access struct {
+	Roles []string `json:"roles"`
 }
 
 func Encode(t *testing.T, claims Claims) string {
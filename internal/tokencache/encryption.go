@@ -0,0 +1,194 @@
+package tokencache
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// saltFilename is the file in the cache directory holding the salt used
+	// for key derivation
+	saltFilename = "salt"
+	saltSize     = 16
+	keySize      = 32
+	// pbkdf2Iterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256
+	pbkdf2Iterations = 600_000
+	hkdfInfo         = "ic token cache"
+
+	encryptionVersion = 1
+)
+
+var (
+	// ErrEncrypted is returned when reading an encrypted entry from a cache
+	// without a key
+	ErrEncrypted = errors.New("token cache entry is encrypted")
+	// ErrDecrypt is returned when an encrypted entry cannot be decrypted
+	ErrDecrypt = errors.New("could not decrypt token cache entry. Wrong passphrase or key file?")
+	// ErrUntrustedPlaintext is returned when reading a plaintext entry written
+	// after encryption of the cache was enabled
+	ErrUntrustedPlaintext = errors.New("plaintext token cache entry written after encryption was enabled")
+)
+
+// KeySource is what the key encrypting the file cache is derived from. KeyFile
+// takes precedence over Passphrase
+type KeySource struct {
+	// KeyFile is a file holding key material, e.g. 32 random bytes
+	KeyFile string
+	// Passphrase is a passphrase the key is derived from
+	Passphrase string
+}
+
+// IsZero returns true if no key is given
+func (s KeySource) IsZero() bool {
+	return s.KeyFile == "" && s.Passphrase == ""
+}
+
+// deriveKey derives a 256 bit key. Key files are expected to hold high entropy
+// key material and are expanded using HKDF. Passphrases are stretched using
+// PBKDF2
+func (s KeySource) deriveKey(salt []byte) ([]byte, error) {
+	switch {
+	case s.KeyFile != "":
+		secret, err := os.ReadFile(s.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading key file: %w", err)
+		}
+		secret = bytes.TrimSpace(secret)
+		if len(secret) == 0 {
+			return nil, fmt.Errorf("key file %s is empty", s.KeyFile)
+		}
+		return hkdf.Key(sha256.New, secret, salt, hkdfInfo, keySize)
+	case s.Passphrase != "":
+		return pbkdf2.Key(sha256.New, s.Passphrase, salt, pbkdf2Iterations, keySize)
+	default:
+		return nil, errors.New("no passphrase or key file given")
+	}
+}
+
+// newAEAD returns the AES-GCM cipher keyed from source and the salt stored in
+// cacheDir
+func newAEAD(cacheDir string, source KeySource) (cipher.AEAD, error) {
+	salt, err := loadOrCreateSalt(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	key, err := source.deriveKey(salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func loadOrCreateSalt(cacheDir string) ([]byte, error) {
+	p := filepath.Join(cacheDir, saltFilename)
+	salt, err := os.ReadFile(p)
+	if err == nil {
+		if len(salt) != saltSize {
+			return nil, fmt.Errorf("invalid salt in %s", p)
+		}
+		return salt, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("could not read salt: %w", err)
+	}
+	if err := os.MkdirAll(cacheDir, newDirPermissions); err != nil {
+		return nil, fmt.Errorf("could not create directory %s: %w", cacheDir, err)
+	}
+	salt = make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(p, salt, newFilePermissions); err != nil {
+		return nil, fmt.Errorf("could not write salt: %w", err)
+	}
+	return salt, nil
+}
+
+// encryptedToken is the file format of encrypted entries. The ciphertext is
+// the JSON encoded cachedToken
+type encryptedToken struct {
+	Version    int    `json:"version"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// cacheEntry can hold both plaintext and encrypted entries
+type cacheEntry struct {
+	cachedToken
+	encryptedToken
+}
+
+func (e cacheEntry) isEncrypted() bool {
+	return e.Ciphertext != nil
+}
+
+// encodeEntry encodes the entry named name. The entry is encrypted if aead is
+// not nil. The name is authenticated along with the ciphertext so entries
+// cannot be swapped
+func encodeEntry(aead cipher.AEAD, name string, t cachedToken) ([]byte, error) {
+	plaintext, err := json.Marshal(t)
+	if err != nil {
+		return nil, fmt.Errorf("json encode error: %w", err)
+	}
+	if aead == nil {
+		return append(plaintext, '\n'), nil
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(encryptedToken{
+		Version:    encryptionVersion,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, []byte(name)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("json encode error: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// decodeEntry decodes the entry named name. It returns whether the entry was
+// encrypted. Encrypted entries can only be decoded if aead is not nil
+func decodeEntry(aead cipher.AEAD, name string, data []byte) (*cachedToken, bool, error) {
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, false, fmt.Errorf("invalid json: %w", err)
+	}
+	if !e.isEncrypted() {
+		return &e.cachedToken, false, nil
+	}
+	if aead == nil {
+		return nil, true, ErrEncrypted
+	}
+	if e.Version != encryptionVersion {
+		return nil, true, fmt.Errorf("unsupported encryption version %d", e.Version)
+	}
+	if len(e.Nonce) != aead.NonceSize() {
+		return nil, true, ErrDecrypt
+	}
+	plaintext, err := aead.Open(nil, e.Nonce, e.Ciphertext, []byte(name))
+	if err != nil {
+		return nil, true, ErrDecrypt
+	}
+	var t cachedToken
+	if err := json.Unmarshal(plaintext, &t); err != nil {
+		return nil, true, fmt.Errorf("invalid json: %w", err)
+	}
+	return &t, true, nil
+}
@@ -0,0 +1,233 @@
+package tokencache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/neticdk-k8s/ic/internal/oidc"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeKeyFile(t *testing.T, content string) string {
+	p := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+		t.Fatalf("could not write key file: %s", err)
+	}
+	return p
+}
+
+func TestEncryptedFSCache(t *testing.T) {
+	key := Key{
+		IssuerURL:   "YOUR_ISSUER",
+		ClientID:    "YOUR_CLIENT_ID",
+		ExtraScopes: []string{"openid", "email"},
+	}
+	otherKey := Key{
+		IssuerURL: "YOUR_ISSUER",
+		ClientID:  "OTHER_CLIENT_ID",
+	}
+	tokenSet := oidc.TokenSet{AccessToken: "YOUR_ACCESS_TOKEN", IDToken: "YOUR_ID_TOKEN", RefreshToken: "YOUR_REFRESH_TOKEN"}
+	keySource := KeySource{KeyFile: writeKeyFile(t, "YOUR_KEY_MATERIAL\n")}
+
+	t.Run("Passphrase", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		cache, err := NewEncryptedFSCache(cacheDir, KeySource{Passphrase: "YOUR_PASSPHRASE"})
+		assert.NoError(t, err)
+		assert.NoError(t, cache.Save(key, tokenSet))
+
+		cache, err = NewEncryptedFSCache(cacheDir, KeySource{Passphrase: "YOUR_PASSPHRASE"})
+		assert.NoError(t, err)
+		got, err := cache.Lookup(key)
+		assert.NoError(t, err)
+		assert.Equal(t, &tokenSet, got)
+	})
+
+	t.Run("KeyFile", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		cache, err := NewEncryptedFSCache(cacheDir, keySource)
+		assert.NoError(t, err)
+		assert.NoError(t, cache.Save(key, tokenSet))
+
+		filename, err := computeFilename(key)
+		assert.NoError(t, err, "could not compute the key")
+		b, err := os.ReadFile(filepath.Join(cacheDir, filename))
+		assert.NoError(t, err)
+		assert.NotContains(t, string(b), "YOUR_REFRESH_TOKEN")
+		assert.Contains(t, string(b), `"ciphertext"`)
+
+		got, err := cache.Lookup(key)
+		assert.NoError(t, err)
+		assert.Equal(t, &tokenSet, got)
+	})
+
+	t.Run("WrongKey", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		cache, err := NewEncryptedFSCache(cacheDir, keySource)
+		assert.NoError(t, err)
+		assert.NoError(t, cache.Save(key, tokenSet))
+
+		cache, err = NewEncryptedFSCache(cacheDir, KeySource{KeyFile: writeKeyFile(t, "OTHER_KEY_MATERIAL")})
+		assert.NoError(t, err)
+		_, err = cache.Lookup(key)
+		assert.ErrorIs(t, err, ErrDecrypt)
+	})
+
+	t.Run("NoKey", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		cache, err := NewEncryptedFSCache(cacheDir, keySource)
+		assert.NoError(t, err)
+		assert.NoError(t, cache.Save(key, tokenSet))
+
+		plain, err := NewFSCache(cacheDir)
+		assert.NoError(t, err)
+		_, err = plain.Lookup(key)
+		assert.ErrorIs(t, err, ErrEncrypted)
+	})
+
+	t.Run("SwappedEntry", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		cache, err := NewEncryptedFSCache(cacheDir, keySource)
+		assert.NoError(t, err)
+		assert.NoError(t, cache.Save(key, tokenSet))
+
+		filename, _ := computeFilename(key)
+		otherFilename, _ := computeFilename(otherKey)
+		assert.NoError(t, os.Rename(filepath.Join(cacheDir, filename), filepath.Join(cacheDir, otherFilename)))
+		_, err = cache.Lookup(otherKey)
+		assert.ErrorIs(t, err, ErrDecrypt)
+	})
+
+	t.Run("MigratesPlaintext", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		plain, err := NewFSCache(cacheDir)
+		assert.NoError(t, err)
+		assert.NoError(t, plain.Save(key, tokenSet))
+
+		cache, err := NewEncryptedFSCache(cacheDir, keySource)
+		assert.NoError(t, err)
+		got, err := cache.Lookup(key)
+		assert.NoError(t, err)
+		assert.Equal(t, &tokenSet, got)
+
+		entries, err := cache.Entries()
+		assert.NoError(t, err)
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, FormatEncrypted, entries[0].Format)
+		}
+	})
+
+	t.Run("RejectsPlaintextWrittenAfterEncryption", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		cache, err := NewEncryptedFSCache(cacheDir, keySource)
+		assert.NoError(t, err)
+		plain, err := NewFSCache(cacheDir)
+		assert.NoError(t, err)
+		assert.NoError(t, plain.Save(key, tokenSet))
+		filename, _ := computeFilename(key)
+		later := time.Now().Add(time.Hour)
+		assert.NoError(t, os.Chtimes(filepath.Join(cacheDir, filename), later, later))
+
+		_, err = cache.Lookup(key)
+		assert.ErrorIs(t, err, ErrUntrustedPlaintext)
+		entries, err := cache.Entries()
+		assert.NoError(t, err)
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, FormatPlaintext, entries[0].Format, "the entry is not migrated")
+		}
+	})
+
+	t.Run("EmptyKeyFile", func(t *testing.T) {
+		_, err := NewEncryptedFSCache(t.TempDir(), KeySource{KeyFile: writeKeyFile(t, "\n")})
+		assert.ErrorContains(t, err, "is empty")
+	})
+}
+
+func TestFSCache_Maintenance(t *testing.T) {
+	tokenSet := oidc.TokenSet{AccessToken: "YOUR_ACCESS_TOKEN", RefreshToken: "YOUR_REFRESH_TOKEN"}
+	oldKeySource := KeySource{KeyFile: writeKeyFile(t, "OLD_KEY_MATERIAL")}
+	newKeySource := KeySource{KeyFile: writeKeyFile(t, "NEW_KEY_MATERIAL")}
+	plainKey := Key{IssuerURL: "YOUR_ISSUER", ClientID: "PLAIN_CLIENT_ID"}
+	encryptedKey := Key{IssuerURL: "YOUR_ISSUER", ClientID: "ENCRYPTED_CLIENT_ID"}
+
+	cacheDir := t.TempDir()
+	plain, err := NewFSCache(cacheDir)
+	assert.NoError(t, err)
+	assert.NoError(t, plain.Save(plainKey, tokenSet))
+	oldCache, err := NewEncryptedFSCache(cacheDir, oldKeySource)
+	assert.NoError(t, err)
+	assert.NoError(t, oldCache.Save(encryptedKey, tokenSet))
+	assert.NoError(t, os.WriteFile(filepath.Join(cacheDir, "README"), []byte("not an entry"), 0o600))
+
+	t.Run("Entries", func(t *testing.T) {
+		entries, err := plain.Entries()
+		assert.NoError(t, err)
+		formats := map[string]int{}
+		for _, e := range entries {
+			formats[e.Format]++
+		}
+		assert.Equal(t, map[string]int{FormatPlaintext: 1, FormatEncrypted: 1}, formats)
+	})
+
+	t.Run("ReEncrypt", func(t *testing.T) {
+		_, _, err := plain.ReEncrypt(KeySource{})
+		assert.Error(t, err, "re-encrypting without a key")
+
+		newCache, err := NewEncryptedFSCache(cacheDir, newKeySource)
+		assert.NoError(t, err)
+		n, skipped, err := newCache.ReEncrypt(oldKeySource)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Empty(t, skipped)
+
+		// a second run finds the entries under the new key
+		n, skipped, err = newCache.ReEncrypt(oldKeySource)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Empty(t, skipped)
+
+		for _, key := range []Key{plainKey, encryptedKey} {
+			got, err := newCache.Lookup(key)
+			assert.NoError(t, err)
+			assert.Equal(t, &tokenSet, got)
+		}
+		_, err = oldCache.Lookup(encryptedKey)
+		assert.ErrorIs(t, err, ErrDecrypt)
+	})
+
+	t.Run("ReEncrypt skips entries", func(t *testing.T) {
+		otherKey := Key{IssuerURL: "YOUR_ISSUER", ClientID: "OTHER_CLIENT_ID"}
+		otherCache, err := NewEncryptedFSCache(cacheDir, KeySource{KeyFile: writeKeyFile(t, "OTHER_KEY_MATERIAL")})
+		assert.NoError(t, err)
+		assert.NoError(t, otherCache.Save(otherKey, tokenSet))
+		otherFilename, _ := computeFilename(otherKey)
+
+		newCache, err := NewEncryptedFSCache(cacheDir, newKeySource)
+		assert.NoError(t, err)
+		n, skipped, err := newCache.ReEncrypt(oldKeySource)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []string{otherFilename}, skipped)
+		assert.NoError(t, newCache.Remove(otherFilename))
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		n, err := plain.Purge()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		entries, err := plain.Entries()
+		assert.NoError(t, err)
+		assert.Empty(t, entries)
+		_, err = os.Stat(filepath.Join(cacheDir, "README"))
+		assert.NoError(t, err, "non-entries are kept")
+	})
+
+	t.Run("MissingDirectory", func(t *testing.T) {
+		c, err := NewFSCache(filepath.Join(cacheDir, "missing"))
+		assert.NoError(t, err)
+		entries, err := c.Entries()
+		assert.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
@@ -1,14 +1,18 @@
 package tokencache
 
 import (
+	"context"
+	"crypto/cipher"
 	"crypto/sha256"
 	"encoding/gob"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/neticdk-k8s/ic/internal/jwt"
 	"github.com/neticdk-k8s/ic/internal/oidc"
 	"github.com/pkg/errors"
 )
@@ -18,16 +22,161 @@ const (
 	newFilePermissions = 0o600
 )
 
+const (
+	// FormatPlaintext is the format of unencrypted entries
+	FormatPlaintext = "plaintext"
+	// FormatEncrypted is the format of encrypted entries
+	FormatEncrypted = "encrypted"
+	// FormatInvalid is the format of entries which cannot be parsed
+	FormatInvalid = "invalid"
+	// FormatKeyring is the format of entries stored in the keyring
+	FormatKeyring = "keyring"
+)
+
+const (
+	// StateValid is the state of entries with an unexpired access token
+	StateValid = "valid"
+	// StateRefreshable is the state of entries with an expired access token
+	// and a refresh token which is not known to be expired
+	StateRefreshable = "refreshable"
+	// StateExpired is the state of entries which cannot be used or refreshed
+	StateExpired = "expired"
+	// StateUnknown is the state of entries which cannot be read, e.g.
+	// encrypted entries when no key is given
+	StateUnknown = "unknown"
+)
+
+// EntryCache is a Cache whose entries can be listed and removed
+type EntryCache interface {
+	Cache
+	// Entries lists the entries of the cache
+	Entries() ([]Entry, error)
+	// Remove removes the entry with the given ID
+	Remove(id string) error
+	// Purge removes all entries and returns the number of entries removed
+	Purge() (int, error)
+}
+
+// FileCache is a Cache stored as files in a directory
+type FileCache interface {
+	EntryCache
+	// ReEncrypt encrypts all entries using the key of the cache. Entries
+	// encrypted with another key are decrypted using from. It returns the
+	// number of entries re-encrypted and the IDs of the entries skipped
+	ReEncrypt(from KeySource) (int, []string, error)
+}
+
+// Entry describes an entry in a cache
+type Entry struct {
+	// ID is the name of the file holding the entry. Entries in the keyring
+	// have the name the file would have
+	ID string `json:"id"`
+	// Format is one of FormatPlaintext, FormatEncrypted, FormatInvalid or
+	// FormatKeyring
+	Format  string    `json:"format"`
+	ModTime time.Time `json:"mod_time"`
+	// State is one of StateValid, StateRefreshable, StateExpired or
+	// StateUnknown
+	State string `json:"state"`
+	// Key is the key the entry is cached under. It is nil for entries which
+	// cannot be read and entries written by older versions
+	Key *Key `json:"key,omitempty"`
+	// Subject is the subject of the access token
+	Subject string `json:"subject,omitempty"`
+	// ExpiresAt is the expiry of the access token
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// HasRefreshToken is true if the entry holds a refresh token
+	HasRefreshToken bool `json:"has_refresh_token"`
+	// RefreshExpiresAt is the expiry of the refresh token if it is a JWT
+	RefreshExpiresAt *time.Time `json:"refresh_expires_at,omitempty"`
+}
+
+// newEntry describes t. Tokens are decoded without verification and secrets
+// are left out
+func newEntry(id string, t *cachedToken) Entry {
+	e := Entry{
+		ID:              id,
+		State:           StateUnknown,
+		Key:             t.Key,
+		HasRefreshToken: t.RefreshToken != "",
+	}
+	claims, err := t.tokenSet().DecodeWithoutVerify()
+	if err != nil {
+		return e
+	}
+	e.Subject = claims.Subject
+	e.ExpiresAt = &claims.Expiry
+	if refreshClaims, err := jwt.DecodeWithoutVerify(t.RefreshToken); err == nil && refreshClaims.Expiry.Unix() > 0 {
+		e.RefreshExpiresAt = &refreshClaims.Expiry
+	}
+	now := time.Now()
+	switch {
+	case claims.Expiry.After(now):
+		e.State = StateValid
+	case e.HasRefreshToken && (e.RefreshExpiresAt == nil || e.RefreshExpiresAt.After(now)):
+		e.State = StateRefreshable
+	default:
+		e.State = StateExpired
+	}
+	return e
+}
+
+// FindEntry returns the entry with the ID or unique ID prefix id
+func FindEntry(entries []Entry, id string) (*Entry, error) {
+	var found *Entry
+	for i, e := range entries {
+		if !strings.HasPrefix(e.ID, id) {
+			continue
+		}
+		if e.ID == id {
+			return &entries[i], nil
+		}
+		if found != nil {
+			return nil, fmt.Errorf("ambiguous cache entry ID %s", id)
+		}
+		found = &entries[i]
+	}
+	if found == nil {
+		return nil, fmt.Errorf("cache entry %s not found", id)
+	}
+	return found, nil
+}
+
 type fsCache struct {
 	CacheDir string
+	// aead encrypts entries. Entries are stored as plaintext if nil
+	aead cipher.AEAD
+	// encryptedSince is when encryption was enabled, i.e. when the salt was
+	// created. Only plaintext entries written before are trusted
+	encryptedSince time.Time
 }
 
 type cachedToken struct {
+	// Key is what the token set is cached under. It is stored to make entries
+	// self-describing and is missing in entries written by older versions
+	Key          *Key   `json:"key,omitempty"`
 	AccessToken  string `json:"access_token,omitempty"`
 	IDToken      string `json:"id_token,omitempty"`
 	RefreshToken string `json:"refresh_token,omitempty"`
 }
 
+func newCachedToken(key Key, tokenSet oidc.TokenSet) cachedToken {
+	return cachedToken{
+		Key:          &key,
+		AccessToken:  tokenSet.AccessToken,
+		IDToken:      tokenSet.IDToken,
+		RefreshToken: tokenSet.RefreshToken,
+	}
+}
+
+func (t cachedToken) tokenSet() *oidc.TokenSet {
+	return &oidc.TokenSet{
+		AccessToken:  t.AccessToken,
+		IDToken:      t.IDToken,
+		RefreshToken: t.RefreshToken,
+	}
+}
+
 // NewFSCache creates a new filesystem backed cache
 func NewFSCache(cacheDir string) (*fsCache, error) {
 	cache := &fsCache{
@@ -36,6 +185,28 @@ func NewFSCache(cacheDir string) (*fsCache, error) {
 	return cache, nil
 }
 
+// NewEncryptedFSCache creates a new filesystem backed cache encrypting entries
+// with AES-GCM using a key derived from keySource. Plaintext entries written
+// before encryption was enabled are encrypted when they are read. Newer
+// plaintext entries are rejected as anyone able to write to the cache
+// directory could have planted them
+func NewEncryptedFSCache(cacheDir string, keySource KeySource) (*fsCache, error) {
+	aead, err := newAEAD(cacheDir, keySource)
+	if err != nil {
+		return nil, fmt.Errorf("setting up encryption: %w", err)
+	}
+	info, err := os.Stat(filepath.Join(cacheDir, saltFilename))
+	if err != nil {
+		return nil, fmt.Errorf("could not stat salt: %w", err)
+	}
+	cache := &fsCache{
+		CacheDir:       cacheDir,
+		aead:           aead,
+		encryptedSince: info.ModTime(),
+	}
+	return cache, nil
+}
+
 // Lookup retrieves a cached token
 func (c *fsCache) Lookup(key Key) (*oidc.TokenSet, error) {
 	filename, err := computeFilename(key)
@@ -43,24 +214,40 @@ func (c *fsCache) Lookup(key Key) (*oidc.TokenSet, error) {
 		return nil, fmt.Errorf("could not compute the key: %w", err)
 	}
 	p := filepath.Join(c.CacheDir, filename)
-	f, err := os.Open(p)
+	data, err := os.ReadFile(p)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return nil, &CacheMissError{}
 		}
 		return nil, fmt.Errorf("could not open file %s: %w", p, err)
 	}
-	defer f.Close()
-	d := json.NewDecoder(f)
-	var e cachedToken
-	if err := d.Decode(&e); err != nil {
-		return nil, fmt.Errorf("invalid json file %s: %w", p, err)
+	t, encrypted, err := decodeEntry(c.aead, filename, data)
+	if err == nil && c.aead != nil && !encrypted {
+		err = c.checkPlaintext(p)
 	}
-	return &oidc.TokenSet{
-		AccessToken:  e.AccessToken,
-		IDToken:      e.IDToken,
-		RefreshToken: e.RefreshToken,
-	}, nil
+	if err != nil {
+		return nil, fmt.Errorf("invalid token cache file %s: %w", p, err)
+	}
+	tokenSet := t.tokenSet()
+	if (c.aead != nil && !encrypted) || t.Key == nil {
+		// migration is best effort as the entry is encrypted and gets its key
+		// on the next save anyway
+		_ = c.write(filename, newCachedToken(key, *tokenSet))
+	}
+	return tokenSet, nil
+}
+
+// checkPlaintext returns ErrUntrustedPlaintext if the plaintext entry at p was
+// written after encryption was enabled
+func (c *fsCache) checkPlaintext(p string) error {
+	info, err := os.Stat(p)
+	if err != nil {
+		return fmt.Errorf("could not stat file %s: %w", p, err)
+	}
+	if info.ModTime().After(c.encryptedSince) {
+		return ErrUntrustedPlaintext
+	}
+	return nil
 }
 
 // Save stores a cached token
@@ -72,23 +259,48 @@ func (c *fsCache) Save(key Key, tokenSet oidc.TokenSet) error {
 	if err != nil {
 		return fmt.Errorf("could not compute the key: %w", err)
 	}
-	p := filepath.Join(c.CacheDir, filename)
-	f, err := os.OpenFile(p, os.O_RDWR|os.O_CREATE|os.O_TRUNC, newFilePermissions)
+	return c.write(filename, newCachedToken(key, tokenSet))
+}
+
+// write replaces the entry atomically by writing a temporary file and renaming
+// it so readers never see a partially written entry
+func (c *fsCache) write(filename string, t cachedToken) error {
+	data, err := encodeEntry(c.aead, filename, t)
 	if err != nil {
-		return fmt.Errorf("could not create file %s: %w", p, err)
+		return err
 	}
-	defer f.Close()
-	e := cachedToken{
-		AccessToken:  tokenSet.AccessToken,
-		IDToken:      tokenSet.IDToken,
-		RefreshToken: tokenSet.RefreshToken,
+	f, err := os.CreateTemp(c.CacheDir, filename+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("could not create temporary file in %s: %w", c.CacheDir, err)
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("could not write file %s: %w", f.Name(), err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("could not sync file %s: %w", f.Name(), err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("could not close file %s: %w", f.Name(), err)
 	}
-	if err := json.NewEncoder(f).Encode(&e); err != nil {
-		return fmt.Errorf("json encode error: %w", err)
+	p := filepath.Join(c.CacheDir, filename)
+	if err := os.Rename(f.Name(), p); err != nil {
+		return fmt.Errorf("could not rename %s to %s: %w", f.Name(), p, err)
 	}
 	return nil
 }
 
+// Lock locks the entry of key across processes
+func (c *fsCache) Lock(ctx context.Context, key Key) (func(), error) {
+	filename, err := computeFilename(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not compute the key: %w", err)
+	}
+	return lockFile(ctx, c.CacheDir, filename)
+}
+
 // Invalidate deletes a cached token
 func (c *fsCache) Invalidate(key Key) error {
 	filename, err := computeFilename(key)
@@ -106,12 +318,162 @@ func (c *fsCache) Invalidate(key Key) error {
 	return nil
 }
 
+// computeFilename returns the name of the file holding the tokens of key. It
+// is the SHA-256 hash of the gob encoding of the key. Keys without a profile
+// are encoded as before profiles were added so existing files are still found
 func computeFilename(key Key) (string, error) {
+	// Key as it was before profiles were added. The gob encoding includes
+	// the name of the type so it must be named Key as well
+	type Key struct {
+		IssuerURL   string
+		ClientID    string
+		ExtraScopes []string
+	}
+	var v any = &key
+	if key.Profile == "" {
+		v = &Key{
+			IssuerURL:   key.IssuerURL,
+			ClientID:    key.ClientID,
+			ExtraScopes: key.ExtraScopes,
+		}
+	}
 	s := sha256.New()
 	e := gob.NewEncoder(s)
-	if err := e.Encode(&key); err != nil {
+	if err := e.Encode(v); err != nil {
 		return "", fmt.Errorf("could not encode the key: %w", err)
 	}
 	h := hex.EncodeToString(s.Sum(nil))
 	return h, nil
 }
+
+// Entries lists the entries of the cache
+func (c *fsCache) Entries() ([]Entry, error) {
+	dirEntries, err := os.ReadDir(c.CacheDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not read directory %s: %w", c.CacheDir, err)
+	}
+	var entries []Entry
+	for _, d := range dirEntries {
+		if !d.Type().IsRegular() || !isEntryName(d.Name()) {
+			continue
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil, fmt.Errorf("could not stat %s: %w", d.Name(), err)
+		}
+		entries = append(entries, c.readEntry(d.Name(), info.ModTime()))
+	}
+	return entries, nil
+}
+
+// readEntry describes the entry with the given ID. Entries which cannot be
+// decoded are described as well
+func (c *fsCache) readEntry(id string, modTime time.Time) Entry {
+	entry := Entry{ID: id, State: StateUnknown}
+	data, err := os.ReadFile(filepath.Join(c.CacheDir, id))
+	if err != nil {
+		entry.Format = FormatInvalid
+	} else if t, encrypted, err := decodeEntry(c.aead, id, data); err == nil {
+		entry = newEntry(id, t)
+		entry.Format = FormatPlaintext
+		if encrypted {
+			entry.Format = FormatEncrypted
+		}
+	} else if encrypted {
+		entry.Format = FormatEncrypted
+	} else {
+		entry.Format = FormatInvalid
+	}
+	entry.ModTime = modTime
+	return entry
+}
+
+// Remove removes the entry with the given ID
+func (c *fsCache) Remove(id string) error {
+	if !isEntryName(id) {
+		return fmt.Errorf("invalid cache entry ID %s", id)
+	}
+	p := filepath.Join(c.CacheDir, id)
+	if err := os.Remove(p); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &CacheMissError{}
+		}
+		return fmt.Errorf("could not remove file %s: %w", p, err)
+	}
+	return nil
+}
+
+// Purge removes all entries and returns the number of entries removed
+func (c *fsCache) Purge() (int, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return 0, err
+	}
+	for i, e := range entries {
+		p := filepath.Join(c.CacheDir, e.ID)
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return i, fmt.Errorf("could not remove file %s: %w", p, err)
+		}
+	}
+	return len(entries), nil
+}
+
+// ReEncrypt encrypts all entries using the key of the cache. Entries encrypted
+// with another key are decrypted using from. Entries already encrypted with
+// the key of the cache, e.g. by an interrupted earlier run, are re-encrypted
+// as well. Entries which cannot be decrypted or decoded and plaintext entries
+// written after encryption was enabled are skipped. It returns the number of
+// entries re-encrypted and the IDs of the entries skipped
+func (c *fsCache) ReEncrypt(from KeySource) (int, []string, error) {
+	if c.aead == nil {
+		return 0, nil, errors.New("no passphrase or key file given")
+	}
+	fromAEAD := c.aead
+	if !from.IsZero() {
+		var err error
+		if fromAEAD, err = newAEAD(c.CacheDir, from); err != nil {
+			return 0, nil, fmt.Errorf("setting up decryption: %w", err)
+		}
+	}
+	entries, err := c.Entries()
+	if err != nil {
+		return 0, nil, err
+	}
+	n := 0
+	var skipped []string
+	for _, e := range entries {
+		p := filepath.Join(c.CacheDir, e.ID)
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return n, skipped, fmt.Errorf("could not read file %s: %w", p, err)
+		}
+		t, encrypted, err := decodeEntry(fromAEAD, e.ID, data)
+		if err != nil && encrypted && fromAEAD != c.aead {
+			t, _, err = decodeEntry(c.aead, e.ID, data)
+		}
+		if err == nil && !encrypted {
+			err = c.checkPlaintext(p)
+		}
+		if err != nil {
+			skipped = append(skipped, e.ID)
+			continue
+		}
+		if err := c.write(e.ID, *t); err != nil {
+			return n, skipped, err
+		}
+		n++
+	}
+	return n, skipped, nil
+}
+
+// isEntryName returns true if name is a name returned by computeFilename
+func isEntryName(name string) bool {
+	if len(name) != hex.EncodedLen(sha256.Size) {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil
+}
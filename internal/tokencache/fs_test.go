@@ -3,9 +3,13 @@ package tokencache
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/neticdk-k8s/ic/internal/oidc"
+	testingJWT "github.com/neticdk-k8s/ic/internal/testing/jwt"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,6 +50,12 @@ func TestFSCache_Lookup(t *testing.T) {
 
 		want := &oidc.TokenSet{AccessToken: "YOUR_ACCESS_TOKEN", IDToken: "YOUR_ID_TOKEN", RefreshToken: "YOUR_REFRESH_TOKEN"}
 		assert.Equal(t, want, got)
+
+		entries, err := fsCache.Entries()
+		assert.NoError(t, err)
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, &key, entries[0].Key, "the key is added to entries without one")
+		}
 	})
 }
 
@@ -74,9 +84,44 @@ func TestFSCache_Save(t *testing.T) {
 			t.Fatalf("could not read the token cache file: %s", err)
 		}
 
-		want := "{\"access_token\":\"YOUR_ACCESS_TOKEN\",\"id_token\":\"YOUR_ID_TOKEN\",\"refresh_token\":\"YOUR_REFRESH_TOKEN\"}\n"
+		want := "{\"key\":{\"issuer_url\":\"YOUR_ISSUER\",\"client_id\":\"YOUR_CLIENT_ID\",\"extra_scopes\":[\"openid\",\"email\"]},\"access_token\":\"YOUR_ACCESS_TOKEN\",\"id_token\":\"YOUR_ID_TOKEN\",\"refresh_token\":\"YOUR_REFRESH_TOKEN\"}\n"
 		got := string(b)
 		assert.Equal(t, want, got)
+
+		info, err := os.Stat(p)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		files, err := os.ReadDir(cacheDir)
+		assert.NoError(t, err)
+		assert.Len(t, files, 1, "temporary files are removed")
+	})
+
+	t.Run("ConcurrentReads", func(t *testing.T) {
+		cacheDir := t.TempDir()
+
+		fsCache, err := NewFSCache(cacheDir)
+		assert.NoError(t, err, "could not create new fsCache")
+
+		key := Key{IssuerURL: "YOUR_ISSUER", ClientID: "YOUR_CLIENT_ID"}
+		tokenSet := oidc.TokenSet{AccessToken: "YOUR_ACCESS_TOKEN", RefreshToken: strings.Repeat("x", 64*1024)}
+		assert.NoError(t, fsCache.Save(key, tokenSet))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for range 50 {
+				assert.NoError(t, fsCache.Save(key, tokenSet))
+			}
+		}()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			_, err := fsCache.Lookup(key)
+			assert.NoError(t, err, "a reader saw a partially written entry")
+		}
 	})
 }
 
@@ -122,3 +167,87 @@ func TestFSCache_Invalidate(t *testing.T) {
 		assert.ErrorIs(t, err, &CacheMissError{})
 	})
 }
+
+func TestComputeFilename(t *testing.T) {
+	key := Key{IssuerURL: "https://issuer.example.com", ClientID: "YOUR_CLIENT_ID", ExtraScopes: []string{"email"}}
+
+	// the name used before profiles were added
+	got, err := computeFilename(key)
+	assert.NoError(t, err)
+	assert.Equal(t, "f8f5c019462d2fad5285ec2a5629fb6ce8cc233b9e157885cb1e850900586254", got)
+
+	key.Profile = "staging"
+	withProfile, err := computeFilename(key)
+	assert.NoError(t, err)
+	assert.NotEqual(t, got, withProfile)
+	assert.True(t, isEntryName(withProfile))
+}
+
+func TestFSCache_Entries(t *testing.T) {
+	now := time.Now()
+	encode := func(sub string, exp time.Time) string {
+		return testingJWT.EncodeF(t, func(claims *testingJWT.Claims) {
+			claims.Subject = sub
+			claims.ExpiresAt = jwt.NewNumericDate(exp)
+		})
+	}
+	tokenSets := map[string]oidc.TokenSet{
+		StateValid: {
+			AccessToken:  encode("YOUR_SUBJECT", now.Add(time.Hour)),
+			RefreshToken: "YOUR_REFRESH_TOKEN",
+		},
+		StateRefreshable: {
+			AccessToken:  encode("YOUR_SUBJECT", now.Add(-time.Hour)),
+			RefreshToken: encode("YOUR_SUBJECT", now.Add(time.Hour)),
+		},
+		StateExpired: {
+			AccessToken:  encode("YOUR_SUBJECT", now.Add(-time.Hour)),
+			RefreshToken: encode("YOUR_SUBJECT", now.Add(-time.Minute)),
+		},
+		StateUnknown: {AccessToken: "YOUR_ACCESS_TOKEN"},
+	}
+
+	cacheDir := t.TempDir()
+	cache, err := NewFSCache(cacheDir)
+	assert.NoError(t, err)
+	for state, tokenSet := range tokenSets {
+		assert.NoError(t, cache.Save(Key{IssuerURL: "YOUR_ISSUER", ClientID: state}, tokenSet))
+	}
+
+	entries, err := cache.Entries()
+	assert.NoError(t, err)
+	assert.Len(t, entries, len(tokenSets))
+	for _, e := range entries {
+		if assert.NotNil(t, e.Key) {
+			assert.Equal(t, e.Key.ClientID, e.State)
+		}
+		assert.Equal(t, FormatPlaintext, e.Format)
+		assert.True(t, e.HasRefreshToken || e.State == StateUnknown)
+		if e.State != StateUnknown {
+			assert.Equal(t, "YOUR_SUBJECT", e.Subject)
+			assert.NotNil(t, e.ExpiresAt)
+		}
+	}
+
+	t.Run("FindEntry", func(t *testing.T) {
+		e, err := FindEntry(entries, entries[0].ID[:12])
+		assert.NoError(t, err)
+		assert.Equal(t, entries[0].ID, e.ID)
+
+		_, err = FindEntry(entries, "")
+		assert.ErrorContains(t, err, "ambiguous")
+
+		_, err = FindEntry(entries, "unknown")
+		assert.ErrorContains(t, err, "not found")
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		assert.NoError(t, cache.Remove(entries[0].ID))
+		assert.ErrorIs(t, cache.Remove(entries[0].ID), &CacheMissError{})
+		assert.ErrorContains(t, cache.Remove("../salt"), "invalid cache entry ID")
+
+		got, err := cache.Entries()
+		assert.NoError(t, err)
+		assert.Len(t, got, len(tokenSets)-1)
+	})
+}
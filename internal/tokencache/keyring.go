@@ -0,0 +1,189 @@
+package tokencache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/neticdk-k8s/ic/internal/oidc"
+	"github.com/pkg/errors"
+)
+
+const keyringService = "ic"
+
+// ErrKeyringUnavailable is returned by NewKeyringCache when no keyring can be
+// reached
+var ErrKeyringUnavailable = errors.New("keyring not available")
+
+type keyringCache struct {
+	ss secretService
+	// lockDir holds the lock files used by Lock
+	lockDir string
+}
+
+// NewKeyringCache creates a new cache storing token sets in the Secret Service
+// keyring (e.g. GNOME Keyring or KWallet). The keyring is accessed over the
+// D-Bus session bus and items are looked up by their attributes.
+//
+// ErrKeyringUnavailable is returned if no keyring is running, e.g. on headless
+// hosts without a D-Bus session.
+//
+// Lock files are kept in lockDir
+func NewKeyringCache(lockDir string) (*keyringCache, error) {
+	ss, err := newDBusSecretService()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKeyringUnavailable, err)
+	}
+	return &keyringCache{ss: ss, lockDir: lockDir}, nil
+}
+
+// Lookup retrieves a cached token
+func (c *keyringCache) Lookup(key Key) (*oidc.TokenSet, error) {
+	attributes, err := keyringAttributes(key)
+	if err != nil {
+		return nil, err
+	}
+	items, err := c.ss.SearchItems(attributes)
+	if err != nil {
+		return nil, keyringError("looking up token", err)
+	}
+	if len(items) == 0 {
+		return nil, &CacheMissError{}
+	}
+	secret, err := c.ss.Secret(items[0])
+	if err != nil {
+		return nil, keyringError("looking up token", err)
+	}
+	var e cachedToken
+	if err := json.Unmarshal(secret, &e); err != nil {
+		return nil, fmt.Errorf("invalid json in keyring: %w", err)
+	}
+	return e.tokenSet(), nil
+}
+
+// Save stores a cached token
+func (c *keyringCache) Save(key Key, tokenSet oidc.TokenSet) error {
+	attributes, err := keyringAttributes(key)
+	if err != nil {
+		return err
+	}
+	secret, err := json.Marshal(newCachedToken(key, tokenSet))
+	if err != nil {
+		return fmt.Errorf("json encode error: %w", err)
+	}
+	label := fmt.Sprintf("ic token for %s (%s)", key.IssuerURL, key.ClientID)
+	if key.Profile != "" {
+		label = fmt.Sprintf("ic token for %s (%s, profile %s)", key.IssuerURL, key.ClientID, key.Profile)
+	}
+	if err := c.ss.CreateItem(label, attributes, secret); err != nil {
+		return keyringError("storing token", err)
+	}
+	return nil
+}
+
+// Invalidate deletes a cached token
+func (c *keyringCache) Invalidate(key Key) error {
+	attributes, err := keyringAttributes(key)
+	if err != nil {
+		return err
+	}
+	return c.remove(attributes)
+}
+
+// Lock locks the entry of key across processes
+func (c *keyringCache) Lock(ctx context.Context, key Key) (func(), error) {
+	filename, err := computeFilename(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not compute the key: %w", err)
+	}
+	return lockFile(ctx, c.lockDir, filename)
+}
+
+// Entries lists the entries of the cache. All items with the service
+// attribute of ic are included
+func (c *keyringCache) Entries() ([]Entry, error) {
+	items, err := c.ss.SearchItems(map[string]string{"service": keyringService})
+	if err != nil {
+		return nil, keyringError("listing tokens", err)
+	}
+	var entries []Entry
+	for _, item := range items {
+		attributes, err := c.ss.Attributes(item)
+		if err != nil {
+			return nil, keyringError("listing tokens", err)
+		}
+		id := attributes["key"]
+		if !isEntryName(id) {
+			continue
+		}
+		secret, err := c.ss.Secret(item)
+		if err != nil {
+			return nil, keyringError("listing tokens", err)
+		}
+		var t cachedToken
+		if err := json.Unmarshal(secret, &t); err != nil {
+			continue
+		}
+		e := newEntry(id, &t)
+		e.Format = FormatKeyring
+		if e.ModTime, err = c.ss.Modified(item); err != nil {
+			return nil, keyringError("listing tokens", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// Remove removes the entry with the given ID
+func (c *keyringCache) Remove(id string) error {
+	if !isEntryName(id) {
+		return fmt.Errorf("invalid cache entry ID %s", id)
+	}
+	return c.remove(map[string]string{"service": keyringService, "key": id})
+}
+
+// Purge removes all entries and returns the number of entries removed
+func (c *keyringCache) Purge() (int, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return 0, err
+	}
+	for i, e := range entries {
+		if err := c.remove(map[string]string{"service": keyringService, "key": e.ID}); err != nil {
+			return i, err
+		}
+	}
+	return len(entries), nil
+}
+
+// remove deletes the items having attributes. CacheMissError is returned if
+// there are none
+func (c *keyringCache) remove(attributes map[string]string) error {
+	items, err := c.ss.SearchItems(attributes)
+	if err != nil {
+		return keyringError("looking up token", err)
+	}
+	if len(items) == 0 {
+		return &CacheMissError{}
+	}
+	for _, item := range items {
+		if err := c.ss.Delete(item); err != nil {
+			return keyringError("removing token", err)
+		}
+	}
+	return nil
+}
+
+// keyringAttributes returns the attributes identifying the item of key. The
+// key attribute is the name the token would have in the file cache
+func keyringAttributes(key Key) (map[string]string, error) {
+	name, err := computeFilename(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not compute the key: %w", err)
+	}
+	return map[string]string{"service": keyringService, "key": name}, nil
+}
+
+func keyringError(action string, err error) error {
+	return fmt.Errorf("%s in keyring: %w", action, err)
+}
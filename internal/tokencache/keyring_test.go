@@ -0,0 +1,179 @@
+package tokencache
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/neticdk-k8s/ic/internal/oidc"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeItem struct {
+	label      string
+	attributes map[string]string
+	secret     []byte
+}
+
+// fakeSecretService is an in-memory Secret Service
+type fakeSecretService struct {
+	items    map[dbus.ObjectPath]*fakeItem
+	modified time.Time
+	next     int
+}
+
+func newFakeSecretService() *fakeSecretService {
+	return &fakeSecretService{
+		items:    map[dbus.ObjectPath]*fakeItem{},
+		modified: time.Date(2026, 1, 2, 12, 0, 0, 0, time.Local),
+	}
+}
+
+func (f *fakeSecretService) SearchItems(attributes map[string]string) ([]dbus.ObjectPath, error) {
+	var found []dbus.ObjectPath
+	for _, path := range slices.Sorted(maps.Keys(f.items)) {
+		matches := true
+		for k, v := range attributes {
+			matches = matches && f.items[path].attributes[k] == v
+		}
+		if matches {
+			found = append(found, path)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeSecretService) Secret(item dbus.ObjectPath) ([]byte, error) {
+	return f.items[item].secret, nil
+}
+
+func (f *fakeSecretService) Attributes(item dbus.ObjectPath) (map[string]string, error) {
+	return f.items[item].attributes, nil
+}
+
+func (f *fakeSecretService) Modified(dbus.ObjectPath) (time.Time, error) {
+	return f.modified, nil
+}
+
+func (f *fakeSecretService) CreateItem(label string, attributes map[string]string, secret []byte) error {
+	items, _ := f.SearchItems(attributes)
+	for _, item := range items {
+		delete(f.items, item)
+	}
+	f.next++
+	path := dbus.ObjectPath(fmt.Sprintf("/org/freedesktop/secrets/collection/login/%d", f.next))
+	f.items[path] = &fakeItem{label: label, attributes: attributes, secret: secret}
+	return nil
+}
+
+func (f *fakeSecretService) Delete(item dbus.ObjectPath) error {
+	delete(f.items, item)
+	return nil
+}
+
+// item returns the item having attributes
+func (f *fakeSecretService) item(attributes map[string]string) *fakeItem {
+	items, _ := f.SearchItems(attributes)
+	if len(items) != 1 {
+		return nil
+	}
+	return f.items[items[0]]
+}
+
+func TestKeyringCache(t *testing.T) {
+	key := Key{
+		IssuerURL:   "YOUR_ISSUER",
+		ClientID:    "YOUR_CLIENT_ID",
+		ExtraScopes: []string{"openid", "email"},
+		Profile:     "staging",
+	}
+	tokenSet := oidc.TokenSet{AccessToken: "YOUR_ACCESS_TOKEN", IDToken: "YOUR_ID_TOKEN", RefreshToken: "YOUR_REFRESH_TOKEN"}
+
+	ss := newFakeSecretService()
+	cache := &keyringCache{ss: ss, lockDir: t.TempDir()}
+
+	_, err := cache.Lookup(key)
+	assert.ErrorIs(t, err, &CacheMissError{})
+
+	assert.NoError(t, cache.Save(key, tokenSet))
+	filename, err := computeFilename(key)
+	assert.NoError(t, err, "could not compute the key")
+	item := ss.item(map[string]string{"service": "ic", "key": filename})
+	if assert.NotNil(t, item) {
+		assert.Equal(t, `{"key":{"issuer_url":"YOUR_ISSUER","client_id":"YOUR_CLIENT_ID","extra_scopes":["openid","email"],"profile":"staging"},"access_token":"YOUR_ACCESS_TOKEN","id_token":"YOUR_ID_TOKEN","refresh_token":"YOUR_REFRESH_TOKEN"}`, string(item.secret))
+		assert.Equal(t, "ic token for YOUR_ISSUER (YOUR_CLIENT_ID, profile staging)", item.label)
+	}
+
+	// saving again replaces the item
+	assert.NoError(t, cache.Save(key, tokenSet))
+	assert.Len(t, ss.items, 1)
+
+	got, err := cache.Lookup(key)
+	assert.NoError(t, err)
+	assert.Equal(t, &tokenSet, got)
+
+	assert.NoError(t, cache.Invalidate(key))
+	assert.Empty(t, ss.items)
+	assert.ErrorIs(t, cache.Invalidate(key), &CacheMissError{})
+}
+
+func TestKeyringCache_Entries(t *testing.T) {
+	key := Key{IssuerURL: "YOUR_ISSUER", ClientID: "YOUR_CLIENT_ID"}
+	otherKey := Key{IssuerURL: "YOUR_ISSUER", ClientID: "YOUR_CLIENT_ID", Profile: "staging"}
+	tokenSet := oidc.TokenSet{AccessToken: "YOUR_ACCESS_TOKEN", RefreshToken: "YOUR_REFRESH_TOKEN"}
+
+	ss := newFakeSecretService()
+	cache := &keyringCache{ss: ss, lockDir: t.TempDir()}
+
+	entries, err := cache.Entries()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	assert.NoError(t, cache.Save(key, tokenSet))
+	assert.NoError(t, cache.Save(otherKey, tokenSet))
+	otherID, _ := computeFilename(otherKey)
+	// items of other applications and items without a valid key are skipped
+	assert.NoError(t, ss.CreateItem("other", map[string]string{"service": "other", "key": otherID}, []byte(`{}`)))
+	assert.NoError(t, ss.CreateItem("invalid", map[string]string{"service": "ic", "key": "../YOUR_FILE"}, []byte(`{}`)))
+
+	entries, err = cache.Entries()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	got, err := FindEntry(entries, otherID)
+	assert.NoError(t, err)
+	assert.Equal(t, FormatKeyring, got.Format)
+	assert.Equal(t, &otherKey, got.Key)
+	assert.True(t, got.HasRefreshToken)
+	assert.Equal(t, time.Date(2026, 1, 2, 12, 0, 0, 0, time.Local), got.ModTime)
+
+	assert.NoError(t, cache.Remove(otherID))
+	assert.ErrorIs(t, cache.Remove(otherID), &CacheMissError{})
+	assert.Error(t, cache.Remove("../YOUR_FILE"))
+
+	n, err := cache.Purge()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	_, err = cache.Lookup(key)
+	assert.ErrorIs(t, err, &CacheMissError{})
+	assert.Len(t, ss.items, 2)
+}
+
+// lockedSecretService fails like a Secret Service whose collection cannot be
+// unlocked
+type lockedSecretService struct {
+	fakeSecretService
+}
+
+func (lockedSecretService) SearchItems(map[string]string) ([]dbus.ObjectPath, error) {
+	return nil, errors.New("prompt dismissed")
+}
+
+func TestKeyringCache_LookupError(t *testing.T) {
+	cache := &keyringCache{ss: &lockedSecretService{}}
+	_, err := cache.Lookup(Key{IssuerURL: "YOUR_ISSUER"})
+	assert.ErrorContains(t, err, "looking up token in keyring: prompt dismissed")
+}
@@ -0,0 +1,54 @@
+package tokencache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	lockSuffix = ".lock"
+	// lockPollInterval is how often a held lock is retried
+	lockPollInterval = 100 * time.Millisecond
+)
+
+// Locker is implemented by caches which can lock an entry across processes
+type Locker interface {
+	// Lock blocks until the entry of key is locked or ctx is done. The
+	// returned function releases the lock
+	Lock(ctx context.Context, key Key) (unlock func(), err error)
+}
+
+// lockFile takes an exclusive advisory lock on the lock file of the entry
+// named name in dir
+func lockFile(ctx context.Context, dir, name string) (func(), error) {
+	if err := os.MkdirAll(dir, newDirPermissions); err != nil {
+		return nil, fmt.Errorf("could not create directory %s: %w", dir, err)
+	}
+	p := filepath.Join(dir, name+lockSuffix)
+	f, err := os.OpenFile(p, os.O_RDWR|os.O_CREATE, newFilePermissions)
+	if err != nil {
+		return nil, fmt.Errorf("could not open lock file %s: %w", p, err)
+	}
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("could not lock %s: %w", p, err)
+		}
+		if locked {
+			return func() {
+				_ = unlock(f)
+				f.Close()
+			}, nil
+		}
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, fmt.Errorf("waiting for lock %s: %w", p, ctx.Err())
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
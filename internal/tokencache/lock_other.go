@@ -0,0 +1,16 @@
+//go:build !unix
+
+package tokencache
+
+import "os"
+
+// Locking is not supported on this platform. Writes are still atomic but
+// concurrent refreshes are not coalesced
+
+func tryLock(_ *os.File) (bool, error) {
+	return true, nil
+}
+
+func unlock(_ *os.File) error {
+	return nil
+}
@@ -0,0 +1,50 @@
+//go:build unix
+
+package tokencache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFSCache_Lock(t *testing.T) {
+	key := Key{IssuerURL: "YOUR_ISSUER", ClientID: "YOUR_CLIENT_ID"}
+	cache, err := NewFSCache(t.TempDir())
+	assert.NoError(t, err, "could not create new fsCache")
+
+	unlock, err := cache.Lock(context.TODO(), key)
+	assert.NoError(t, err)
+
+	t.Run("Held", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), 3*lockPollInterval)
+		defer cancel()
+		_, err := cache.Lock(ctx, key)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("OtherKey", func(t *testing.T) {
+		unlock, err := cache.Lock(context.TODO(), Key{IssuerURL: "YOUR_ISSUER", ClientID: "OTHER_CLIENT_ID"})
+		assert.NoError(t, err)
+		unlock()
+	})
+
+	t.Run("Released", func(t *testing.T) {
+		locked := make(chan struct{})
+		go func() {
+			unlock, err := cache.Lock(context.TODO(), key)
+			assert.NoError(t, err)
+			close(locked)
+			unlock()
+		}()
+		time.Sleep(2 * lockPollInterval)
+		unlock()
+		select {
+		case <-locked:
+		case <-time.After(5 * time.Second):
+			t.Fatal("lock was not acquired after release")
+		}
+	})
+}
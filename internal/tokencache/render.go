@@ -0,0 +1,136 @@
+package tokencache
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/neticdk-k8s/ic/internal/render"
+	"github.com/neticdk-k8s/ic/internal/ui"
+)
+
+type entriesRenderer struct {
+	render.Base
+	entries []Entry
+}
+
+// NewEntriesRenderer creates a new renderer of token cache entries
+func NewEntriesRenderer(entries []Entry, writer io.Writer, noHeaders bool) *entriesRenderer {
+	if entries == nil {
+		entries = []Entry{}
+	}
+	er := &entriesRenderer{
+		Base:    render.Base{Writer: writer, NoHeaders: noHeaders, Value: entries, Items: entries},
+		entries: entries,
+	}
+	er.Table = er.table
+	er.Names = func() []string {
+		ids := make([]string, 0, len(entries))
+		for _, e := range entries {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+	return er
+}
+
+// shortIDLength is the length of the IDs shown in tables. Commands accept
+// unique ID prefixes
+const shortIDLength = 12
+
+var entryColumns = []render.Column{
+	{Name: "id"},
+	{Name: "profile"},
+	{Name: "issuer"},
+	{Name: "client-id"},
+	{Name: "subject"},
+	{Name: "expires"},
+	{Name: "state"},
+	{Name: "format"},
+}
+
+func (r *entriesRenderer) table() *render.Table {
+	t := &render.Table{Columns: entryColumns}
+	for _, e := range r.entries {
+		var profile, issuer, clientID string
+		if e.Key != nil {
+			profile, issuer, clientID = e.Key.Profile, e.Key.IssuerURL, e.Key.ClientID
+		}
+		t.Rows = append(t.Rows, []string{
+			e.ID[:shortIDLength],
+			profile,
+			issuer,
+			clientID,
+			e.Subject,
+			formatTime(e.ExpiresAt),
+			e.State,
+			e.Format,
+		})
+	}
+	return t
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+type entryRenderer struct {
+	render.Base
+	entry *Entry
+}
+
+// NewEntryRenderer creates a new renderer of a token cache entry
+func NewEntryRenderer(entry *Entry, writer io.Writer) *entryRenderer {
+	er := &entryRenderer{
+		Base:  render.Base{Writer: writer, Value: entry},
+		entry: entry,
+	}
+	er.Text = er.renderText
+	er.Names = func() []string { return []string{entry.ID} }
+	return er
+}
+
+func (r *entryRenderer) renderText() error {
+	e := r.entry
+	rows := [][]string{
+		{"ID:", e.ID},
+	}
+	if e.Key != nil {
+		if e.Key.Profile != "" {
+			rows = append(rows, []string{"Profile:", e.Key.Profile})
+		}
+		rows = append(rows,
+			[]string{"Issuer:", e.Key.IssuerURL},
+			[]string{"Client ID:", e.Key.ClientID},
+		)
+		if len(e.Key.ExtraScopes) > 0 {
+			rows = append(rows, []string{"Scopes:", strings.Join(e.Key.ExtraScopes, ", ")})
+		}
+	}
+	if e.Subject != "" {
+		rows = append(rows, []string{"Subject:", e.Subject})
+	}
+	if e.ExpiresAt != nil {
+		rows = append(rows, []string{"Expires:", formatTime(e.ExpiresAt)})
+	}
+	refreshToken := "not cached"
+	if e.HasRefreshToken {
+		refreshToken = "cached"
+		if e.RefreshExpiresAt != nil {
+			refreshToken = fmt.Sprintf("cached (expires %s)", formatTime(e.RefreshExpiresAt))
+		}
+	}
+	rows = append(rows,
+		[]string{"Refresh token:", refreshToken},
+		[]string{"State:", e.State},
+		[]string{"Format:", e.Format},
+		[]string{"Modified:", e.ModTime.Format(time.RFC3339)},
+	)
+	ui.RenderKVTable(r.Writer, "Cached token", rows)
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package tokencache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	secretServiceName      = "org.freedesktop.secrets"
+	secretServicePath      = dbus.ObjectPath("/org/freedesktop/secrets")
+	secretServiceInterface = "org.freedesktop.Secret.Service"
+	secretCollectionIface  = "org.freedesktop.Secret.Collection"
+	secretItemInterface    = "org.freedesktop.Secret.Item"
+	secretPromptInterface  = "org.freedesktop.Secret.Prompt"
+
+	// noPrompt is the path returned when no prompt is needed
+	noPrompt = dbus.ObjectPath("/")
+)
+
+// secretService is the part of the Secret Service API used by keyringCache
+type secretService interface {
+	// SearchItems returns the items having all of attributes. Locked items
+	// are unlocked
+	SearchItems(attributes map[string]string) ([]dbus.ObjectPath, error)
+	// Secret returns the secret of item
+	Secret(item dbus.ObjectPath) ([]byte, error)
+	// Attributes returns the attributes of item
+	Attributes(item dbus.ObjectPath) (map[string]string, error)
+	// Modified returns the time item was last modified
+	Modified(item dbus.ObjectPath) (time.Time, error)
+	// CreateItem stores secret in the default collection replacing any item
+	// with the same attributes
+	CreateItem(label string, attributes map[string]string, secret []byte) error
+	// Delete deletes item
+	Delete(item dbus.ObjectPath) error
+}
+
+// secret is a secret as transferred over D-Bus
+type secret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// dbusSecretService is a Secret Service client talking to the keyring over the
+// D-Bus session bus. Secrets are transferred unencrypted, which is fine as the
+// session bus is only reachable by the user
+type dbusSecretService struct {
+	conn    *dbus.Conn
+	session dbus.ObjectPath
+}
+
+// newDBusSecretService connects to the Secret Service on the session bus and
+// opens a session
+func newDBusSecretService() (*dbusSecretService, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("connecting to the session bus: %w", err)
+	}
+	var output dbus.Variant
+	var session dbus.ObjectPath
+	err = conn.Object(secretServiceName, secretServicePath).
+		Call(secretServiceInterface+".OpenSession", 0, "plain", dbus.MakeVariant("")).
+		Store(&output, &session)
+	if err != nil {
+		return nil, fmt.Errorf("opening a session: %w", err)
+	}
+	return &dbusSecretService{conn: conn, session: session}, nil
+}
+
+func (s *dbusSecretService) service() dbus.BusObject {
+	return s.conn.Object(secretServiceName, secretServicePath)
+}
+
+func (s *dbusSecretService) SearchItems(attributes map[string]string) ([]dbus.ObjectPath, error) {
+	var unlocked, locked []dbus.ObjectPath
+	err := s.service().Call(secretServiceInterface+".SearchItems", 0, attributes).Store(&unlocked, &locked)
+	if err != nil {
+		return nil, err
+	}
+	if len(locked) > 0 {
+		if err := s.unlock(locked); err != nil {
+			return nil, err
+		}
+	}
+	return append(unlocked, locked...), nil
+}
+
+func (s *dbusSecretService) Secret(item dbus.ObjectPath) ([]byte, error) {
+	var sec secret
+	err := s.conn.Object(secretServiceName, item).Call(secretItemInterface+".GetSecret", 0, s.session).Store(&sec)
+	if err != nil {
+		return nil, err
+	}
+	return sec.Value, nil
+}
+
+func (s *dbusSecretService) Attributes(item dbus.ObjectPath) (map[string]string, error) {
+	v, err := s.conn.Object(secretServiceName, item).GetProperty(secretItemInterface + ".Attributes")
+	if err != nil {
+		return nil, err
+	}
+	var attributes map[string]string
+	if err := v.Store(&attributes); err != nil {
+		return nil, err
+	}
+	return attributes, nil
+}
+
+func (s *dbusSecretService) Modified(item dbus.ObjectPath) (time.Time, error) {
+	v, err := s.conn.Object(secretServiceName, item).GetProperty(secretItemInterface + ".Modified")
+	if err != nil {
+		return time.Time{}, err
+	}
+	var modified uint64
+	if err := v.Store(&modified); err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(int64(modified), 0), nil
+}
+
+func (s *dbusSecretService) CreateItem(label string, attributes map[string]string, value []byte) error {
+	var collection dbus.ObjectPath
+	if err := s.service().Call(secretServiceInterface+".ReadAlias", 0, "default").Store(&collection); err != nil {
+		return err
+	}
+	if collection == noPrompt {
+		return fmt.Errorf("no default collection")
+	}
+	if err := s.unlock([]dbus.ObjectPath{collection}); err != nil {
+		return err
+	}
+	properties := map[string]dbus.Variant{
+		secretItemInterface + ".Label":      dbus.MakeVariant(label),
+		secretItemInterface + ".Attributes": dbus.MakeVariant(attributes),
+	}
+	sec := secret{Session: s.session, Value: value, ContentType: "application/json"}
+	var item, prompt dbus.ObjectPath
+	err := s.conn.Object(secretServiceName, collection).
+		Call(secretCollectionIface+".CreateItem", 0, properties, sec, true).
+		Store(&item, &prompt)
+	if err != nil {
+		return err
+	}
+	return s.prompt(prompt)
+}
+
+func (s *dbusSecretService) Delete(item dbus.ObjectPath) error {
+	var prompt dbus.ObjectPath
+	if err := s.conn.Object(secretServiceName, item).Call(secretItemInterface+".Delete", 0).Store(&prompt); err != nil {
+		return err
+	}
+	return s.prompt(prompt)
+}
+
+// unlock unlocks objects prompting the user if needed
+func (s *dbusSecretService) unlock(objects []dbus.ObjectPath) error {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	if err := s.service().Call(secretServiceInterface+".Unlock", 0, objects).Store(&unlocked, &prompt); err != nil {
+		return err
+	}
+	return s.prompt(prompt)
+}
+
+// prompt shows prompt and waits for the user to complete it
+func (s *dbusSecretService) prompt(prompt dbus.ObjectPath) error {
+	if prompt == noPrompt {
+		return nil
+	}
+	match := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(prompt),
+		dbus.WithMatchInterface(secretPromptInterface),
+		dbus.WithMatchMember("Completed"),
+	}
+	if err := s.conn.AddMatchSignal(match...); err != nil {
+		return err
+	}
+	defer s.conn.RemoveMatchSignal(match...) //nolint:errcheck
+	signals := make(chan *dbus.Signal, 1)
+	s.conn.Signal(signals)
+	defer s.conn.RemoveSignal(signals)
+
+	if err := s.conn.Object(secretServiceName, prompt).Call(secretPromptInterface+".Prompt", 0, "").Err; err != nil {
+		return err
+	}
+	for signal := range signals {
+		if signal.Path != prompt || signal.Name != secretPromptInterface+".Completed" {
+			continue
+		}
+		if dismissed, _ := signal.Body[0].(bool); dismissed {
+			return fmt.Errorf("prompt dismissed")
+		}
+		return nil
+	}
+	return fmt.Errorf("connection closed while prompting")
+}
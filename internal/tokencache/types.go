@@ -1,8 +1,22 @@
 package tokencache
 
+import "github.com/neticdk-k8s/ic/internal/oidc"
+
 // Key is used to generate a unique ID for a cached token
 type Key struct {
-	IssuerURL   string
-	ClientID    string
-	ExtraScopes []string
+	IssuerURL   string   `json:"issuer_url"`
+	ClientID    string   `json:"client_id"`
+	ExtraScopes []string `json:"extra_scopes,omitempty"`
+	// Profile is the configuration profile the token belongs to
+	Profile string `json:"profile,omitempty"`
+}
+
+// NewKey returns the key of tokens for an OIDC provider and profile
+func NewKey(provider oidc.Provider, profile string) Key {
+	return Key{
+		IssuerURL:   provider.IssuerURL,
+		ClientID:    provider.ClientID,
+		ExtraScopes: provider.ExtraScopes,
+		Profile:     profile,
+	}
 }
@@ -20,6 +20,11 @@ type BrowserLoginInput struct {
 	// RedirectURLHostname is the hostname of the redirect URL. You can set this
 	// if your provider does not accept localhost.
 	RedirectURLHostname string
+	// ACRValues are the requested authentication context class references,
+	// space separated in order of preference. Optional
+	ACRValues string
+	// Prompt is the OIDC prompt parameter, e.g. login. Optional
+	Prompt string
 }
 
 // Browser represents a browser based login
@@ -54,6 +59,8 @@ func (b *Browser) Login(ctx context.Context, in *BrowserLoginInput, oidcClient o
 		PKCEParams:          pkce,
 		State:               state,
 		Nonce:               nonce,
+		ACRValues:           in.ACRValues,
+		Prompt:              in.Prompt,
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
@@ -2,8 +2,12 @@ package authcode
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/url"
+	"strings"
 
 	"github.com/int128/oauth2cli/oauth2params"
 	"github.com/neticdk-k8s/ic/internal/oidc"
@@ -16,12 +20,20 @@ const keyboardPrompt = "Enter code: "
 type KeyboardLoginInput struct {
 	// RedirectURI is the URI used for redirection after login
 	RedirectURI string
+	// ACRValues are the requested authentication context class references,
+	// space separated in order of preference. Optional
+	ACRValues string
+	// Prompt is the OIDC prompt parameter, e.g. login. Optional
+	Prompt string
 }
 
 // Keyboard represents a keyboard based login
 type Keyboard struct {
 	// Reader is used to read input from stdin
 	Reader reader.Reader
+	// Writer is where the URL to visit is written. It must not be stdout as
+	// stdout may hold the output of the command, e.g. an ExecCredential
+	Writer io.Writer
 	// Logger holds a logging instance
 	Logger *slog.Logger
 }
@@ -51,16 +63,22 @@ func (k *Keyboard) Login(ctx context.Context, in *KeyboardLoginInput, oidcClient
 		Nonce:       nonce,
 		PKCEParams:  pkce,
 		RedirectURI: in.RedirectURI,
+		ACRValues:   in.ACRValues,
+		Prompt:      in.Prompt,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Printf("Please visit the following URL in your browser: %s\n", authCodeURL)
-	code, err := k.Reader.ReadString(keyboardPrompt)
+	fmt.Fprintf(k.Writer, "Please visit the following URL in your browser: %s\n", authCodeURL)
+	input, err := k.Reader.ReadString(keyboardPrompt)
 	if err != nil {
 		return nil, fmt.Errorf("reading authorization code: %w", err)
 	}
+	code, err := parseCode(input, state)
+	if err != nil {
+		return nil, err
+	}
 
 	k.Logger.DebugContext(ctx, "Exchanging code and token")
 	tokenSet, err := oidcClient.ExchangeAuthCode(ctx, oidc.ExchangeAuthCodeInput{
@@ -75,3 +93,19 @@ func (k *Keyboard) Login(ctx context.Context, in *KeyboardLoginInput, oidcClient
 
 	return tokenSet, nil
 }
+
+// parseCode returns the authorization code entered by the user. Either the
+// code or the whole redirect URL can be entered. The state of a redirect URL
+// must match state
+func parseCode(input, state string) (string, error) {
+	input = strings.TrimSpace(input)
+	u, err := url.Parse(input)
+	if err != nil || !u.Query().Has("code") {
+		return input, nil
+	}
+	query := u.Query()
+	if query.Get("state") != state {
+		return "", errors.New("state does not match the authorization request")
+	}
+	return query.Get("code"), nil
+}
@@ -1,8 +1,10 @@
 package authcode
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"io"
 	"log/slog"
 	"testing"
 	"time"
@@ -45,12 +47,15 @@ func TestKeyboard_Login(t *testing.T) {
 		mockReader.EXPECT().
 			ReadString(keyboardPrompt).
 			Return("YOUR_AUTH_CODE", nil)
+		written := new(bytes.Buffer)
 		u := Keyboard{
 			Reader: mockReader,
+			Writer: written,
 			Logger: slog.Default(),
 		}
 		got, err := u.Login(ctx, o, mockClient)
 		assert.NoError(t, err, "Login returned error")
+		assert.Contains(t, written.String(), "https://issuer.example.com/auth")
 
 		want := &oidc.TokenSet{
 			AccessToken:  "YOUR_ACCESS_TOKEN",
@@ -81,6 +86,7 @@ func TestKeyboard_Login(t *testing.T) {
 			Return("YOUR_INVALID_AUTH_CODE", nil)
 		u := Keyboard{
 			Reader: mockReader,
+			Writer: io.Discard,
 			Logger: slog.Default(),
 		}
 		got, err := u.Login(ctx, o, mockClient)
@@ -88,3 +94,22 @@ func TestKeyboard_Login(t *testing.T) {
 		assert.Nil(t, got)
 	})
 }
+
+func TestParseCode(t *testing.T) {
+	t.Run("Code", func(t *testing.T) {
+		got, err := parseCode(" YOUR_AUTH_CODE\n", "YOUR_STATE")
+		assert.NoError(t, err)
+		assert.Equal(t, "YOUR_AUTH_CODE", got)
+	})
+
+	t.Run("RedirectURL", func(t *testing.T) {
+		got, err := parseCode("http://localhost:8000/?code=YOUR_AUTH_CODE&state=YOUR_STATE", "YOUR_STATE")
+		assert.NoError(t, err)
+		assert.Equal(t, "YOUR_AUTH_CODE", got)
+	})
+
+	t.Run("StateMismatch", func(t *testing.T) {
+		_, err := parseCode("http://localhost:8000/?code=YOUR_AUTH_CODE&state=OTHER_STATE", "YOUR_STATE")
+		assert.ErrorContains(t, err, "state does not match")
+	})
+}
@@ -4,19 +4,39 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/neticdk-k8s/ic/internal/oidc"
 	"github.com/neticdk-k8s/ic/internal/tokencache"
 	"github.com/neticdk-k8s/ic/internal/usecases/authentication/authcode"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication/clientcredentials"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication/devicecode"
 	"github.com/pkg/errors"
 )
 
+// lockTimeout is how long Login waits for another process refreshing the
+// cached token. An interactive login in another process may take longer in
+// which case Login continues without the lock
+const lockTimeout = 30 * time.Second
+
 // LoginInput is the input given to Login
 type LoginInput struct {
 	// Provider represents an OIDC provider configuration
 	Provider oidc.Provider
 	// TokenCache is the interface used for caching tokens
 	TokenCache tokencache.Cache
+	// Profile is the configuration profile used. Tokens are cached per profile
+	Profile string
+	// VerifyCachedToken enables verification of cached tokens against the
+	// keys of the OIDC provider before they are used
+	VerifyCachedToken bool
+	// RefreshSkew is how long before expiry a cached token is refreshed
+	RefreshSkew time.Duration
+	// ForceRefresh refreshes the cached token even if it has not expired,
+	// e.g. when the server rejected it
+	ForceRefresh bool
 	// AuthOptions are the options used for authentication
 	AuthOptions AuthOptions
 }
@@ -27,6 +47,13 @@ type AuthenticateInput struct {
 	Provider oidc.Provider
 	// CachedTokenSet is a TokenSet with cached credentials
 	CachedTokenSet *oidc.TokenSet
+	// VerifyCachedToken enables verification of CachedTokenSet against the
+	// keys of the OIDC provider. A token set failing verification is discarded
+	VerifyCachedToken bool
+	// RefreshSkew is how long before expiry CachedTokenSet is refreshed
+	RefreshSkew time.Duration
+	// ForceRefresh refreshes CachedTokenSet even if it has not expired
+	ForceRefresh bool
 	// AuthOptions are the options used for authentication
 	AuthOptions AuthOptions
 }
@@ -41,8 +68,22 @@ type AuthenticateLogoutInput struct {
 
 // AuthOptions is authentication options used by Authenticate
 type AuthOptions struct {
-	AuthCodeBrowser  *authcode.BrowserLoginInput
-	AuthCodeKeyboard *authcode.KeyboardLoginInput
+	AuthCodeBrowser   *authcode.BrowserLoginInput
+	AuthCodeKeyboard  *authcode.KeyboardLoginInput
+	ClientCredentials *clientcredentials.LoginInput
+	DeviceCode        *devicecode.LoginInput
+}
+
+// acrValues returns the authentication context class references requested by
+// the auth code flows
+func (o AuthOptions) acrValues() []string {
+	switch {
+	case o.AuthCodeBrowser != nil:
+		return strings.Fields(o.AuthCodeBrowser.ACRValues)
+	case o.AuthCodeKeyboard != nil:
+		return strings.Fields(o.AuthCodeKeyboard.ACRValues)
+	}
+	return nil
 }
 
 // AuthResult is the result of an authentication
@@ -59,6 +100,8 @@ type LogoutInput struct {
 	Provider oidc.Provider
 	// TokenCache is the interface used for caching tokens
 	TokenCache tokencache.Cache
+	// Profile is the configuration profile used. Tokens are cached per profile
+	Profile string
 }
 
 // Authenticator represents an Authenticator
@@ -100,27 +143,30 @@ func NewAuthenticator(l *slog.Logger, a Authentication) *authenticator {
 //  2. authenticating using the cached token or if not present performing OIDC authentication using the grant type provided
 //  3. caching the token obtained from the auth flow
 func (a *authenticator) Login(ctx context.Context, in LoginInput) (*oidc.TokenSet, error) {
-	a.logger.DebugContext(ctx, "Fetching cached token")
-
-	tokenCacheKey := tokencache.Key{
-		IssuerURL:   in.Provider.IssuerURL,
-		ClientID:    in.Provider.ClientID,
-		ExtraScopes: in.Provider.ExtraScopes,
-	}
-
-	cachedTokenSet, err := in.TokenCache.Lookup(tokenCacheKey)
-	if err != nil {
-		if errors.Is(err, &tokencache.CacheMissError{}) {
-			a.logger.DebugContext(ctx, "Cached token not found")
+	tokenCacheKey := tokencache.NewKey(in.Provider, in.Profile)
+	cachedTokenSet := a.lookupCachedToken(ctx, in.TokenCache, tokenCacheKey)
+
+	// Only one process at a time refreshes or replaces the cached token. Other
+	// processes wait for the lock and reuse the token it obtained
+	if locker, ok := in.TokenCache.(tokencache.Locker); ok && (in.ForceRefresh || !isUsable(cachedTokenSet, in.RefreshSkew)) {
+		lockCtx, cancel := context.WithTimeout(ctx, lockTimeout)
+		unlock, err := locker.Lock(lockCtx, tokenCacheKey)
+		cancel()
+		if err != nil {
+			a.logger.WarnContext(ctx, "Locking token cache", "err", err)
 		} else {
-			a.logger.ErrorContext(ctx, "Fetching cached token", "err", err)
+			defer unlock()
+			cachedTokenSet = a.lookupCachedToken(ctx, in.TokenCache, tokenCacheKey)
 		}
 	}
 
 	authenticateInput := AuthenticateInput{
-		Provider:       in.Provider,
-		CachedTokenSet: cachedTokenSet,
-		AuthOptions:    in.AuthOptions,
+		Provider:          in.Provider,
+		CachedTokenSet:    cachedTokenSet,
+		VerifyCachedToken: in.VerifyCachedToken,
+		RefreshSkew:       in.RefreshSkew,
+		ForceRefresh:      in.ForceRefresh,
+		AuthOptions:       in.AuthOptions,
 	}
 
 	authResult, err := a.authentication.Authenticate(ctx, authenticateInput)
@@ -147,6 +193,29 @@ func (a *authenticator) Login(ctx context.Context, in LoginInput) (*oidc.TokenSe
 	return &authResult.TokenSet, nil
 }
 
+func (a *authenticator) lookupCachedToken(ctx context.Context, tokenCache tokencache.Cache, key tokencache.Key) *oidc.TokenSet {
+	a.logger.DebugContext(ctx, "Fetching cached token")
+	cachedTokenSet, err := tokenCache.Lookup(key)
+	if err != nil {
+		if errors.Is(err, &tokencache.CacheMissError{}) {
+			a.logger.DebugContext(ctx, "Cached token not found")
+		} else {
+			a.logger.ErrorContext(ctx, "Fetching cached token", "err", err)
+		}
+		return nil
+	}
+	return cachedTokenSet
+}
+
+// isUsable returns true if tokenSet can be used without refreshing it
+func isUsable(tokenSet *oidc.TokenSet, refreshSkew time.Duration) bool {
+	if tokenSet == nil {
+		return false
+	}
+	claims, err := tokenSet.DecodeWithoutVerify()
+	return err == nil && !claims.ExpiresWithin(refreshSkew)
+}
+
 // Logout performs OIDC logout by:
 //
 //  1. fetching a cached token
@@ -155,11 +224,7 @@ func (a *authenticator) Login(ctx context.Context, in LoginInput) (*oidc.TokenSe
 func (a *authenticator) Logout(ctx context.Context, in LogoutInput) error {
 	a.logger.DebugContext(ctx, "Fetching cached token")
 
-	tokenCacheKey := tokencache.Key{
-		IssuerURL:   in.Provider.IssuerURL,
-		ClientID:    in.Provider.ClientID,
-		ExtraScopes: in.Provider.ExtraScopes,
-	}
+	tokenCacheKey := tokencache.NewKey(in.Provider, in.Profile)
 
 	cachedTokenSet, err := in.TokenCache.Lookup(tokenCacheKey)
 	if err != nil {
@@ -212,17 +277,23 @@ type authentication struct {
 	authCodeBrowser *authcode.Browser
 	// AuthCodeKeyboard is the configuration used when authenticating using authcode-keyboard
 	authCodeKeyboard *authcode.Keyboard
+	// ClientCredentials is the configuration used when authenticating using client-credentials
+	clientCredentials *clientcredentials.ClientCredentials
+	// DeviceCode is the configuration used when authenticating using device-code
+	deviceCode *devicecode.DeviceCode
 }
 
 // NewAuthentication creates a new authentication
-func NewAuthentication(l *slog.Logger, clientFactory oidc.FactoryClient, authCodeBrowser *authcode.Browser, authCodeKeyboard *authcode.Keyboard) *authentication {
+func NewAuthentication(l *slog.Logger, clientFactory oidc.FactoryClient, authCodeBrowser *authcode.Browser, authCodeKeyboard *authcode.Keyboard, clientCredentials *clientcredentials.ClientCredentials, deviceCode *devicecode.DeviceCode) *authentication {
 	authn := &authentication{
 		oidcClientFactory: &oidc.Factory{
 			Logger: l,
 		},
-		logger:           l,
-		authCodeBrowser:  authCodeBrowser,
-		authCodeKeyboard: authCodeKeyboard,
+		logger:            l,
+		authCodeBrowser:   authCodeBrowser,
+		authCodeKeyboard:  authCodeKeyboard,
+		clientCredentials: clientCredentials,
+		deviceCode:        deviceCode,
 	}
 	if clientFactory != nil {
 		authn.oidcClientFactory = clientFactory
@@ -232,30 +303,64 @@ func NewAuthentication(l *slog.Logger, clientFactory oidc.FactoryClient, authCod
 
 // Authenticate performs the OIDC authentication using the configuration given by AuthenticateInput
 func (a *authentication) Authenticate(ctx context.Context, in AuthenticateInput) (*AuthResult, error) {
-	if in.CachedTokenSet != nil {
+	var (
+		oidcClient     oidc.Client
+		cachedTokenSet = in.CachedTokenSet
+		err            error
+	)
+	if cachedTokenSet != nil && in.VerifyCachedToken {
+		if oidcClient, err = a.oidcClientFactory.New(ctx, in.Provider); err != nil {
+			return nil, fmt.Errorf("creating OIDC client: %w", err)
+		}
+		a.logger.DebugContext(ctx, "Verifying cached token")
+		if err := oidcClient.VerifyTokenSet(ctx, *cachedTokenSet); err != nil {
+			// the refresh token cannot be trusted either so the whole set is
+			// discarded
+			a.logger.WarnContext(ctx, "Discarding cached token which failed verification", "err", err)
+			cachedTokenSet = nil
+		}
+	}
+
+	if acrValues := in.AuthOptions.acrValues(); cachedTokenSet != nil && len(acrValues) > 0 {
+		// a refreshed token keeps the authentication context of the session so
+		// a new login is needed for step-up authentication
+		if claims, err := cachedTokenSet.DecodeWithoutVerify(); err == nil && !slices.Contains(acrValues, claims.ACR) {
+			a.logger.DebugContext(ctx, "Cached token lacks the requested authentication context", "acr", claims.ACR, "acrValues", acrValues)
+			cachedTokenSet = nil
+		}
+	}
+
+	if cachedTokenSet != nil {
 		a.logger.DebugContext(ctx, "Found cached token")
-		claims, err := in.CachedTokenSet.DecodeWithoutVerify()
+		claims, err := cachedTokenSet.DecodeWithoutVerify()
 		if err != nil {
 			return nil, fmt.Errorf("decoding token: %w", err)
 		}
-		if !claims.IsExpired() {
+		switch {
+		case in.ForceRefresh:
+			a.logger.DebugContext(ctx, "Cached token was rejected", "expires", claims.Expiry)
+		case !claims.ExpiresWithin(in.RefreshSkew):
 			a.logger.DebugContext(ctx, "Found cached token", "expires", claims.Expiry)
 			return &AuthResult{
 				UsingCachedToken: true,
-				TokenSet:         *in.CachedTokenSet,
+				TokenSet:         *cachedTokenSet,
 			}, nil
+		case !claims.IsExpired():
+			a.logger.DebugContext(ctx, "Cached token is about to expire", "expires", claims.Expiry)
+		default:
+			a.logger.DebugContext(ctx, "Cached token is expired")
 		}
-		a.logger.DebugContext(ctx, "Cached token is expired")
 	}
 
-	oidcClient, err := a.oidcClientFactory.New(ctx, in.Provider)
-	if err != nil {
-		return nil, fmt.Errorf("creating OIDC client: %w", err)
+	if oidcClient == nil {
+		if oidcClient, err = a.oidcClientFactory.New(ctx, in.Provider); err != nil {
+			return nil, fmt.Errorf("creating OIDC client: %w", err)
+		}
 	}
 
-	if in.CachedTokenSet != nil && in.CachedTokenSet.RefreshToken != "" {
+	if cachedTokenSet != nil && cachedTokenSet.RefreshToken != "" {
 		a.logger.DebugContext(ctx, "Refreshing token")
-		tokenSet, err := oidcClient.Refresh(ctx, in.CachedTokenSet.RefreshToken)
+		tokenSet, err := oidcClient.Refresh(ctx, cachedTokenSet.RefreshToken)
 		if err == nil {
 			return &AuthResult{TokenSet: *tokenSet}, nil
 		}
@@ -280,6 +385,24 @@ func (a *authentication) Authenticate(ctx context.Context, in AuthenticateInput)
 		return &AuthResult{TokenSet: *tokenSet}, nil
 	}
 
+	if in.AuthOptions.ClientCredentials != nil {
+		a.logger.DebugContext(ctx, "Authenticating using client-credentials")
+		tokenSet, err := a.clientCredentials.Login(ctx, in.AuthOptions.ClientCredentials, oidcClient)
+		if err != nil {
+			return nil, fmt.Errorf("client-credentials error: %w", err)
+		}
+		return &AuthResult{TokenSet: *tokenSet}, nil
+	}
+
+	if in.AuthOptions.DeviceCode != nil {
+		a.logger.DebugContext(ctx, "Authenticating using device-code")
+		tokenSet, err := a.deviceCode.Login(ctx, in.AuthOptions.DeviceCode, oidcClient)
+		if err != nil {
+			return nil, fmt.Errorf("device-code error: %w", err)
+		}
+		return &AuthResult{TokenSet: *tokenSet}, nil
+	}
+
 	return nil, fmt.Errorf("unknown authentication method")
 }
 
@@ -305,4 +428,6 @@ func (a *authentication) SetLogger(l *slog.Logger) {
 	a.oidcClientFactory.SetLogger(l)
 	a.authCodeBrowser.Logger = l
 	a.authCodeKeyboard.Logger = l
+	a.clientCredentials.Logger = l
+	a.deviceCode.Logger = l
 }
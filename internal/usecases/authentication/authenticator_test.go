@@ -2,7 +2,9 @@ package authentication
 
 import (
 	"context"
+	"io"
 	"log/slog"
+	"sync"
 	"testing"
 	"time"
 
@@ -12,6 +14,8 @@ import (
 	testingJWT "github.com/neticdk-k8s/ic/internal/testing/jwt"
 	"github.com/neticdk-k8s/ic/internal/tokencache"
 	"github.com/neticdk-k8s/ic/internal/usecases/authentication/authcode"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication/clientcredentials"
+	"github.com/neticdk-k8s/ic/internal/usecases/authentication/devicecode"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	mock "github.com/stretchr/testify/mock"
@@ -20,7 +24,7 @@ import (
 func TestAuthenticator_NewAuthenticator(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		logger := slog.Default()
-		authn := NewAuthentication(logger, nil, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Logger: logger})
+		authn := NewAuthentication(logger, nil, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Writer: io.Discard, Logger: logger}, &clientcredentials.ClientCredentials{Logger: logger}, &devicecode.DeviceCode{Writer: io.Discard, Logger: logger})
 		want := &authenticator{
 			authentication: authn,
 			logger:         logger,
@@ -141,6 +145,66 @@ func TestAuthenticator_Login(t *testing.T) {
 	})
 }
 
+func TestAuthenticator_Login_ConcurrentRefresh(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	testProvider := oidc.Provider{
+		IssuerURL: "https://issuer.example.com",
+		ClientID:  "YOUR_CLIENT_ID",
+	}
+	encode := func(expiresAt time.Time) string {
+		return testingJWT.EncodeF(t, func(claims *testingJWT.Claims) {
+			claims.Issuer = "https://issuer.example.com"
+			claims.Subject = "YOUR_SUBJECT"
+			claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+		})
+	}
+	expiredToken := encode(time.Now().Add(-time.Hour))
+	refreshedToken := encode(time.Now().Add(time.Hour))
+	refreshedTokenSet := oidc.TokenSet{
+		AccessToken:  refreshedToken,
+		IDToken:      refreshedToken,
+		RefreshToken: "NEW_REFRESH_TOKEN",
+	}
+
+	tokenCache, err := tokencache.NewFSCache(t.TempDir())
+	assert.NoError(t, err)
+	assert.NoError(t, tokenCache.Save(tokencache.NewKey(testProvider, ""), oidc.TokenSet{
+		AccessToken:  expiredToken,
+		IDToken:      expiredToken,
+		RefreshToken: "YOUR_REFRESH_TOKEN",
+	}))
+
+	// only a single process may refresh the token
+	mockClient := oidc.NewMockClient(t)
+	mockClient.EXPECT().
+		Refresh(mock.Anything, "YOUR_REFRESH_TOKEN").
+		Run(func(_ context.Context, _ string) { time.Sleep(200 * time.Millisecond) }).
+		Return(&refreshedTokenSet, nil).
+		Once()
+	mockClientFactory := oidc.NewMockFactoryClient(t)
+	mockClientFactory.EXPECT().
+		New(mock.Anything, testProvider).
+		Return(mockClient, nil).
+		Once()
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// each process has its own authenticator
+			authn := NewAuthentication(logger, mockClientFactory, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Writer: io.Discard, Logger: logger}, &clientcredentials.ClientCredentials{Logger: logger}, &devicecode.DeviceCode{Writer: io.Discard, Logger: logger})
+			got, err := NewAuthenticator(logger, authn).Login(context.TODO(), LoginInput{
+				Provider:   testProvider,
+				TokenCache: tokenCache,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, &refreshedTokenSet, got)
+		}()
+	}
+	wg.Wait()
+}
+
 func TestAuthenticator_Logout(t *testing.T) {
 	logger := slog.Default()
 	testProvider := oidc.Provider{
@@ -226,11 +290,19 @@ func TestAuthentication_NewAuthentication(t *testing.T) {
 			},
 			authCodeKeyboard: &authcode.Keyboard{
 				Reader: reader.NewReader(),
+				Writer: io.Discard,
+				Logger: logger,
+			},
+			clientCredentials: &clientcredentials.ClientCredentials{
+				Logger: logger,
+			},
+			deviceCode: &devicecode.DeviceCode{
+				Writer: io.Discard,
 				Logger: logger,
 			},
 		}
 
-		got := NewAuthentication(logger, nil, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Logger: logger})
+		got := NewAuthentication(logger, nil, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Writer: io.Discard, Logger: logger}, &clientcredentials.ClientCredentials{Logger: logger}, &devicecode.DeviceCode{Writer: io.Discard, Logger: logger})
 		assert.Equal(t, want, got)
 	})
 }
@@ -257,10 +329,16 @@ func TestAuthentication_Authenticate(t *testing.T) {
 		claims.ExpiresAt = jwt.NewNumericDate(goodExpiryTime)
 	})
 
+	soonExpiringIDToken := testingJWT.EncodeF(t, func(claims *testingJWT.Claims) {
+		claims.Issuer = "https://issuer.example.com"
+		claims.Subject = "YOUR_SUBJECT"
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(30 * time.Second))
+	})
+
 	t.Run("HasValidIDToken", func(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
 
-		authentication := NewAuthentication(logger, nil, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Logger: logger})
+		authentication := NewAuthentication(logger, nil, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Writer: io.Discard, Logger: logger}, &clientcredentials.ClientCredentials{Logger: logger}, &devicecode.DeviceCode{Writer: io.Discard, Logger: logger})
 
 		defer cancel()
 		in := AuthenticateInput{
@@ -282,6 +360,79 @@ func TestAuthentication_Authenticate(t *testing.T) {
 		assert.Equal(t, want, got)
 	})
 
+	t.Run("HasValidIDToken/Verified", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+		defer cancel()
+		cachedTokenSet := oidc.TokenSet{
+			AccessToken: goodIssuedIDToken,
+			IDToken:     goodIssuedIDToken,
+		}
+		in := AuthenticateInput{
+			Provider:          testProvider,
+			CachedTokenSet:    &cachedTokenSet,
+			VerifyCachedToken: true,
+		}
+		mockClient := oidc.NewMockClient(t)
+		mockClient.EXPECT().
+			VerifyTokenSet(ctx, cachedTokenSet).
+			Return(nil)
+		mockClientFactory := oidc.NewMockFactoryClient(t)
+		mockClientFactory.EXPECT().
+			New(ctx, testProvider).
+			Return(mockClient, nil)
+		authentication := NewAuthentication(logger, mockClientFactory, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Writer: io.Discard, Logger: logger}, &clientcredentials.ClientCredentials{Logger: logger}, &devicecode.DeviceCode{Writer: io.Discard, Logger: logger})
+		got, err := authentication.Authenticate(ctx, in)
+		assert.NoError(t, err)
+		want := &AuthResult{
+			UsingCachedToken: true,
+			TokenSet:         cachedTokenSet,
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("HasTamperedToken/Verified", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+		defer cancel()
+		cachedTokenSet := oidc.TokenSet{
+			AccessToken:  goodIssuedIDToken,
+			IDToken:      goodIssuedIDToken,
+			RefreshToken: "TAMPERED_REFRESH_TOKEN",
+		}
+		in := AuthenticateInput{
+			Provider:          testProvider,
+			CachedTokenSet:    &cachedTokenSet,
+			VerifyCachedToken: true,
+			AuthOptions: AuthOptions{
+				ClientCredentials: &clientcredentials.LoginInput{
+					ClientSecret: "YOUR_CLIENT_SECRET",
+				},
+			},
+		}
+		mockClient := oidc.NewMockClient(t)
+		mockClient.EXPECT().
+			VerifyTokenSet(ctx, cachedTokenSet).
+			Return(errors.New("verifying id token: failed to verify signature"))
+		mockClient.EXPECT().
+			GetTokenByClientCredentials(mock.Anything, oidc.GetTokenByClientCredentialsInput{ClientSecret: "YOUR_CLIENT_SECRET"}).
+			Return(&oidc.TokenSet{
+				AccessToken: "NEW_ACCESS_TOKEN",
+			}, nil)
+		mockClientFactory := oidc.NewMockFactoryClient(t)
+		mockClientFactory.EXPECT().
+			New(ctx, testProvider).
+			Return(mockClient, nil).
+			Once()
+		authentication := NewAuthentication(logger, mockClientFactory, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Writer: io.Discard, Logger: logger}, &clientcredentials.ClientCredentials{Logger: logger}, &devicecode.DeviceCode{Writer: io.Discard, Logger: logger})
+		got, err := authentication.Authenticate(ctx, in)
+		assert.NoError(t, err)
+		want := &AuthResult{
+			TokenSet: oidc.TokenSet{
+				AccessToken: "NEW_ACCESS_TOKEN",
+			},
+		}
+		assert.Equal(t, want, got)
+	})
+
 	t.Run("HasValidRefreshToken", func(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
 		defer cancel()
@@ -305,7 +456,7 @@ func TestAuthentication_Authenticate(t *testing.T) {
 		mockClientFactory.EXPECT().
 			New(ctx, testProvider).
 			Return(mockClient, nil)
-		authentication := NewAuthentication(logger, mockClientFactory, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Logger: logger})
+		authentication := NewAuthentication(logger, mockClientFactory, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Writer: io.Discard, Logger: logger}, &clientcredentials.ClientCredentials{Logger: logger}, &devicecode.DeviceCode{Writer: io.Discard, Logger: logger})
 		got, err := authentication.Authenticate(ctx, in)
 		if err != nil {
 			t.Errorf("Do returned error: %+v", err)
@@ -320,6 +471,51 @@ func TestAuthentication_Authenticate(t *testing.T) {
 		assert.Equal(t, want, got)
 	})
 
+	for name, in := range map[string]AuthenticateInput{
+		"ExpiresWithinRefreshSkew": {
+			Provider: testProvider,
+			CachedTokenSet: &oidc.TokenSet{
+				AccessToken:  soonExpiringIDToken,
+				RefreshToken: "VALID_REFRESH_TOKEN",
+			},
+			RefreshSkew: time.Minute,
+		},
+		"ForceRefresh": {
+			Provider: testProvider,
+			CachedTokenSet: &oidc.TokenSet{
+				AccessToken:  goodIssuedIDToken,
+				RefreshToken: "VALID_REFRESH_TOKEN",
+			},
+			ForceRefresh: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+			defer cancel()
+			mockClient := oidc.NewMockClient(t)
+			mockClient.EXPECT().
+				Refresh(ctx, "VALID_REFRESH_TOKEN").
+				Return(&oidc.TokenSet{
+					AccessToken:  "NEW_ACCESS_TOKEN",
+					RefreshToken: "NEW_REFRESH_TOKEN",
+				}, nil)
+			mockClientFactory := oidc.NewMockFactoryClient(t)
+			mockClientFactory.EXPECT().
+				New(ctx, testProvider).
+				Return(mockClient, nil)
+			authentication := NewAuthentication(logger, mockClientFactory, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Writer: io.Discard, Logger: logger}, &clientcredentials.ClientCredentials{Logger: logger}, &devicecode.DeviceCode{Writer: io.Discard, Logger: logger})
+			got, err := authentication.Authenticate(ctx, in)
+			assert.NoError(t, err)
+			want := &AuthResult{
+				TokenSet: oidc.TokenSet{
+					AccessToken:  "NEW_ACCESS_TOKEN",
+					RefreshToken: "NEW_REFRESH_TOKEN",
+				},
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+
 	t.Run("HasExpiredRefreshToken/Browser", func(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
 		defer cancel()
@@ -355,7 +551,163 @@ func TestAuthentication_Authenticate(t *testing.T) {
 		mockClientFactory.EXPECT().
 			New(ctx, testProvider).
 			Return(mockClient, nil)
-		authentication := NewAuthentication(logger, mockClientFactory, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Logger: logger})
+		authentication := NewAuthentication(logger, mockClientFactory, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Writer: io.Discard, Logger: logger}, &clientcredentials.ClientCredentials{Logger: logger}, &devicecode.DeviceCode{Writer: io.Discard, Logger: logger})
+		got, err := authentication.Authenticate(ctx, in)
+		if err != nil {
+			t.Errorf("Do returned error: %+v", err)
+		}
+		want := &AuthResult{
+			TokenSet: oidc.TokenSet{
+				AccessToken:  "NEW_ACCESS_TOKEN",
+				IDToken:      "NEW_ID_TOKEN",
+				RefreshToken: "NEW_REFRESH_TOKEN",
+			},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("HasValidIDToken/ACRMismatch", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+		defer cancel()
+		silverIDToken := testingJWT.EncodeF(t, func(claims *testingJWT.Claims) {
+			claims.Issuer = "https://issuer.example.com"
+			claims.Subject = "YOUR_SUBJECT"
+			claims.ACR = "silver"
+			claims.ExpiresAt = jwt.NewNumericDate(goodExpiryTime)
+		})
+		in := AuthenticateInput{
+			Provider: testProvider,
+			AuthOptions: AuthOptions{
+				AuthCodeBrowser: &authcode.BrowserLoginInput{
+					BindAddress:         "127.0.0.1",
+					RedirectURLHostname: "localhost",
+					ACRValues:           "gold",
+					Prompt:              "login",
+				},
+			},
+			CachedTokenSet: &oidc.TokenSet{
+				AccessToken:  silverIDToken,
+				IDToken:      silverIDToken,
+				RefreshToken: "YOUR_REFRESH_TOKEN",
+			},
+		}
+		mockClient := oidc.NewMockClient(t)
+		mockClient.EXPECT().
+			GetTokenByAuthCode(mock.Anything, mock.Anything, mock.Anything).
+			Run(func(_ context.Context, in oidc.GetTokenByAuthCodeInput, readyChan chan<- string) {
+				assert.Equal(t, "gold", in.ACRValues)
+				assert.Equal(t, "login", in.Prompt)
+				readyChan <- "LOCAL_SERVER_URL"
+			}).
+			Return(&oidc.TokenSet{
+				AccessToken:  "NEW_ACCESS_TOKEN",
+				IDToken:      "NEW_ID_TOKEN",
+				RefreshToken: "NEW_REFRESH_TOKEN",
+			}, nil)
+		mockClientFactory := oidc.NewMockFactoryClient(t)
+		mockClientFactory.EXPECT().
+			New(ctx, testProvider).
+			Return(mockClient, nil)
+		authentication := NewAuthentication(logger, mockClientFactory, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Writer: io.Discard, Logger: logger}, &clientcredentials.ClientCredentials{Logger: logger}, &devicecode.DeviceCode{Writer: io.Discard, Logger: logger})
+		got, err := authentication.Authenticate(ctx, in)
+		assert.NoError(t, err)
+		want := &AuthResult{
+			TokenSet: oidc.TokenSet{
+				AccessToken:  "NEW_ACCESS_TOKEN",
+				IDToken:      "NEW_ID_TOKEN",
+				RefreshToken: "NEW_REFRESH_TOKEN",
+			},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("HasValidIDToken/ACRMatch", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+		defer cancel()
+		goldIDToken := testingJWT.EncodeF(t, func(claims *testingJWT.Claims) {
+			claims.Issuer = "https://issuer.example.com"
+			claims.Subject = "YOUR_SUBJECT"
+			claims.ACR = "gold"
+			claims.ExpiresAt = jwt.NewNumericDate(goodExpiryTime)
+		})
+		cachedTokenSet := oidc.TokenSet{AccessToken: goldIDToken, IDToken: goldIDToken}
+		in := AuthenticateInput{
+			Provider: testProvider,
+			AuthOptions: AuthOptions{
+				AuthCodeKeyboard: &authcode.KeyboardLoginInput{ACRValues: "gold silver"},
+			},
+			CachedTokenSet: &cachedTokenSet,
+		}
+		authentication := NewAuthentication(logger, nil, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Writer: io.Discard, Logger: logger}, &clientcredentials.ClientCredentials{Logger: logger}, &devicecode.DeviceCode{Writer: io.Discard, Logger: logger})
+		got, err := authentication.Authenticate(ctx, in)
+		assert.NoError(t, err)
+		assert.Equal(t, &AuthResult{UsingCachedToken: true, TokenSet: cachedTokenSet}, got)
+	})
+
+	t.Run("NoCachedToken/ClientCredentials", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+		defer cancel()
+		in := AuthenticateInput{
+			Provider: testProvider,
+			AuthOptions: AuthOptions{
+				ClientCredentials: &clientcredentials.LoginInput{
+					ClientSecret: "YOUR_CLIENT_SECRET",
+				},
+			},
+		}
+		mockClient := oidc.NewMockClient(t)
+		mockClient.EXPECT().
+			GetTokenByClientCredentials(mock.Anything, oidc.GetTokenByClientCredentialsInput{ClientSecret: "YOUR_CLIENT_SECRET"}).
+			Return(&oidc.TokenSet{
+				AccessToken: "NEW_ACCESS_TOKEN",
+			}, nil)
+		mockClientFactory := oidc.NewMockFactoryClient(t)
+		mockClientFactory.EXPECT().
+			New(ctx, testProvider).
+			Return(mockClient, nil)
+		authentication := NewAuthentication(logger, mockClientFactory, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Writer: io.Discard, Logger: logger}, &clientcredentials.ClientCredentials{Logger: logger}, &devicecode.DeviceCode{Writer: io.Discard, Logger: logger})
+		got, err := authentication.Authenticate(ctx, in)
+		if err != nil {
+			t.Errorf("Do returned error: %+v", err)
+		}
+		want := &AuthResult{
+			TokenSet: oidc.TokenSet{
+				AccessToken: "NEW_ACCESS_TOKEN",
+			},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("NoCachedToken/DeviceCode", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+		defer cancel()
+		in := AuthenticateInput{
+			Provider: testProvider,
+			AuthOptions: AuthOptions{
+				DeviceCode: &devicecode.LoginInput{},
+			},
+		}
+		mockClient := oidc.NewMockClient(t)
+		mockClient.EXPECT().
+			GetDeviceAuthorization(mock.Anything).
+			Return(&oidc.DeviceAuthorization{
+				DeviceCode:      "YOUR_DEVICE_CODE",
+				UserCode:        "ABCD-EFGH",
+				VerificationURI: "https://issuer.example.com/device",
+				Interval:        time.Millisecond,
+			}, nil)
+		mockClient.EXPECT().
+			ExchangeDeviceCode(mock.Anything, oidc.ExchangeDeviceCodeInput{DeviceCode: "YOUR_DEVICE_CODE"}).
+			Return(&oidc.TokenSet{
+				AccessToken:  "NEW_ACCESS_TOKEN",
+				IDToken:      "NEW_ID_TOKEN",
+				RefreshToken: "NEW_REFRESH_TOKEN",
+			}, nil)
+		mockClientFactory := oidc.NewMockFactoryClient(t)
+		mockClientFactory.EXPECT().
+			New(ctx, testProvider).
+			Return(mockClient, nil)
+		authentication := NewAuthentication(logger, mockClientFactory, &authcode.Browser{Logger: logger}, &authcode.Keyboard{Reader: reader.NewReader(), Writer: io.Discard, Logger: logger}, &clientcredentials.ClientCredentials{Logger: logger}, &devicecode.DeviceCode{Writer: io.Discard, Logger: logger})
 		got, err := authentication.Authenticate(ctx, in)
 		if err != nil {
 			t.Errorf("Do returned error: %+v", err)
@@ -0,0 +1,41 @@
+package clientcredentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/neticdk-k8s/ic/internal/oidc"
+)
+
+// LoginInput is the input given to Login
+type LoginInput struct {
+	// ClientSecret is the secret of the confidential client
+	ClientSecret string
+}
+
+// ClientCredentials represents a non-interactive login using a client id and
+// secret
+type ClientCredentials struct {
+	// Logger holds a logging instance
+	Logger *slog.Logger
+}
+
+// Login performs the client credentials flow, i.e. exchanging the client id
+// and secret for a token at the OIDC issuer
+func (c *ClientCredentials) Login(ctx context.Context, in *LoginInput, oidcClient oidc.Client) (*oidc.TokenSet, error) {
+	if in.ClientSecret == "" {
+		return nil, errors.New("client secret not set")
+	}
+
+	c.Logger.DebugContext(ctx, "Getting token using client credentials")
+	tokenSet, err := oidcClient.GetTokenByClientCredentials(ctx, oidc.GetTokenByClientCredentialsInput{
+		ClientSecret: in.ClientSecret,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting token: %w", err)
+	}
+
+	return tokenSet, nil
+}
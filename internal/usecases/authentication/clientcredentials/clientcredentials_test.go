@@ -0,0 +1,72 @@
+package clientcredentials
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/neticdk-k8s/ic/internal/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestClientCredentials_Login(t *testing.T) {
+	timeout := 5 * time.Second
+
+	t.Run("Success", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+		defer cancel()
+
+		o := &LoginInput{
+			ClientSecret: "YOUR_CLIENT_SECRET",
+		}
+
+		mockClient := oidc.NewMockClient(t)
+		mockClient.EXPECT().
+			GetTokenByClientCredentials(mock.Anything, oidc.GetTokenByClientCredentialsInput{ClientSecret: "YOUR_CLIENT_SECRET"}).
+			Return(&oidc.TokenSet{
+				AccessToken: "YOUR_ACCESS_TOKEN",
+			}, nil)
+		u := ClientCredentials{
+			Logger: slog.Default(),
+		}
+		got, err := u.Login(ctx, o, mockClient)
+		assert.NoError(t, err, "Login returned error")
+
+		want := &oidc.TokenSet{
+			AccessToken: "YOUR_ACCESS_TOKEN",
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("NoSecret", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+		defer cancel()
+
+		mockClient := oidc.NewMockClient(t)
+		u := ClientCredentials{
+			Logger: slog.Default(),
+		}
+		got, err := u.Login(ctx, &LoginInput{}, mockClient)
+		assert.Error(t, err, "Login returned error")
+		assert.Nil(t, got)
+	})
+
+	t.Run("AuthError", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+		defer cancel()
+
+		mockClient := oidc.NewMockClient(t)
+		mockClient.EXPECT().
+			GetTokenByClientCredentials(mock.Anything, mock.Anything).
+			Return(nil, errors.New("invalid client secret"))
+		u := ClientCredentials{
+			Logger: slog.Default(),
+		}
+		got, err := u.Login(ctx, &LoginInput{ClientSecret: "YOUR_INVALID_SECRET"}, mockClient)
+		assert.Error(t, err, "Login returned error")
+		assert.Nil(t, got)
+	})
+}
@@ -0,0 +1,125 @@
+package devicecode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/neticdk-k8s/ic/internal/oidc"
+)
+
+const (
+	// slowDownIncrement is added to the polling interval every time the
+	// provider asks us to slow down (RFC 8628 section 3.5)
+	slowDownIncrement = time.Duration(5) * time.Second
+	// maxInterval is the maximum polling interval used when backing off after
+	// failed requests
+	maxInterval = time.Duration(60) * time.Second
+	// maxFailures is the number of failed requests in a row after which we
+	// give up
+	maxFailures = 5
+)
+
+// wait blocks for d or until ctx is done. It is a variable so tests can
+// avoid sleeping
+var wait = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// LoginInput is the input given to Login
+type LoginInput struct{}
+
+// DeviceCode represents a login using the device authorization grant. It is
+// useful on machines without a browser
+type DeviceCode struct {
+	// Writer is where the login instructions are written
+	Writer io.Writer
+	// Logger holds a logging instance
+	Logger *slog.Logger
+}
+
+// Login performs the device authorization flow, i.e.:
+// 1. Requesting a device and user code from the OIDC issuer
+// 2. Printing the verification URI and user code
+// 3. Polling the token endpoint until the user has logged in
+func (d *DeviceCode) Login(ctx context.Context, _ *LoginInput, oidcClient oidc.Client) (*oidc.TokenSet, error) {
+	authorization, err := oidcClient.GetDeviceAuthorization(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting device authorization: %w", err)
+	}
+
+	if authorization.VerificationURIComplete != "" {
+		fmt.Fprintf(d.Writer, "Please visit the following URL in a browser: %s\n", authorization.VerificationURIComplete)
+		fmt.Fprintf(d.Writer, "Or visit %s and enter the code: %s\n", authorization.VerificationURI, authorization.UserCode)
+	} else {
+		fmt.Fprintf(d.Writer, "Please visit %s and enter the code: %s\n", authorization.VerificationURI, authorization.UserCode)
+	}
+
+	if !authorization.Expiry.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, authorization.Expiry)
+		defer cancel()
+	}
+
+	tokenSet, err := d.poll(ctx, authorization, oidcClient)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && !time.Now().Before(authorization.Expiry) {
+			return nil, errors.New("device code expired before login was completed")
+		}
+		return nil, err
+	}
+
+	return tokenSet, nil
+}
+
+// poll polls the token endpoint until a token is issued. The interval is
+// increased when the provider asks us to slow down. Requests failing for
+// other reasons than an error response from the provider, e.g. network
+// errors, are retried with the interval doubled up to maxInterval. Polling
+// stops after maxFailures failed requests in a row
+func (d *DeviceCode) poll(ctx context.Context, authorization *oidc.DeviceAuthorization, oidcClient oidc.Client) (*oidc.TokenSet, error) {
+	interval := authorization.Interval
+	backoff := interval
+	failures := 0
+	for {
+		if err := wait(ctx, backoff); err != nil {
+			return nil, err
+		}
+
+		tokenSet, err := oidcClient.ExchangeDeviceCode(ctx, oidc.ExchangeDeviceCodeInput{
+			DeviceCode: authorization.DeviceCode,
+		})
+		if err == nil {
+			return tokenSet, nil
+		}
+		failures++
+		switch {
+		case errors.Is(err, oidc.ErrAuthorizationPending):
+			failures = 0
+			d.Logger.DebugContext(ctx, "Authorization pending", "interval", interval)
+			backoff = interval
+		case errors.Is(err, oidc.ErrSlowDown):
+			failures = 0
+			interval += slowDownIncrement
+			d.Logger.DebugContext(ctx, "Slowing down", "interval", interval)
+			backoff = interval
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case errors.As(err, new(*oidc.TokenError)), failures >= maxFailures:
+			return nil, fmt.Errorf("exchanging device code: %w", err)
+		default:
+			backoff = min(backoff*2, maxInterval)
+			d.Logger.DebugContext(ctx, "Exchanging device code failed, backing off", "err", err, "backoff", backoff)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package devicecode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/neticdk-k8s/ic/internal/oidc"
+	"github.com/neticdk-k8s/ic/internal/testing/issuer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const clientID = "YOUR_CLIENT_ID"
+
+// fakeWait records the waits instead of sleeping
+func fakeWait(t *testing.T) *[]time.Duration {
+	waits := make([]time.Duration, 0)
+	orig := wait
+	wait = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { wait = orig })
+	return &waits
+}
+
+func newOIDCClient(t *testing.T, s *issuer.Server) oidc.Client {
+	f := &oidc.Factory{Logger: slog.Default()}
+	c, err := f.New(context.Background(), oidc.Provider{IssuerURL: s.URL, ClientID: clientID})
+	if err != nil {
+		t.Fatalf("creating oidc client: %s", err)
+	}
+	return c
+}
+
+func TestDeviceCode_Login(t *testing.T) {
+	timeout := 5 * time.Second
+
+	t.Run("Success", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+		defer cancel()
+		waits := fakeWait(t)
+
+		s := issuer.New(t, clientID)
+		s.DeviceAuthorization = map[string]any{
+			"device_code":      "YOUR_DEVICE_CODE",
+			"user_code":        "ABCD-EFGH",
+			"verification_uri": s.URL + "/device/verify",
+			"expires_in":       600,
+			"interval":         2,
+		}
+		s.TokenErrors = []string{"authorization_pending", "slow_down", "authorization_pending"}
+
+		out := new(bytes.Buffer)
+		d := &DeviceCode{Writer: out, Logger: slog.Default()}
+		got, err := d.Login(ctx, &LoginInput{}, newOIDCClient(t, s))
+		assert.NoError(t, err)
+		assert.NotEmpty(t, got.IDToken)
+		assert.Equal(t, "YOUR_REFRESH_TOKEN", got.RefreshToken)
+
+		assert.Contains(t, out.String(), s.URL+"/device/verify")
+		assert.Contains(t, out.String(), "ABCD-EFGH")
+		assert.Equal(t, []time.Duration{2 * time.Second, 2 * time.Second, 7 * time.Second, 7 * time.Second}, *waits)
+
+		requests := s.TokenRequests()
+		assert.Len(t, requests, 4)
+		for _, r := range requests {
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:device_code", r.Get("grant_type"))
+			assert.Equal(t, "YOUR_DEVICE_CODE", r.Get("device_code"))
+			assert.Equal(t, clientID, r.Get("client_id"))
+		}
+	})
+
+	t.Run("VerificationURIComplete", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+		defer cancel()
+		waits := fakeWait(t)
+
+		s := issuer.New(t, clientID)
+		s.DeviceAuthorization = map[string]any{
+			"device_code":               "YOUR_DEVICE_CODE",
+			"user_code":                 "ABCD-EFGH",
+			"verification_uri":          s.URL + "/device/verify",
+			"verification_uri_complete": s.URL + "/device/verify?user_code=ABCD-EFGH",
+		}
+
+		out := new(bytes.Buffer)
+		d := &DeviceCode{Writer: out, Logger: slog.Default()}
+		_, err := d.Login(ctx, &LoginInput{}, newOIDCClient(t, s))
+		assert.NoError(t, err)
+		assert.Contains(t, out.String(), s.URL+"/device/verify?user_code=ABCD-EFGH")
+		// RFC 8628 default interval
+		assert.Equal(t, []time.Duration{5 * time.Second}, *waits)
+	})
+
+	t.Run("AccessDenied", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+		defer cancel()
+		fakeWait(t)
+
+		s := issuer.New(t, clientID)
+		s.DeviceAuthorization = map[string]any{
+			"device_code":      "YOUR_DEVICE_CODE",
+			"user_code":        "ABCD-EFGH",
+			"verification_uri": s.URL + "/device/verify",
+		}
+		s.TokenErrors = []string{"authorization_pending", "access_denied"}
+
+		d := &DeviceCode{Writer: new(bytes.Buffer), Logger: slog.Default()}
+		got, err := d.Login(ctx, &LoginInput{}, newOIDCClient(t, s))
+		assert.Nil(t, got)
+		assert.ErrorContains(t, err, "access_denied")
+		assert.Len(t, s.TokenRequests(), 2)
+	})
+
+	t.Run("NotSupported", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+		defer cancel()
+		fakeWait(t)
+
+		s := issuer.New(t, clientID)
+
+		d := &DeviceCode{Writer: new(bytes.Buffer), Logger: slog.Default()}
+		_, err := d.Login(ctx, &LoginInput{}, newOIDCClient(t, s))
+		assert.ErrorContains(t, err, "does not support the device authorization grant")
+	})
+
+	t.Run("BackoffOnServerError", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+		defer cancel()
+		waits := fakeWait(t)
+
+		mockClient := oidc.NewMockClient(t)
+		mockClient.EXPECT().
+			GetDeviceAuthorization(mock.Anything).
+			Return(&oidc.DeviceAuthorization{
+				DeviceCode:      "YOUR_DEVICE_CODE",
+				UserCode:        "ABCD-EFGH",
+				VerificationURI: "https://issuer.example.com/device",
+				Interval:        20 * time.Second,
+			}, nil)
+		mockClient.EXPECT().
+			ExchangeDeviceCode(mock.Anything, oidc.ExchangeDeviceCodeInput{DeviceCode: "YOUR_DEVICE_CODE"}).
+			Return(nil, errors.New("token request failed with status code: 502")).
+			Times(3)
+		mockClient.EXPECT().
+			ExchangeDeviceCode(mock.Anything, oidc.ExchangeDeviceCodeInput{DeviceCode: "YOUR_DEVICE_CODE"}).
+			Return(&oidc.TokenSet{IDToken: "YOUR_ID_TOKEN"}, nil).
+			Once()
+
+		d := &DeviceCode{Writer: new(bytes.Buffer), Logger: slog.Default()}
+		got, err := d.Login(ctx, &LoginInput{}, mockClient)
+		assert.NoError(t, err)
+		assert.Equal(t, &oidc.TokenSet{IDToken: "YOUR_ID_TOKEN"}, got)
+		assert.Equal(t, []time.Duration{20 * time.Second, 40 * time.Second, maxInterval, maxInterval}, *waits)
+	})
+
+	t.Run("GiveUpAfterMaxFailures", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+		defer cancel()
+		waits := fakeWait(t)
+
+		mockClient := oidc.NewMockClient(t)
+		mockClient.EXPECT().
+			GetDeviceAuthorization(mock.Anything).
+			Return(&oidc.DeviceAuthorization{
+				DeviceCode:      "YOUR_DEVICE_CODE",
+				UserCode:        "ABCD-EFGH",
+				VerificationURI: "https://issuer.example.com/device",
+				Interval:        time.Second,
+			}, nil)
+		mockClient.EXPECT().
+			ExchangeDeviceCode(mock.Anything, mock.Anything).
+			Return(nil, errors.New("verifying id token: bad signature")).
+			Times(maxFailures)
+
+		d := &DeviceCode{Writer: new(bytes.Buffer), Logger: slog.Default()}
+		_, err := d.Login(ctx, &LoginInput{}, mockClient)
+		assert.ErrorContains(t, err, "bad signature")
+		assert.Len(t, *waits, maxFailures)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+		defer cancel()
+
+		mockClient := oidc.NewMockClient(t)
+		mockClient.EXPECT().
+			GetDeviceAuthorization(mock.Anything).
+			Return(&oidc.DeviceAuthorization{
+				DeviceCode:      "YOUR_DEVICE_CODE",
+				UserCode:        "ABCD-EFGH",
+				VerificationURI: "https://issuer.example.com/device",
+				Expiry:          time.Now().Add(50 * time.Millisecond),
+				Interval:        10 * time.Millisecond,
+			}, nil)
+		mockClient.EXPECT().
+			ExchangeDeviceCode(mock.Anything, mock.Anything).
+			Return(nil, oidc.ErrAuthorizationPending).
+			Maybe()
+
+		d := &DeviceCode{Writer: new(bytes.Buffer), Logger: slog.Default()}
+		_, err := d.Login(ctx, &LoginInput{}, mockClient)
+		assert.ErrorContains(t, err, "device code expired")
+	})
+}
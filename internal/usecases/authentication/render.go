@@ -0,0 +1,119 @@
+package authentication
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/neticdk-k8s/ic/internal/render"
+	"github.com/neticdk-k8s/ic/internal/ui"
+)
+
+type statusRenderer struct {
+	render.Base
+	status *StatusResult
+}
+
+// NewStatusRenderer creates a new renderer of an authentication status
+func NewStatusRenderer(status *StatusResult, writer io.Writer) *statusRenderer {
+	sr := &statusRenderer{
+		Base:   render.Base{Writer: writer, Value: status},
+		status: status,
+	}
+	sr.Text = sr.renderText
+	return sr
+}
+
+var nextActionDescriptions = map[string]string{
+	NextActionUseCachedToken: "the cached token is used",
+	NextActionRefreshToken:   "the cached token is refreshed",
+	NextActionLogin:          "login is required",
+	NextActionUseStaticToken: "the static token is used",
+}
+
+func (r *statusRenderer) renderText() error {
+	s := r.status
+	refreshToken := "not cached"
+	if s.HasRefreshToken {
+		refreshToken = "cached"
+	}
+	rows := [][]string{
+		{"Source:", s.Source},
+	}
+	if s.Profile != "" {
+		rows = append(rows, []string{"Profile:", s.Profile})
+	}
+	rows = append(rows,
+		[]string{"Refresh token:", refreshToken},
+		[]string{"Next command:", nextActionDescriptions[s.NextAction]},
+	)
+	if s.HasAudience != nil {
+		rows = append(rows, []string{"API audience:", presence(*s.HasAudience)})
+	}
+	if s.HasWriteRole != nil {
+		rows = append(rows, []string{"Write role:", presence(*s.HasWriteRole)})
+	}
+	ui.RenderKVTable(r.Writer, "Status", rows)
+
+	if s.AccessToken != nil {
+		fmt.Fprintln(r.Writer)
+		ui.RenderKVTable(r.Writer, "Access token", tokenInfoRows(s.AccessToken))
+	}
+	if s.IDToken != nil {
+		fmt.Fprintln(r.Writer)
+		ui.RenderKVTable(r.Writer, "ID token", tokenInfoRows(s.IDToken))
+	}
+
+	return nil
+}
+
+func presence(ok bool) string {
+	if ok {
+		return "present"
+	}
+	return "missing"
+}
+
+func tokenInfoRows(t *TokenInfo) [][]string {
+	rows := [][]string{
+		{"Subject:", t.Subject},
+	}
+	if t.Username != "" {
+		rows = append(rows, []string{"Username:", t.Username})
+	}
+	if t.Email != "" {
+		rows = append(rows, []string{"Email:", t.Email})
+	}
+	rows = append(rows,
+		[]string{"Issuer:", t.Issuer},
+		[]string{"Audience:", strings.Join(t.Audience, ", ")},
+	)
+	if len(t.Roles) > 0 {
+		rows = append(rows, []string{"Roles:", strings.Join(t.Roles, ", ")})
+	}
+	for _, client := range slices.Sorted(maps.Keys(t.ClientRoles)) {
+		rows = append(rows, []string{
+			fmt.Sprintf("Roles (%s):", client),
+			strings.Join(t.ClientRoles[client], ", "),
+		})
+	}
+	if len(t.Groups) > 0 {
+		rows = append(rows, []string{"Groups:", strings.Join(t.Groups, ", ")})
+	}
+	if t.IssuedAt != nil {
+		rows = append(rows, []string{"Issued:", t.IssuedAt.Format(time.RFC3339)})
+	}
+	rows = append(rows, []string{"Expires:", formatExpiry(t)})
+	return rows
+}
+
+func formatExpiry(t *TokenInfo) string {
+	d := time.Duration(t.ExpiresIn) * time.Second
+	if t.Expired {
+		return fmt.Sprintf("%s (expired %s ago)", t.ExpiresAt.Format(time.RFC3339), -d)
+	}
+	return fmt.Sprintf("%s (in %s)", t.ExpiresAt.Format(time.RFC3339), d)
+}
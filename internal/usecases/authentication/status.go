@@ -0,0 +1,171 @@
+package authentication
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/neticdk-k8s/ic/internal/jwt"
+	"github.com/neticdk-k8s/ic/internal/oidc"
+	"github.com/neticdk-k8s/ic/internal/tokencache"
+)
+
+const (
+	// SourceCache means the token is read from the token cache
+	SourceCache = "cache"
+	// SourceStatic means the token is given using --token, --token-file or
+	// IC_TOKEN
+	SourceStatic = "static"
+)
+
+const (
+	// NextActionUseCachedToken means the cached token is used as is
+	NextActionUseCachedToken = "use-cached-token"
+	// NextActionRefreshToken means the cached token is refreshed
+	NextActionRefreshToken = "refresh-token"
+	// NextActionLogin means a new login is required
+	NextActionLogin = "login"
+	// NextActionUseStaticToken means the static token is used
+	NextActionUseStaticToken = "use-static-token"
+)
+
+// StatusInput is the input given to Status
+type StatusInput struct {
+	// Provider represents an OIDC provider configuration
+	Provider oidc.Provider
+	// TokenCache is the interface used for caching tokens
+	TokenCache tokencache.Cache
+	// Profile is the configuration profile used
+	Profile string
+	// StaticToken is used instead of the cached tokens if set
+	StaticToken string
+	// RefreshSkew is how long before expiry a cached token is refreshed
+	RefreshSkew time.Duration
+	// Audience is the audience the inventory server expects in access tokens.
+	// It is not checked if empty
+	Audience string
+	// WriteRole is the role needed to create, update and delete resources. It
+	// is not checked if empty
+	WriteRole string
+}
+
+// TokenInfo describes a decoded token
+type TokenInfo struct {
+	Subject     string              `json:"subject"`
+	Username    string              `json:"username,omitempty"`
+	Email       string              `json:"email,omitempty"`
+	Issuer      string              `json:"issuer,omitempty"`
+	Audience    []string            `json:"audience,omitempty"`
+	Roles       []string            `json:"roles,omitempty"`
+	ClientRoles map[string][]string `json:"client_roles,omitempty"`
+	Groups      []string            `json:"groups,omitempty"`
+	IssuedAt    *time.Time          `json:"issued_at,omitempty"`
+	ExpiresAt   time.Time           `json:"expires_at"`
+	// ExpiresIn is the number of seconds until the token expires. It is
+	// negative for expired tokens
+	ExpiresIn int64 `json:"expires_in"`
+	Expired   bool  `json:"expired"`
+}
+
+// StatusResult is the result of Status
+type StatusResult struct {
+	// LoggedIn is false if no token was found
+	LoggedIn bool `json:"logged_in"`
+	// Source is where the token was found. One of SourceCache or SourceStatic
+	Source  string `json:"source,omitempty"`
+	Profile string `json:"profile,omitempty"`
+	// AccessToken is the decoded access token
+	AccessToken *TokenInfo `json:"access_token,omitempty"`
+	// IDToken is the decoded ID token if one is cached
+	IDToken *TokenInfo `json:"id_token,omitempty"`
+	// HasRefreshToken is true if a refresh token is cached
+	HasRefreshToken bool `json:"has_refresh_token"`
+	// NextAction is what the next command needing authentication will do
+	NextAction string `json:"next_action"`
+	// HasAudience is true if the access token has the audience expected by
+	// the inventory server. It is nil if no audience is given
+	HasAudience *bool `json:"has_audience,omitempty"`
+	// HasWriteRole is true if the access token has the write role. It is nil
+	// if no write role is given or the token carries no roles
+	HasWriteRole *bool `json:"has_write_role,omitempty"`
+}
+
+// Status describes the token used for authentication without performing any
+// authentication
+func Status(in StatusInput) (*StatusResult, error) {
+	result := &StatusResult{
+		Profile:    in.Profile,
+		NextAction: NextActionLogin,
+	}
+
+	tokenSet := &oidc.TokenSet{AccessToken: in.StaticToken}
+	if in.StaticToken != "" {
+		result.Source = SourceStatic
+	} else {
+		result.Source = SourceCache
+		cached, err := in.TokenCache.Lookup(tokencache.NewKey(in.Provider, in.Profile))
+		if err != nil {
+			if errors.Is(err, &tokencache.CacheMissError{}) {
+				return result, nil
+			}
+			return nil, fmt.Errorf("looking up cached token: %w", err)
+		}
+		tokenSet = cached
+	}
+
+	now := time.Now()
+	claims, err := jwt.DecodeWithoutVerify(tokenSet.AccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("decoding access token: %w", err)
+	}
+	accessToken := newTokenInfo(claims, now)
+	result.LoggedIn = true
+	result.AccessToken = accessToken
+	if tokenSet.IDToken != "" {
+		idClaims, err := jwt.DecodeWithoutVerify(tokenSet.IDToken)
+		if err != nil {
+			return nil, fmt.Errorf("decoding id token: %w", err)
+		}
+		result.IDToken = newTokenInfo(idClaims, now)
+	}
+	if in.Audience != "" {
+		hasAudience := claims.HasAudience(in.Audience)
+		result.HasAudience = &hasAudience
+	}
+	if in.WriteRole != "" && claims.HasRoleClaims() {
+		hasWriteRole := claims.HasRealmOrClientRole(in.WriteRole)
+		result.HasWriteRole = &hasWriteRole
+	}
+	result.HasRefreshToken = tokenSet.RefreshToken != ""
+
+	switch {
+	case result.Source == SourceStatic:
+		result.NextAction = NextActionUseStaticToken
+	case accessToken.ExpiresAt.After(now.Add(in.RefreshSkew)):
+		result.NextAction = NextActionUseCachedToken
+	case result.HasRefreshToken:
+		result.NextAction = NextActionRefreshToken
+	}
+
+	return result, nil
+}
+
+func newTokenInfo(claims *jwt.Claims, now time.Time) *TokenInfo {
+	info := &TokenInfo{
+		Subject:     claims.Subject,
+		Username:    claims.PreferredUsername,
+		Email:       claims.Email,
+		Issuer:      claims.Issuer,
+		Audience:    claims.Audience,
+		Roles:       claims.Roles,
+		ClientRoles: claims.ResourceAccess,
+		Groups:      claims.Groups,
+		ExpiresAt:   claims.Expiry,
+		ExpiresIn:   int64(claims.Expiry.Sub(now).Seconds()),
+		Expired:     claims.IsExpired(),
+	}
+	if !claims.IssuedAt.IsZero() {
+		info.IssuedAt = &claims.IssuedAt
+	}
+	return info
+}
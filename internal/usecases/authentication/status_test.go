@@ -0,0 +1,208 @@
+package authentication
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/neticdk-k8s/ic/internal/oidc"
+	testingJWT "github.com/neticdk-k8s/ic/internal/testing/jwt"
+	"github.com/neticdk-k8s/ic/internal/tokencache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus(t *testing.T) {
+	provider := oidc.Provider{
+		IssuerURL: "https://issuer.example.com",
+		ClientID:  "YOUR_CLIENT_ID",
+	}
+	encode := func(expiresAt time.Time) string {
+		return testingJWT.EncodeF(t, func(claims *testingJWT.Claims) {
+			claims.Issuer = "https://issuer.example.com"
+			claims.Subject = "YOUR_SUBJECT"
+			claims.Audience = []string{"YOUR_CLIENT_ID"}
+			claims.Groups = []string{"admins"}
+			claims.PreferredUsername = "jdoe"
+			claims.ResourceAccess = map[string]testingJWT.Access{
+				"inventory-api": {Roles: []string{"inventory-write"}},
+			}
+			claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+		})
+	}
+	validToken := encode(time.Now().Add(time.Hour))
+	expiredToken := encode(time.Now().Add(-time.Hour))
+
+	lookup := func(t *testing.T, tokenSet *oidc.TokenSet, err error) tokencache.Cache {
+		c := tokencache.NewMockCache(t)
+		c.EXPECT().
+			Lookup(tokencache.NewKey(provider, "staging")).
+			Return(tokenSet, err)
+		return c
+	}
+
+	t.Run("CachedToken", func(t *testing.T) {
+		got, err := Status(StatusInput{
+			Provider:   provider,
+			TokenCache: lookup(t, &oidc.TokenSet{AccessToken: validToken, IDToken: validToken, RefreshToken: "YOUR_REFRESH_TOKEN"}, nil),
+			Profile:    "staging",
+		})
+		assert.NoError(t, err)
+		assert.True(t, got.LoggedIn)
+		assert.Equal(t, SourceCache, got.Source)
+		assert.Equal(t, "staging", got.Profile)
+		assert.Equal(t, NextActionUseCachedToken, got.NextAction)
+		assert.True(t, got.HasRefreshToken)
+		assert.Equal(t, "YOUR_SUBJECT", got.AccessToken.Subject)
+		assert.Equal(t, "https://issuer.example.com", got.AccessToken.Issuer)
+		assert.Equal(t, []string{"YOUR_CLIENT_ID"}, got.AccessToken.Audience)
+		assert.Equal(t, []string{"admins"}, got.AccessToken.Groups)
+		assert.Equal(t, "jdoe", got.AccessToken.Username)
+		assert.Equal(t, map[string][]string{"inventory-api": {"inventory-write"}}, got.AccessToken.ClientRoles)
+		assert.False(t, got.AccessToken.Expired)
+		assert.InDelta(t, 3600, got.AccessToken.ExpiresIn, 5)
+		assert.NotNil(t, got.IDToken)
+	})
+
+	t.Run("ExpiredWithRefreshToken", func(t *testing.T) {
+		got, err := Status(StatusInput{
+			Provider:   provider,
+			TokenCache: lookup(t, &oidc.TokenSet{AccessToken: expiredToken, RefreshToken: "YOUR_REFRESH_TOKEN"}, nil),
+			Profile:    "staging",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, NextActionRefreshToken, got.NextAction)
+		assert.True(t, got.AccessToken.Expired)
+		assert.Less(t, got.AccessToken.ExpiresIn, int64(0))
+		assert.Nil(t, got.IDToken)
+	})
+
+	t.Run("ExpiresWithinRefreshSkew", func(t *testing.T) {
+		got, err := Status(StatusInput{
+			Provider:    provider,
+			TokenCache:  lookup(t, &oidc.TokenSet{AccessToken: encode(time.Now().Add(30 * time.Second)), RefreshToken: "YOUR_REFRESH_TOKEN"}, nil),
+			Profile:     "staging",
+			RefreshSkew: time.Minute,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, NextActionRefreshToken, got.NextAction)
+		assert.False(t, got.AccessToken.Expired)
+	})
+
+	t.Run("ExpiredWithoutRefreshToken", func(t *testing.T) {
+		got, err := Status(StatusInput{
+			Provider:   provider,
+			TokenCache: lookup(t, &oidc.TokenSet{AccessToken: expiredToken}, nil),
+			Profile:    "staging",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, NextActionLogin, got.NextAction)
+		assert.False(t, got.HasRefreshToken)
+	})
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		got, err := Status(StatusInput{
+			Provider:   provider,
+			TokenCache: lookup(t, nil, &tokencache.CacheMissError{}),
+			Profile:    "staging",
+		})
+		assert.NoError(t, err)
+		assert.False(t, got.LoggedIn)
+		assert.Equal(t, NextActionLogin, got.NextAction)
+	})
+
+	t.Run("CacheError", func(t *testing.T) {
+		_, err := Status(StatusInput{
+			Provider:   provider,
+			TokenCache: lookup(t, nil, errors.New("permission denied")),
+			Profile:    "staging",
+		})
+		assert.ErrorContains(t, err, "permission denied")
+	})
+
+	t.Run("StaticToken", func(t *testing.T) {
+		got, err := Status(StatusInput{
+			Provider:    provider,
+			TokenCache:  tokencache.NewMockCache(t),
+			StaticToken: validToken,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, SourceStatic, got.Source)
+		assert.Equal(t, NextActionUseStaticToken, got.NextAction)
+		assert.Equal(t, "YOUR_SUBJECT", got.AccessToken.Subject)
+	})
+	t.Run("AudienceAndWriteRole", func(t *testing.T) {
+		got, err := Status(StatusInput{
+			Provider:    provider,
+			TokenCache:  tokencache.NewMockCache(t),
+			StaticToken: validToken,
+			Audience:    "inventory-api",
+			WriteRole:   "inventory-write",
+		})
+		assert.NoError(t, err)
+		assert.False(t, *got.HasAudience)
+		assert.True(t, *got.HasWriteRole)
+
+		got, err = Status(StatusInput{
+			Provider:    provider,
+			TokenCache:  tokencache.NewMockCache(t),
+			StaticToken: validToken,
+		})
+		assert.NoError(t, err)
+		assert.Nil(t, got.HasAudience)
+		assert.Nil(t, got.HasWriteRole)
+	})
+}
+
+func TestStatusRenderer(t *testing.T) {
+	hasWriteRole := false
+	status := &StatusResult{
+		LoggedIn:        true,
+		Source:          SourceCache,
+		HasRefreshToken: true,
+		NextAction:      NextActionRefreshToken,
+		HasWriteRole:    &hasWriteRole,
+		AccessToken: &TokenInfo{
+			Subject:  "YOUR_SUBJECT",
+			Email:    "user@example.com",
+			Issuer:   "https://issuer.example.com",
+			Audience: []string{"inventory-cli", "inventory-api"},
+			Username: "jdoe",
+			Roles:    []string{"inventory-write"},
+			ClientRoles: map[string][]string{
+				"inventory-api": {"cluster-admin"},
+			},
+			ExpiresAt: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+			ExpiresIn: -90,
+			Expired:   true,
+		},
+	}
+
+	t.Run("Text", func(t *testing.T) {
+		got := new(bytes.Buffer)
+		assert.NoError(t, NewStatusRenderer(status, got).Render("plain"))
+		assert.Contains(t, got.String(), "user@example.com")
+		assert.Contains(t, got.String(), "inventory-cli, inventory-api")
+		assert.Contains(t, got.String(), "inventory-write")
+		assert.Contains(t, got.String(), "jdoe")
+		assert.Contains(t, got.String(), "Roles (inventory-api):")
+		assert.Contains(t, got.String(), "cluster-admin")
+		assert.Contains(t, got.String(), "2026-01-01T12:00:00Z (expired 1m30s ago)")
+		assert.Contains(t, got.String(), "the cached token is refreshed")
+		assert.Regexp(t, `Write role:\s+missing`, got.String())
+		assert.NotContains(t, got.String(), "API audience:")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		got := new(bytes.Buffer)
+		assert.NoError(t, NewStatusRenderer(status, got).Render("json"))
+		assert.Contains(t, got.String(), `"next_action": "refresh-token"`)
+		assert.Contains(t, got.String(), `"expires_in": -90`)
+		assert.Contains(t, got.String(), `"has_write_role": false`)
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		assert.Error(t, NewStatusRenderer(status, new(bytes.Buffer)).Render("xml"))
+	})
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/neticdk-k8s/ic/internal/apiclient"
+	"github.com/neticdk-k8s/ic/internal/render"
 	"github.com/neticdk/go-common/pkg/qsparser"
 )
 
@@ -17,25 +20,60 @@ type capacity struct {
 	MemoryBytes int64 `json:"memory_bytes,omitempty"`
 }
 
+// Node roles of the capacity reported for a cluster
+const (
+	roleControlPlane = "control-plane"
+	roleWorker       = "worker"
+)
+
+// Names of the collections linked from a cluster
+const (
+	LinkComponents      = "components"
+	LinkImages          = "images"
+	LinkNamespaces      = "namespaces"
+	LinkPods            = "pods"
+	LinkVulnerabilities = "vulnerabilities"
+	LinkWorkloads       = "workloads"
+)
+
+// linkNames are the names of the linked collections in the order they are
+// rendered
+var linkNames = []string{LinkComponents, LinkImages, LinkNamespaces, LinkPods, LinkVulnerabilities, LinkWorkloads}
+
+// linkedCollection is a collection linked from a cluster. Total and Error
+// are only set if the link has been followed
+type linkedCollection struct {
+	Link  string `json:"link"`
+	Total *int64 `json:"total,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
 type clusterResponse struct {
-	ID                     string    `json:"id,omitempty"`
-	Name                   string    `json:"name,omitempty"`
-	ProviderName           string    `json:"provider_name,omitempty"`
-	NRN                    string    `json:"nrn,omitempty"`
-	Description            string    `json:"description,omitempty"`
-	ClusterType            string    `json:"cluster_type,omitempty"`
-	Partition              string    `json:"partition,omitempty"`
-	Region                 string    `json:"region,omitempty"`
-	EnvironmentName        string    `json:"environment_name,omitempty"`
-	ResilienceZone         string    `json:"resilience_zone,omitempty"`
-	KubernetesProvider     string    `json:"kubernetes_provider,omitempty"`
-	InfrastructureProvider string    `json:"infrastructure_provider,omitempty"`
-	KubernetesVersion      string    `json:"kubernetes_version,omitempty"`
-	SubscriptionName       string    `json:"subscription_name,omitempty"`
-	CustomerName           string    `json:"customer_name,omitempty"`
-	ClientVersion          string    `json:"client_version,omitempty"`
-	ControlPlaneCapacity   *capacity `json:"control_plane_capacity,omitempty"`
-	WorkerNodesCapacity    *capacity `json:"worker_nodes_capacity,omitempty"`
+	ID                     string                       `json:"id,omitempty"`
+	Name                   string                       `json:"name,omitempty"`
+	ProviderName           string                       `json:"provider_name,omitempty"`
+	NRN                    string                       `json:"nrn,omitempty"`
+	FQDN                   string                       `json:"fqdn,omitempty"`
+	Description            string                       `json:"description,omitempty"`
+	ClusterType            string                       `json:"cluster_type,omitempty"`
+	Partition              string                       `json:"partition,omitempty"`
+	Region                 string                       `json:"region,omitempty"`
+	EnvironmentName        string                       `json:"environment_name,omitempty"`
+	ResilienceZone         string                       `json:"resilience_zone,omitempty"`
+	KubernetesProvider     string                       `json:"kubernetes_provider,omitempty"`
+	InfrastructureProvider string                       `json:"infrastructure_provider,omitempty"`
+	KubernetesVersion      string                       `json:"kubernetes_version,omitempty"`
+	SubscriptionName       string                       `json:"subscription_name,omitempty"`
+	CustomerName           string                       `json:"customer_name,omitempty"`
+	ClientVersion          string                       `json:"client_version,omitempty"`
+	DocsSpace              string                       `json:"docs_space,omitempty"`
+	JiraProject            string                       `json:"jira_project,omitempty"`
+	Created                *time.Time                   `json:"created,omitempty"`
+	Timestamp              *time.Time                   `json:"timestamp,omitempty"`
+	ControlPlaneCapacity   *capacity                    `json:"control_plane_capacity,omitempty"`
+	WorkerNodesCapacity    *capacity                    `json:"worker_nodes_capacity,omitempty"`
+	Capacity               map[string]capacity          `json:"capacity,omitempty"`
+	Links                  map[string]*linkedCollection `json:"links,omitempty"`
 }
 
 type clusterListResponse struct {
@@ -88,6 +126,16 @@ func (cl *ClusterList) ToResponse() *clusterListResponse {
 		if kubernetesVersion, ok := i["kubernetesVersion"].(map[string]any); ok {
 			cr.KubernetesVersion, _ = mapValAs[string](kubernetesVersion, "version")
 		}
+		cr.Partition, _ = mapValAs[string](i, "partition")
+		cr.Region, _ = mapValAs[string](i, "region")
+		cr.InfrastructureProvider, _ = mapValAs[string](i, "infrastructureProvider")
+		cr.KubernetesProvider, _ = mapValAs[string](i, "kubernetesProvider")
+		if clientVersion, ok := i["clientVersion"].(map[string]any); ok {
+			cr.ClientVersion, _ = mapValAs[string](clientVersion, "version")
+		}
+		if c, ok := i["capacity"].(map[string]any); ok && len(c) > 0 {
+			cr.setCapacity(capacityFromMap(c))
+		}
 		clr.Clusters = append(clr.Clusters, cr)
 	}
 	return clr
@@ -109,6 +157,11 @@ type ListClustersInput struct {
 	PerPage int
 	// Filters is a list of search filters to apply
 	Filters map[string]*qsparser.SearchField
+	// SortBy is the field the clusters are sorted by. The order of the
+	// server is kept if nil
+	SortBy *render.Field
+	// Reverse sorts in descending order
+	Reverse bool
 }
 
 // ListClusterResults is the result of ListClusters
@@ -128,11 +181,15 @@ func ListClusters(ctx context.Context, in ListClustersInput) (*ListClusterResult
 	if problem != nil {
 		return &ListClusterResults{nil, nil, problem}, nil
 	}
-	jsonData, err := cl.MarshalJSON()
+	clr := cl.ToResponse()
+	if err := render.SortBy(clr.Clusters, in.SortBy, in.Reverse); err != nil {
+		return nil, fmt.Errorf("sorting clusters: %w", err)
+	}
+	jsonData, err := json.Marshal(clr)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling cluster list: %w", err)
 	}
-	return &ListClusterResults{cl.ToResponse(), jsonData, nil}, nil
+	return &ListClusterResults{clr, jsonData, nil}, nil
 }
 
 func listClusters(ctx context.Context, in *ListClustersInput, clusterList *ClusterList) (*apiclient.Problem, error) { //nolint
@@ -176,6 +233,9 @@ func listClusters(ctx context.Context, in *ListClustersInput, clusterList *Clust
 type GetClusterInput struct {
 	Logger    *slog.Logger
 	APIClient apiclient.ClientWithResponsesInterface
+	// LinkFollower is used to follow the links of the cluster and count the
+	// items of the linked collections. Links are not followed if nil
+	LinkFollower apiclient.LinkFollower
 }
 
 // GetClusterResult is the result of GetCluster
@@ -203,6 +263,9 @@ func GetCluster(ctx context.Context, clusterID string, in GetClusterInput) (*Get
 	}
 
 	cluster := toClusterResponse(response.ApplicationldJSONDefault)
+	if in.LinkFollower != nil {
+		followLinks(ctx, in.Logger, in.LinkFollower, cluster.Links)
+	}
 
 	jsonData, err := json.Marshal(cluster)
 	if err != nil {
@@ -212,6 +275,54 @@ func GetCluster(ctx context.Context, clusterID string, in GetClusterInput) (*Get
 	return &GetClusterResult{cluster, jsonData, nil}, nil
 }
 
+// followLinks requests the linked collections and sets their totals. A
+// collection which cannot be read gets an error rather than failing the
+// whole cluster
+func followLinks(ctx context.Context, logger *slog.Logger, f apiclient.LinkFollower, links map[string]*linkedCollection) {
+	for _, name := range linkNames {
+		lc, ok := links[name]
+		if !ok {
+			continue
+		}
+		total, err := collectionTotal(ctx, logger, f, lc.Link)
+		if err != nil {
+			lc.Error = err.Error()
+			continue
+		}
+		lc.Total = &total
+	}
+}
+
+// collection holds the counts of any collection returned by the api
+type collection struct {
+	Count *int64 `json:"count,omitempty"`
+	Total *int64 `json:"total,omitempty"`
+}
+
+// collectionTotal returns the total number of items in the collection at link
+func collectionTotal(ctx context.Context, logger *slog.Logger, f apiclient.LinkFollower, link string) (int64, error) {
+	response, err := f.FollowLink(ctx, link)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	logger.DebugContext(ctx, "followLink", append(logStatus(response), slog.String("link", link))...)
+	if response.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("bad status code: %d", response.StatusCode)
+	}
+	var c collection
+	if err := json.NewDecoder(io.LimitReader(response.Body, 10<<20)).Decode(&c); err != nil {
+		return 0, fmt.Errorf("decoding collection: %w", err)
+	}
+	switch {
+	case c.Total != nil:
+		return *c.Total, nil
+	case c.Count != nil:
+		return *c.Count, nil
+	}
+	return 0, fmt.Errorf("collection has no count")
+}
+
 // CreateClusterInput is the input used by CreateCluster()
 type CreateClusterInput struct {
 	Logger                   *slog.Logger
@@ -458,6 +569,11 @@ type ListClusterNodesInput struct {
 	Filters map[string]*qsparser.SearchField
 	// ClusterName is the name of the cluster
 	ClusterName string
+	// SortBy is the field the nodes are sorted by. The order of the
+	// server is kept if nil
+	SortBy *render.Field
+	// Reverse sorts in descending order
+	Reverse bool
 }
 
 // ListClusterNodesResults is the result of ListClusterNodes()
@@ -477,11 +593,15 @@ func ListClusterNodes(ctx context.Context, in ListClusterNodesInput) (*ListClust
 	if problem != nil {
 		return &ListClusterNodesResults{nil, nil, problem}, nil
 	}
-	jsonData, err := nl.MarshalJSON()
+	nlr := nl.ToResponse()
+	if err := render.SortBy(nlr.Nodes, in.SortBy, in.Reverse); err != nil {
+		return nil, fmt.Errorf("sorting cluster nodes: %w", err)
+	}
+	jsonData, err := json.Marshal(nlr)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling cluster list: %w", err)
 	}
-	return &ListClusterNodesResults{nl.ToResponse(), jsonData, nil}, nil
+	return &ListClusterNodesResults{nlr, jsonData, nil}, nil
 }
 
 func listClusterNodes(ctx context.Context, in *ListClusterNodesInput, nodeList *ClusterNodesList) (*apiclient.Problem, error) { //nolint
@@ -563,6 +683,144 @@ func GetClusterNode(ctx context.Context, in GetClusterNodeInput) (*GetClusterNod
 	return &GetClusterNodeResult{node, jsonData, nil}, nil
 }
 
+type clusterPodResponse struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	NodeName  string `json:"node_name,omitempty"`
+	Owner     string `json:"owner,omitempty"`
+}
+
+type clusterPodsListResponse struct {
+	Pods []clusterPodResponse `json:"pods,omitempty"`
+}
+
+// ClusterPodsList is a list of pods running in a cluster
+type ClusterPodsList struct { //nolint
+	// Included is a list of included items
+	Included []map[string]any
+	// Pods is a list of pods
+	Pods []string
+}
+
+func (pl *ClusterPodsList) ToResponse() *clusterPodsListResponse {
+	cplr := &clusterPodsListResponse{
+		Pods: make([]clusterPodResponse, 0),
+	}
+	includeMap := make(map[string]any)
+	for _, i := range pl.Included {
+		if v, ok := mapValAs[string](i, "@id"); ok {
+			includeMap[v] = i
+		}
+	}
+	for _, i := range pl.Included {
+		if v, ok := mapValAs[string](i, "@type"); ok {
+			if v != "Pod" {
+				continue
+			}
+		}
+		pr := clusterPodResponse{}
+		pr.Name, _ = mapValAs[string](i, "name")
+		pr.Namespace, _ = mapValAs[string](i, "namespace")
+		pr.NodeName, _ = mapValAs[string](i, "nodeName")
+		if ownerID, ok := mapValAs[string](i, "owner"); ok {
+			pr.Owner = ownerID
+			if owner, ok := includeMap[ownerID]; ok {
+				if o, ok := owner.(map[string]any); ok {
+					kind, _ := mapValAs[string](o, "kind")
+					name, _ := mapValAs[string](o, "name")
+					if kind != "" && name != "" {
+						pr.Owner = fmt.Sprintf("%s/%s", kind, name)
+					}
+				}
+			}
+		}
+
+		cplr.Pods = append(cplr.Pods, pr)
+	}
+	return cplr
+}
+
+func (pl *ClusterPodsList) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pl.ToResponse())
+}
+
+// ListClusterPodsInput is the input given to ListClusterPods()
+type ListClusterPodsInput struct {
+	// Logger is a logger
+	Logger *slog.Logger
+	// APIClient is the inventory server API client used to make requests
+	APIClient apiclient.ClientWithResponsesInterface
+	// Page is the initial page (0-based index)
+	Page int
+	// PerPage is the number of items requested for each page
+	PerPage int
+	// Filters is a list of search filters to apply
+	Filters map[string]*qsparser.SearchField
+	// ClusterID is the id of the cluster
+	ClusterID string
+}
+
+// ListClusterPodsResults is the result of ListClusterPods()
+type ListClusterPodsResults struct {
+	ClusterPodListResponse *clusterPodsListResponse
+	JSONResponse           []byte
+	Problem                *apiclient.Problem
+}
+
+// ListClusterPods returns a non-paginated list of pods running in a cluster
+func ListClusterPods(ctx context.Context, in ListClusterPodsInput) (*ListClusterPodsResults, error) {
+	pl := &ClusterPodsList{}
+	problem, err := listClusterPods(ctx, &in, pl)
+	if err != nil {
+		return nil, fmt.Errorf("listing cluster pods: %w", err)
+	}
+	if problem != nil {
+		return &ListClusterPodsResults{nil, nil, problem}, nil
+	}
+	jsonData, err := pl.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("marshaling cluster pod list: %w", err)
+	}
+	return &ListClusterPodsResults{pl.ToResponse(), jsonData, nil}, nil
+}
+
+func listClusterPods(ctx context.Context, in *ListClusterPodsInput, podList *ClusterPodsList) (*apiclient.Problem, error) { //nolint
+	nextPage := func(_ context.Context, req *http.Request) error {
+		sp := qsparser.SearchParams{
+			Page:    &in.Page,
+			PerPage: &in.PerPage,
+			Fields:  in.Filters,
+		}
+		sp.SetRawQuery(req)
+		return nil
+	}
+	response, err := in.APIClient.ListPodsWithResponse(ctx, in.ClusterID, nextPage)
+	if err != nil {
+		return nil, fmt.Errorf("reading cluster pod list: %w", err)
+	}
+	in.Logger.DebugContext(ctx, "listPods", logStatus(response.HTTPResponse)...)
+	switch response.StatusCode() {
+	case http.StatusOK:
+	case http.StatusBadRequest:
+		return response.ApplicationproblemJSON400, nil
+	case http.StatusUnauthorized:
+		return response.ApplicationproblemJSON401, nil
+	default:
+		return nil, fmt.Errorf("bad status code: %d", response.StatusCode())
+	}
+	if response.ApplicationldJSONDefault.Pods != nil {
+		podList.Pods = append(podList.Pods, *response.ApplicationldJSONDefault.Pods...)
+	}
+	if response.ApplicationldJSONDefault.Included != nil {
+		podList.Included = append(podList.Included, *response.ApplicationldJSONDefault.Included...)
+	}
+	if response.ApplicationldJSONDefault.Pagination != nil && response.ApplicationldJSONDefault.Pagination.Next != nil {
+		in.Page++
+		return listClusterPods(ctx, in, podList)
+	}
+	return nil, nil
+}
+
 // GetClusterKubeConfigInput is the input used by GetClusterKubeConfig()
 type GetClusterKubeConfigInput struct {
 	Logger    *slog.Logger
@@ -596,16 +854,50 @@ func GetClusterKubeConfig(ctx context.Context, in GetClusterKubeConfigInput) (*G
 	return &GetClusterKubeConfigResult{response.Body, nil}, nil
 }
 
+// setCapacity sets the capacity per node role
+func (cr *clusterResponse) setCapacity(caps map[string]capacity) {
+	cr.Capacity = caps
+	if c, ok := caps[roleControlPlane]; ok {
+		cr.ControlPlaneCapacity = &c
+	}
+	if c, ok := caps[roleWorker]; ok {
+		cr.WorkerNodesCapacity = &c
+	}
+}
+
+// capacityFromMap returns the capacity per node role of an included cluster
+func capacityFromMap(m map[string]any) map[string]capacity {
+	caps := make(map[string]capacity, len(m))
+	for role, v := range m {
+		c, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		nodes, _ := mapValAs[float64](c, "nodes")
+		coresMillis, _ := mapValAs[float64](c, "coresMillis")
+		memoryBytes, _ := mapValAs[float64](c, "memoryBytes")
+		caps[role] = capacity{
+			NodeCount:   int64(nodes),
+			CoresMillis: int64(coresMillis),
+			MemoryBytes: int64(memoryBytes),
+		}
+	}
+	return caps
+}
+
 func toClusterResponse(cluster *apiclient.Cluster) *clusterResponse {
 	includeMap := make(map[string]any)
-	for _, i := range *cluster.Included {
-		if v, ok := mapValAs[string](i, "@id"); ok {
-			includeMap[v] = i
+	if cluster.Included != nil {
+		for _, i := range *cluster.Included {
+			if v, ok := mapValAs[string](i, "@id"); ok {
+				includeMap[v] = i
+			}
 		}
 	}
 	cr := &clusterResponse{}
 	cr.Name = nilStr(cluster.Name)
 	cr.NRN = nilStr(cluster.Nrn)
+	cr.FQDN = nilStr(cluster.Fqdn)
 	cr.Description = nilStr(cluster.Description)
 	cr.Partition = nilStr(cluster.Partition)
 	cr.Region = nilStr(cluster.Region)
@@ -613,24 +905,42 @@ func toClusterResponse(cluster *apiclient.Cluster) *clusterResponse {
 	cr.InfrastructureProvider = nilStr(cluster.InfrastructureProvider)
 	cr.ClusterType = nilStr(cluster.ClusterType)
 	cr.KubernetesProvider = nilStr(cluster.KubernetesProvider)
+	cr.DocsSpace = nilStr(cluster.DocsSpace)
+	cr.JiraProject = nilStr(cluster.JiraProject)
+	cr.Created = cluster.Created
+	cr.Timestamp = cluster.Timestamp
 	if cluster.KubernetesVersion != nil {
-		cr.KubernetesVersion = *cluster.KubernetesVersion.Version
+		cr.KubernetesVersion = nilStr(cluster.KubernetesVersion.Version)
 	}
 	if cluster.ClientVersion != nil {
-		cr.ClientVersion = *cluster.ClientVersion.Version
-	}
-	if cluster.Capacity != nil {
-		cpct := *cluster.Capacity
-		cr.ControlPlaneCapacity = &capacity{
-			NodeCount:   *cpct["control-plane"].Nodes,
-			CoresMillis: *cpct["control-plane"].CoresMillis,
-			MemoryBytes: *cpct["control-plane"].MemoryBytes,
+		cr.ClientVersion = nilStr(cluster.ClientVersion.Version)
+	}
+	if cluster.Capacity != nil && len(*cluster.Capacity) > 0 {
+		caps := make(map[string]capacity, len(*cluster.Capacity))
+		for role, c := range *cluster.Capacity {
+			caps[role] = capacity{
+				NodeCount:   nilInt64(c.Nodes),
+				CoresMillis: nilInt64(c.CoresMillis),
+				MemoryBytes: nilInt64(c.MemoryBytes),
+			}
+		}
+		cr.setCapacity(caps)
+	}
+	for name, link := range map[string]*string{
+		LinkComponents:      cluster.Components,
+		LinkImages:          cluster.Images,
+		LinkNamespaces:      cluster.Namespaces,
+		LinkPods:            cluster.Pods,
+		LinkVulnerabilities: cluster.Vulnerabilities,
+		LinkWorkloads:       cluster.Workloads,
+	} {
+		if link == nil || *link == "" {
+			continue
 		}
-		cr.WorkerNodesCapacity = &capacity{
-			NodeCount:   *cpct["worker"].Nodes,
-			CoresMillis: *cpct["worker"].CoresMillis,
-			MemoryBytes: *cpct["worker"].MemoryBytes,
+		if cr.Links == nil {
+			cr.Links = make(map[string]*linkedCollection)
 		}
+		cr.Links[name] = &linkedCollection{Link: *link}
 	}
 	if cluster.Provider != nil {
 		if provider, ok := includeMap[*cluster.Provider]; ok {
@@ -2,9 +2,12 @@ package cluster
 
 import (
 	"context"
+	"io"
 	"log/slog"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/neticdk-k8s/ic/internal/apiclient"
 	"github.com/stretchr/testify/assert"
@@ -59,6 +62,35 @@ func TestClusterList_ToResponse(t *testing.T) {
 		assert.Equal(t, want, got)
 	})
 
+	t.Run("Capacity", func(t *testing.T) {
+		cl.Clusters = []string{"my-cluster"}
+		cl.Included = []map[string]any{
+			{
+				"@id":       "my-cluster-id",
+				"@type":     "Cluster",
+				"name":      "my-cluster",
+				"partition": "netic",
+				"region":    "dk-north",
+				"clientVersion": map[string]any{
+					"version": "v2.0.0",
+				},
+				"capacity": map[string]any{
+					"control-plane": map[string]any{"nodes": float64(3), "coresMillis": float64(6000), "memoryBytes": float64(12884901888)},
+					"worker":        map[string]any{"nodes": float64(5), "coresMillis": float64(40000)},
+				},
+			},
+		}
+		got := cl.ToResponse()
+		if assert.Len(t, got.Clusters, 1) {
+			c := got.Clusters[0]
+			assert.Equal(t, "netic", c.Partition)
+			assert.Equal(t, "dk-north", c.Region)
+			assert.Equal(t, "v2.0.0", c.ClientVersion)
+			assert.Equal(t, &capacity{NodeCount: 3, CoresMillis: 6000, MemoryBytes: 12884901888}, c.ControlPlaneCapacity)
+			assert.Equal(t, &capacity{NodeCount: 5, CoresMillis: 40000}, c.WorkerNodesCapacity)
+		}
+	})
+
 	t.Run("Empty input", func(t *testing.T) {
 		cl.Clusters = []string{""}
 		cl.Included = []map[string]any{}
@@ -259,6 +291,92 @@ func TestGetCluster(t *testing.T) {
 	assert.Equal(t, wantJSON, got.JSONResponse)
 }
 
+// linkFollowerFunc is a LinkFollower returning the response of a function
+type linkFollowerFunc func(link string) (*http.Response, error)
+
+func (f linkFollowerFunc) FollowLink(_ context.Context, link string, _ ...apiclient.RequestEditorFn) (*http.Response, error) {
+	return f(link)
+}
+
+func TestGetCluster_AllFields(t *testing.T) {
+	logger := slog.Default()
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	cluster := &apiclient.Cluster{
+		Name:        ptr("my-cluster"),
+		Fqdn:        ptr("my-cluster.example.com"),
+		DocsSpace:   ptr("MYDOCS"),
+		JiraProject: ptr("MYJIRA"),
+		Created:     &created,
+		Timestamp:   &created,
+		Capacity: &map[string]apiclient.Capacity{
+			"control-plane": {Nodes: ptr(int64(3)), CoresMillis: ptr(int64(6000)), MemoryBytes: ptr(int64(1 << 30))},
+			"worker":        {Nodes: ptr(int64(5))},
+			"gpu":           {Nodes: ptr(int64(1))},
+		},
+		Pods:   ptr("/clusters/my-cluster/pods"),
+		Images: ptr("/clusters/my-cluster/images"),
+	}
+
+	newMockClient := func(t *testing.T) *apiclient.MockClientWithResponsesInterface {
+		mockClient := apiclient.NewMockClientWithResponsesInterface(t)
+		mockClient.EXPECT().
+			GetClusterWithResponse(mock.Anything, mock.Anything).
+			Return(
+				&apiclient.GetClusterResponse{
+					HTTPResponse:             &http.Response{StatusCode: http.StatusOK},
+					ApplicationldJSONDefault: cluster,
+				}, nil)
+		return mockClient
+	}
+
+	t.Run("WithoutFollowingLinks", func(t *testing.T) {
+		got, err := GetCluster(context.TODO(), "my-cluster", GetClusterInput{Logger: logger, APIClient: newMockClient(t)})
+		assert.NoError(t, err)
+		want := &clusterResponse{
+			Name:                 "my-cluster",
+			FQDN:                 "my-cluster.example.com",
+			DocsSpace:            "MYDOCS",
+			JiraProject:          "MYJIRA",
+			Created:              &created,
+			Timestamp:            &created,
+			ControlPlaneCapacity: &capacity{NodeCount: 3, CoresMillis: 6000, MemoryBytes: 1 << 30},
+			WorkerNodesCapacity:  &capacity{NodeCount: 5},
+			Capacity: map[string]capacity{
+				"control-plane": {NodeCount: 3, CoresMillis: 6000, MemoryBytes: 1 << 30},
+				"worker":        {NodeCount: 5},
+				"gpu":           {NodeCount: 1},
+			},
+			Links: map[string]*linkedCollection{
+				LinkPods:   {Link: "/clusters/my-cluster/pods"},
+				LinkImages: {Link: "/clusters/my-cluster/images"},
+			},
+		}
+		assert.Equal(t, want, got.ClusterResponse)
+	})
+
+	t.Run("FollowingLinks", func(t *testing.T) {
+		in := GetClusterInput{
+			Logger:    logger,
+			APIClient: newMockClient(t),
+			LinkFollower: linkFollowerFunc(func(link string) (*http.Response, error) {
+				if link == "/clusters/my-cluster/images" {
+					return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))}, nil
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"count":50,"total":120}`))}, nil
+			}),
+		}
+		got, err := GetCluster(context.TODO(), "my-cluster", in)
+		assert.NoError(t, err)
+		assert.Equal(t, ptr(int64(120)), got.ClusterResponse.Links[LinkPods].Total)
+		assert.Equal(t, "bad status code: 404", got.ClusterResponse.Links[LinkImages].Error)
+		assert.Contains(t, string(got.JSONResponse), `"pods":{"link":"/clusters/my-cluster/pods","total":120}`)
+	})
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
 func TestNodeList_ToResponse(t *testing.T) {
 	cl := ClusterList{
 		Clusters: make([]string, 0),
@@ -552,3 +670,116 @@ users:
 	assert.NoError(t, err)
 	assert.Equal(t, want, got.Response)
 }
+
+func TestClusterPodsList_ToResponse(t *testing.T) {
+	pl := ClusterPodsList{
+		Pods:     make([]string, 0),
+		Included: make([]map[string]any, 0),
+	}
+	t.Run("Valid input", func(t *testing.T) {
+		pl.Pods = []string{"my-pod-id"}
+		pl.Included = []map[string]any{
+			{
+				"@id":       "my-deployment-id",
+				"@type":     "Resource",
+				"kind":      "Deployment",
+				"name":      "my-deployment",
+				"namespace": "my-namespace",
+			},
+			{
+				"@id":       "my-pod-id",
+				"@type":     "Pod",
+				"name":      "my-pod",
+				"namespace": "my-namespace",
+				"nodeName":  "my-node",
+				"owner":     "my-deployment-id",
+			},
+			{
+				"@id":       "my-other-pod-id",
+				"@type":     "Pod",
+				"name":      "my-other-pod",
+				"namespace": "my-namespace",
+				"owner":     "unknown-owner-id",
+			},
+		}
+		want := &clusterPodsListResponse{
+			Pods: []clusterPodResponse{
+				{
+					Name:      "my-pod",
+					Namespace: "my-namespace",
+					NodeName:  "my-node",
+					Owner:     "Deployment/my-deployment",
+				},
+				{
+					Name:      "my-other-pod",
+					Namespace: "my-namespace",
+					Owner:     "unknown-owner-id",
+				},
+			},
+		}
+		got := pl.ToResponse()
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Empty input", func(t *testing.T) {
+		pl.Pods = []string{""}
+		pl.Included = []map[string]any{}
+		want := &clusterPodsListResponse{[]clusterPodResponse{}}
+		got := pl.ToResponse()
+		assert.Equal(t, want, got)
+	})
+}
+
+func TestListClusterPods(t *testing.T) {
+	logger := slog.Default()
+
+	pods := []string{"my-pod-id"}
+	included := []map[string]any{
+		{
+			"@id":       "my-pod-id",
+			"@type":     "Pod",
+			"name":      "my-pod",
+			"namespace": "my-namespace",
+			"nodeName":  "my-node",
+		},
+	}
+
+	mockClient := apiclient.NewMockClientWithResponsesInterface(t)
+	mockClient.EXPECT().
+		ListPodsWithResponse(mock.Anything, "my-cluster.my-provider", mock.Anything).
+		Return(
+			&apiclient.ListPodsResponse{
+				Body: make([]byte, 0),
+				HTTPResponse: &http.Response{
+					Status:     "200 OK",
+					StatusCode: 200,
+				},
+				ApplicationldJSONDefault: &apiclient.Pods{
+					Pods:       &pods,
+					Included:   &included,
+					Pagination: &apiclient.Pagination{},
+				},
+			}, nil)
+	in := ListClusterPodsInput{
+		Logger:    logger,
+		APIClient: mockClient,
+		ClusterID: "my-cluster.my-provider",
+	}
+
+	want := &clusterPodsListResponse{
+		Pods: []clusterPodResponse{
+			{
+				Name:      "my-pod",
+				Namespace: "my-namespace",
+				NodeName:  "my-node",
+			},
+		},
+	}
+
+	got, err := ListClusterPods(context.TODO(), in)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got.ClusterPodListResponse)
+
+	wantJSON := []byte(`{"pods":[{"name":"my-pod","namespace":"my-namespace","node_name":"my-node"}]}`)
+	assert.Equal(t, wantJSON, got.JSONResponse)
+}
@@ -0,0 +1,317 @@
+package cluster
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/neticdk-k8s/ic/internal/apiclient"
+	"golang.org/x/sync/errgroup"
+	"sigs.k8s.io/yaml"
+)
+
+// kubeconfig fields holding base64 encoded data which the API expects as
+// byte arrays
+var (
+	kubeConfigClusterDataFields = []string{"certificate-authority-data"}
+	kubeConfigUserDataFields    = []string{"client-certificate-data", "client-key-data"}
+)
+
+var kubeConfigExecInteractiveModes = []string{"Never", "IfAvailable", "Always"}
+
+// ParseKubeConfig parses a kubeconfig document in yaml or json format
+func ParseKubeConfig(data []byte) (*apiclient.KubeConfig, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing kubeconfig: %w", err)
+	}
+	doc := make(map[string]any)
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		return nil, fmt.Errorf("parsing kubeconfig: %w", err)
+	}
+	if err := decodeKubeConfigData(doc, "clusters", "cluster", kubeConfigClusterDataFields); err != nil {
+		return nil, err
+	}
+	if err := decodeKubeConfigData(doc, "users", "user", kubeConfigUserDataFields); err != nil {
+		return nil, err
+	}
+	jsonData, err = json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("parsing kubeconfig: %w", err)
+	}
+	kubeConfig := &apiclient.KubeConfig{}
+	if err := json.Unmarshal(jsonData, kubeConfig); err != nil {
+		return nil, fmt.Errorf("parsing kubeconfig: %w", err)
+	}
+	return kubeConfig, nil
+}
+
+// decodeKubeConfigData replaces base64 encoded fields of the named list
+// entries with their decoded values
+func decodeKubeConfigData(doc map[string]any, listKey, itemKey string, fields []string) error {
+	list, ok := mapValAs[[]any](doc, listKey)
+	if !ok {
+		return nil
+	}
+	for _, e := range list {
+		named, ok := e.(map[string]any)
+		if !ok {
+			continue
+		}
+		item, ok := mapValAs[map[string]any](named, itemKey)
+		if !ok {
+			continue
+		}
+		for _, f := range fields {
+			v, ok := mapValAs[string](item, f)
+			if !ok {
+				continue
+			}
+			b, err := base64.StdEncoding.DecodeString(v)
+			if err != nil {
+				name, _ := mapValAs[string](named, "name")
+				return fmt.Errorf("decoding %s of %s %q: %w", f, itemKey, name, err)
+			}
+			item[f] = toInt8s(b)
+		}
+	}
+	return nil
+}
+
+// ValidateKubeConfig checks that a kubeconfig is complete and consistent
+func ValidateKubeConfig(kc *apiclient.KubeConfig) error {
+	var errs []error
+
+	clusters := make([]string, 0)
+	if kc.Clusters == nil || len(*kc.Clusters) == 0 {
+		errs = append(errs, errors.New("no clusters defined"))
+	} else {
+		for _, c := range *kc.Clusters {
+			name := nilStr(c.Name)
+			if name == "" {
+				errs = append(errs, errors.New("cluster without name"))
+				continue
+			}
+			clusters = append(clusters, name)
+			errs = append(errs, validateKubeConfigCluster(name, c.Cluster)...)
+		}
+	}
+
+	users := make([]string, 0)
+	if kc.Users != nil {
+		for _, u := range *kc.Users {
+			name := nilStr(u.Name)
+			if name == "" {
+				errs = append(errs, errors.New("user without name"))
+				continue
+			}
+			users = append(users, name)
+			errs = append(errs, validateKubeConfigUser(name, u.User)...)
+		}
+	}
+
+	contexts := make([]string, 0)
+	if kc.Contexts != nil {
+		for _, c := range *kc.Contexts {
+			name := nilStr(c.Name)
+			if name == "" {
+				errs = append(errs, errors.New("context without name"))
+				continue
+			}
+			contexts = append(contexts, name)
+			if c.Context == nil {
+				errs = append(errs, fmt.Errorf("context %q: missing context", name))
+				continue
+			}
+			if cluster := nilStr(c.Context.Cluster); !slices.Contains(clusters, cluster) {
+				errs = append(errs, fmt.Errorf("context %q: unknown cluster %q", name, cluster))
+			}
+			if user := nilStr(c.Context.User); user != "" && !slices.Contains(users, user) {
+				errs = append(errs, fmt.Errorf("context %q: unknown user %q", name, user))
+			}
+		}
+	}
+
+	if current := nilStr(kc.CurrentContext); current != "" && !slices.Contains(contexts, current) {
+		errs = append(errs, fmt.Errorf("current-context: unknown context %q", current))
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateKubeConfigCluster(name string, c *apiclient.KubeConfigCluster) []error {
+	if c == nil {
+		return []error{fmt.Errorf("cluster %q: missing cluster", name)}
+	}
+	var errs []error
+	server := nilStr(c.Server)
+	if u, err := url.Parse(server); err != nil || u.Scheme != "https" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("cluster %q: server %q must be a https URL", name, server))
+	}
+	if c.CertificateAuthorityData != nil && !isPEMCertificates(fromInt8s(*c.CertificateAuthorityData)) {
+		errs = append(errs, fmt.Errorf("cluster %q: certificate-authority-data must contain PEM encoded certificates", name))
+	}
+	return errs
+}
+
+func validateKubeConfigUser(name string, u *apiclient.KubeConfigAuthInfo) []error {
+	if u == nil || u.Exec == nil {
+		return nil
+	}
+	var errs []error
+	if nilStr(u.Exec.Command) == "" {
+		errs = append(errs, fmt.Errorf("user %q: exec command must be set", name))
+	}
+	if nilStr(u.Exec.ApiVersion) == "" {
+		errs = append(errs, fmt.Errorf("user %q: exec apiVersion must be set", name))
+	}
+	if mode := nilStr(u.Exec.InteractiveMode); mode != "" && !slices.Contains(kubeConfigExecInteractiveModes, mode) {
+		errs = append(errs, fmt.Errorf("user %q: exec interactiveMode %q must be one of %v", name, mode, kubeConfigExecInteractiveModes))
+	}
+	if u.Exec.Env != nil {
+		for _, e := range *u.Exec.Env {
+			if nilStr(e.Name) == "" {
+				errs = append(errs, fmt.Errorf("user %q: exec env variable without name", name))
+			}
+		}
+	}
+	return errs
+}
+
+func isPEMCertificates(data []byte) bool {
+	found := false
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return false
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+func toInt8s(b []byte) []int8 {
+	r := make([]int8, len(b))
+	for i, v := range b {
+		r[i] = int8(v)
+	}
+	return r
+}
+
+func fromInt8s(i []int8) []byte {
+	r := make([]byte, len(i))
+	for n, v := range i {
+		r[n] = byte(v)
+	}
+	return r
+}
+
+// UpdateClusterKubeConfigInput is the input used by UpdateClusterKubeConfig()
+type UpdateClusterKubeConfigInput struct {
+	Logger     *slog.Logger
+	APIClient  apiclient.ClientWithResponsesInterface
+	ClusterID  string
+	KubeConfig *apiclient.KubeConfig
+}
+
+// UpdateClusterKubeConfigResult is the result of UpdateClusterKubeConfig()
+type UpdateClusterKubeConfigResult struct {
+	Problem *apiclient.Problem
+}
+
+// UpdateClusterKubeConfig replaces the kubeconfig for a cluster
+func UpdateClusterKubeConfig(ctx context.Context, in UpdateClusterKubeConfigInput) (*UpdateClusterKubeConfigResult, error) {
+	response, err := in.APIClient.UpdateClusterKubeConfigWithResponse(ctx, in.ClusterID, *in.KubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("updating cluster kubeconfig: %w", err)
+	}
+	in.Logger.DebugContext(ctx, "updateClusterKubeconfig", logStatus(response.HTTPResponse)...)
+	switch response.StatusCode() {
+	case http.StatusOK, http.StatusNoContent:
+	case http.StatusBadRequest:
+		return &UpdateClusterKubeConfigResult{response.ApplicationproblemJSON400}, nil
+	case http.StatusUnauthorized:
+		return &UpdateClusterKubeConfigResult{response.ApplicationproblemJSON401}, nil
+	case http.StatusForbidden:
+		return &UpdateClusterKubeConfigResult{response.ApplicationproblemJSON403}, nil
+	case http.StatusNotFound:
+		return &UpdateClusterKubeConfigResult{response.ApplicationproblemJSON404}, nil
+	case http.StatusInternalServerError:
+		return &UpdateClusterKubeConfigResult{response.ApplicationproblemJSON500}, nil
+	default:
+		return nil, fmt.Errorf("bad status code: %d", response.StatusCode())
+	}
+	return &UpdateClusterKubeConfigResult{nil}, nil
+}
+
+// GetClusterKubeConfigsInput is the input used by GetClusterKubeConfigs()
+type GetClusterKubeConfigsInput struct {
+	Logger    *slog.Logger
+	APIClient apiclient.ClientWithResponsesInterface
+	// ClusterIDs is the list of clusters to get kubeconfigs for
+	ClusterIDs []string
+	// Concurrency is the maximum number of concurrent requests
+	Concurrency int
+}
+
+// ClusterKubeConfig is the kubeconfig of a single cluster or the reason why
+// it could not be fetched
+type ClusterKubeConfig struct {
+	ClusterID string
+	Response  []byte
+	Problem   *apiclient.Problem
+	Err       error
+}
+
+// GetClusterKubeConfigsResult is the result of GetClusterKubeConfigs()
+type GetClusterKubeConfigsResult struct {
+	// KubeConfigs is in the same order as the cluster ids given
+	KubeConfigs []ClusterKubeConfig
+}
+
+// GetClusterKubeConfigs returns kubeconfigs for a list of clusters. A failure
+// to get a single kubeconfig does not stop the others from being fetched
+func GetClusterKubeConfigs(ctx context.Context, in GetClusterKubeConfigsInput) (*GetClusterKubeConfigsResult, error) {
+	result := &GetClusterKubeConfigsResult{
+		KubeConfigs: make([]ClusterKubeConfig, len(in.ClusterIDs)),
+	}
+	eg := errgroup.Group{}
+	eg.SetLimit(max(in.Concurrency, 1))
+	for i, clusterID := range in.ClusterIDs {
+		eg.Go(func() error {
+			kc := ClusterKubeConfig{ClusterID: clusterID}
+			r, err := GetClusterKubeConfig(ctx, GetClusterKubeConfigInput{
+				Logger:    in.Logger,
+				APIClient: in.APIClient,
+				ClusterID: clusterID,
+			})
+			if err != nil {
+				kc.Err = err
+			} else {
+				kc.Response, kc.Problem = r.Response, r.Problem
+			}
+			result.KubeConfigs[i] = kc
+			return nil
+		})
+	}
+	_ = eg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("getting cluster kubeconfigs: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,225 @@
+package cluster
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/neticdk-k8s/ic/internal/apiclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: my-cluster
+  cluster:
+    server: https://my-cluster.example.com:6443
+    certificate-authority-data: %s
+contexts:
+- name: my-context
+  context:
+    cluster: my-cluster
+    user: my-user
+current-context: my-context
+users:
+- name: my-user
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: kubectl
+      args:
+      - oidc-login
+      - get-token
+      env:
+      - name: MY_VAR
+        value: my-value
+      interactiveMode: IfAvailable
+`
+
+func testCACert(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "my-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestParseKubeConfig(t *testing.T) {
+	ca := testCACert(t)
+	data := fmt.Sprintf(testKubeConfig, base64.StdEncoding.EncodeToString(ca))
+
+	got, err := ParseKubeConfig([]byte(data))
+	require.NoError(t, err)
+	require.Len(t, *got.Clusters, 1)
+	cluster := (*got.Clusters)[0].Cluster
+	assert.Equal(t, "https://my-cluster.example.com:6443", *cluster.Server)
+	assert.Equal(t, ca, fromInt8s(*cluster.CertificateAuthorityData))
+	exec := (*got.Users)[0].User.Exec
+	assert.Equal(t, "kubectl", *exec.Command)
+	assert.Equal(t, []string{"oidc-login", "get-token"}, *exec.Args)
+	assert.Equal(t, "MY_VAR", *(*exec.Env)[0].Name)
+	assert.Equal(t, "IfAvailable", *exec.InteractiveMode)
+	assert.Equal(t, "my-context", *got.CurrentContext)
+	assert.NoError(t, ValidateKubeConfig(got))
+
+	t.Run("invalid base64", func(t *testing.T) {
+		_, err := ParseKubeConfig([]byte(fmt.Sprintf(testKubeConfig, "not base64!")))
+		assert.ErrorContains(t, err, "decoding certificate-authority-data of cluster \"my-cluster\"")
+	})
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		_, err := ParseKubeConfig([]byte("clusters: [\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateKubeConfig(t *testing.T) {
+	testCases := []struct {
+		name   string
+		data   string
+		expErr string
+	}{
+		{
+			name:   "no clusters",
+			data:   "apiVersion: v1\nkind: Config\n",
+			expErr: "no clusters defined",
+		},
+		{
+			name:   "server not https",
+			data:   "clusters:\n- name: c\n  cluster:\n    server: http://c.example.com\n",
+			expErr: "must be a https URL",
+		},
+		{
+			name:   "ca data not a certificate",
+			data:   "clusters:\n- name: c\n  cluster:\n    server: https://c.example.com\n    certificate-authority-data: " + base64.StdEncoding.EncodeToString([]byte("garbage")) + "\n",
+			expErr: "must contain PEM encoded certificates",
+		},
+		{
+			name:   "context with unknown cluster",
+			data:   "clusters:\n- name: c\n  cluster:\n    server: https://c.example.com\ncontexts:\n- name: ctx\n  context:\n    cluster: other\n",
+			expErr: "unknown cluster \"other\"",
+		},
+		{
+			name:   "unknown current context",
+			data:   "clusters:\n- name: c\n  cluster:\n    server: https://c.example.com\ncurrent-context: ctx\n",
+			expErr: "unknown context \"ctx\"",
+		},
+		{
+			name:   "exec without command",
+			data:   "clusters:\n- name: c\n  cluster:\n    server: https://c.example.com\nusers:\n- name: u\n  user:\n    exec:\n      apiVersion: client.authentication.k8s.io/v1\n",
+			expErr: "exec command must be set",
+		},
+		{
+			name:   "exec with invalid interactive mode",
+			data:   "clusters:\n- name: c\n  cluster:\n    server: https://c.example.com\nusers:\n- name: u\n  user:\n    exec:\n      apiVersion: client.authentication.k8s.io/v1\n      command: kubectl\n      interactiveMode: Sometimes\n",
+			expErr: "interactiveMode \"Sometimes\"",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			kc, err := ParseKubeConfig([]byte(tc.data))
+			require.NoError(t, err)
+			assert.ErrorContains(t, ValidateKubeConfig(kc), tc.expErr)
+		})
+	}
+}
+
+func TestUpdateClusterKubeConfig(t *testing.T) {
+	logger := slog.Default()
+	server := "https://my-cluster.example.com:6443"
+	kubeConfig := &apiclient.KubeConfig{
+		Clusters: &[]apiclient.KubeConfigNamedCluster{
+			{
+				Name:    &server,
+				Cluster: &apiclient.KubeConfigCluster{Server: &server},
+			},
+		},
+	}
+
+	mockClient := apiclient.NewMockClientWithResponsesInterface(t)
+	mockClient.EXPECT().
+		UpdateClusterKubeConfigWithResponse(mock.Anything, "my-cluster.my-provider", *kubeConfig).
+		Return(
+			&apiclient.UpdateClusterKubeConfigResponse{
+				Body: make([]byte, 0),
+				HTTPResponse: &http.Response{
+					Status:     "204 No Content",
+					StatusCode: 204,
+				},
+			}, nil)
+
+	in := UpdateClusterKubeConfigInput{
+		Logger:     logger,
+		APIClient:  mockClient,
+		ClusterID:  "my-cluster.my-provider",
+		KubeConfig: kubeConfig,
+	}
+	got, err := UpdateClusterKubeConfig(context.TODO(), in)
+	assert.NoError(t, err)
+	assert.Nil(t, got.Problem)
+}
+
+func TestGetClusterKubeConfigs(t *testing.T) {
+	logger := slog.Default()
+	mockClient := apiclient.NewMockClientWithResponsesInterface(t)
+	for _, id := range []string{"a.my-provider", "b.my-provider"} {
+		mockClient.EXPECT().
+			GetClusterKubeConfigWithResponse(mock.Anything, id).
+			Return(
+				&apiclient.GetClusterKubeConfigResponse{
+					Body: []byte("kubeconfig " + id),
+					HTTPResponse: &http.Response{
+						Status:     "200 OK",
+						StatusCode: 200,
+					},
+				}, nil)
+	}
+	title := "Not Found"
+	mockClient.EXPECT().
+		GetClusterKubeConfigWithResponse(mock.Anything, "c.my-provider").
+		Return(
+			&apiclient.GetClusterKubeConfigResponse{
+				HTTPResponse: &http.Response{
+					Status:     "404 Not Found",
+					StatusCode: 404,
+				},
+				ApplicationproblemJSON404: &apiclient.Problem{Title: &title},
+			}, nil)
+
+	in := GetClusterKubeConfigsInput{
+		Logger:      logger,
+		APIClient:   mockClient,
+		ClusterIDs:  []string{"a.my-provider", "b.my-provider", "c.my-provider"},
+		Concurrency: 2,
+	}
+	got, err := GetClusterKubeConfigs(context.TODO(), in)
+	require.NoError(t, err)
+	require.Len(t, got.KubeConfigs, 3)
+	assert.Equal(t, ClusterKubeConfig{ClusterID: "a.my-provider", Response: []byte("kubeconfig a.my-provider")}, got.KubeConfigs[0])
+	assert.Equal(t, ClusterKubeConfig{ClusterID: "b.my-provider", Response: []byte("kubeconfig b.my-provider")}, got.KubeConfigs[1])
+	assert.Equal(t, "c.my-provider", got.KubeConfigs[2].ClusterID)
+	assert.Equal(t, &title, got.KubeConfigs[2].Problem.Title)
+}
@@ -3,63 +3,81 @@ package cluster
 import (
 	"fmt"
 	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/neticdk-k8s/ic/internal/render"
 	"github.com/neticdk-k8s/ic/internal/ui"
 	"sigs.k8s.io/yaml"
 )
 
+// Sections of a rendered cluster
 const (
-	FormatJson  = "json"
-	FormatTable = "table"
-	FormatPlain = "plain"
+	SectionBase     = "base"
+	SectionCapacity = "capacity"
+	SectionLinks    = "links"
+	// SectionAll selects all sections
+	SectionAll = "all"
 )
 
-type Renderer interface {
-	// Render renders the cluster
-	Render(format string) error
-}
+// Sections are the sections of a rendered cluster
+var Sections = []string{SectionBase, SectionCapacity, SectionLinks}
 
-type renderer struct {
-	data   []byte
-	writer io.Writer
-}
+// DefaultSections are the sections rendered if none are given
+var DefaultSections = []string{SectionBase, SectionCapacity}
 
 type clusterRenderer struct {
-	renderer
-	cluster *clusterResponse
+	render.Base
+	cluster  *clusterResponse
+	sections []string
 }
 
-// NewClusterRenderer creates a new renderer of a single cluster
-func NewClusterRenderer(cluster *clusterResponse, jsonData []byte, writer io.Writer) *clusterRenderer {
+// NewClusterRenderer creates a new renderer of a single cluster. sections are
+// the sections rendered as text. DefaultSections are rendered if empty
+func NewClusterRenderer(cluster *clusterResponse, jsonData []byte, writer io.Writer, sections []string) *clusterRenderer {
+	if len(sections) == 0 {
+		sections = DefaultSections
+	}
+	if slices.Contains(sections, SectionAll) {
+		sections = Sections
+	}
 	cr := &clusterRenderer{
-		renderer: renderer{
-			data:   jsonData,
-			writer: writer,
-		},
-		cluster: cluster,
+		Base:     render.Base{Writer: writer, JSON: jsonData, Value: cluster},
+		cluster:  cluster,
+		sections: sections,
+	}
+	cr.Text = cr.renderText
+	cr.Names = func() []string {
+		return []string{cluster.ID}
 	}
 	return cr
 }
 
-// Render renders the cluster
-func (r *clusterRenderer) Render(format string) error {
-	switch format {
-	case FormatJson:
-		return r.renderJSON()
-	case FormatPlain, FormatTable:
-		return r.renderText()
-	default:
-		return fmt.Errorf("unknown format: %s", format)
+func (r *clusterRenderer) renderText() error {
+	for _, section := range r.sections {
+		switch section {
+		case SectionBase:
+			r.renderBase()
+		case SectionCapacity:
+			r.renderCapacity()
+		case SectionLinks:
+			r.renderLinks()
+		default:
+			return fmt.Errorf("unknown section: %s", section)
+		}
 	}
+	return nil
 }
 
-func (r *clusterRenderer) renderText() error {
+func (r *clusterRenderer) renderBase() {
 	data := [][]string{
 		{"ID:", fmt.Sprintf("%s.%s", r.cluster.Name, r.cluster.ProviderName)},
 		{"Name:", r.cluster.Name},
 		{"Provider:", r.cluster.ProviderName},
 		{"NRN:", r.cluster.NRN},
+		{"FQDN:", r.cluster.FQDN},
 		{"Description:", r.cluster.Description},
 		{"Type:", r.cluster.ClusterType},
 		{"Partition:", r.cluster.Partition},
@@ -70,180 +88,248 @@ func (r *clusterRenderer) renderText() error {
 		{"Kubernetes Provider:", r.cluster.KubernetesProvider},
 		{"Kubernetes Version:", r.cluster.KubernetesVersion},
 		{"Client Version:", r.cluster.ClientVersion},
+		{"Docs Space:", r.cluster.DocsSpace},
+		{"Jira Project:", r.cluster.JiraProject},
+		{"Created:", formatTime(r.cluster.Created)},
+		{"Last Updated:", formatTime(r.cluster.Timestamp)},
 	}
-	ui.RenderKVTable(r.writer, "Base Information", data)
+	ui.RenderKVTable(r.Writer, "Base Information", data)
+}
 
-	if r.cluster.ControlPlaneCapacity != nil {
-		allocMem, unit := render.BytesToBinarySI(r.cluster.ControlPlaneCapacity.MemoryBytes)
-		data = [][]string{
-			{"Nodes:", fmt.Sprintf("%d", r.cluster.ControlPlaneCapacity.NodeCount)},
-			{"Allocatable CPU:", fmt.Sprintf("%dm", r.cluster.ControlPlaneCapacity.CoresMillis)},
+// renderCapacity renders the capacity of each node role. Control plane and
+// worker nodes come first
+func (r *clusterRenderer) renderCapacity() {
+	roles := slices.SortedFunc(maps.Keys(r.cluster.Capacity), func(a, b string) int {
+		return strings.Compare(capacityRoleOrder(a), capacityRoleOrder(b))
+	})
+	for _, role := range roles {
+		c := r.cluster.Capacity[role]
+		allocMem, unit := render.BytesToBinarySI(c.MemoryBytes)
+		data := [][]string{
+			{"Nodes:", fmt.Sprintf("%d", c.NodeCount)},
+			{"Allocatable CPU:", fmt.Sprintf("%dm", c.CoresMillis)},
 			{"Allocatable Memory:", fmt.Sprintf("%.f%s", allocMem, unit)},
 		}
-		ui.RenderKVTable(r.writer, "Control Plane Capacity", data)
+		ui.RenderKVTable(r.Writer, capacityTitle(role), data)
 	}
+}
 
-	if r.cluster.WorkerNodesCapacity != nil {
-		allocMem, unit := render.BytesToBinarySI(r.cluster.WorkerNodesCapacity.MemoryBytes)
-		data = [][]string{
-			{"Nodes:", fmt.Sprintf("%d", r.cluster.WorkerNodesCapacity.NodeCount)},
-			{"Allocatable CPU:", fmt.Sprintf("%dm", r.cluster.WorkerNodesCapacity.CoresMillis)},
-			{"Allocatable Memory:", fmt.Sprintf("%.f%s", allocMem, unit)},
-		}
-		ui.RenderKVTable(r.writer, "Worker Nodes Capacity", data)
+func capacityRoleOrder(role string) string {
+	switch role {
+	case roleControlPlane:
+		return "0"
+	case roleWorker:
+		return "1"
 	}
+	return "2" + role
+}
 
-	return nil
+func capacityTitle(role string) string {
+	switch role {
+	case roleControlPlane:
+		return "Control Plane Capacity"
+	case roleWorker:
+		return "Worker Nodes Capacity"
+	}
+	return fmt.Sprintf("Capacity (%s)", role)
 }
 
-func (r *clusterRenderer) renderJSON() error {
-	return render.PrettyPrintJSON(r.data, r.writer)
+// renderLinks renders the linked collections with the number of items if
+// the links have been followed
+func (r *clusterRenderer) renderLinks() {
+	var data [][]string
+	for _, name := range linkNames {
+		lc, ok := r.cluster.Links[name]
+		if !ok {
+			continue
+		}
+		summary := lc.Link
+		switch {
+		case lc.Error != "":
+			summary = fmt.Sprintf("%s (%s)", lc.Link, lc.Error)
+		case lc.Total != nil:
+			summary = fmt.Sprintf("%d (%s)", *lc.Total, lc.Link)
+		}
+		data = append(data, []string{strings.ToUpper(name[:1]) + name[1:] + ":", summary})
+	}
+	if len(data) == 0 {
+		return
+	}
+	ui.RenderKVTable(r.Writer, "Linked Collections", data)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
 }
 
 type clustersRenderer struct {
-	renderer
-	noHeaders bool
-	clusters  *clusterListResponse
+	render.Base
+	clusters *clusterListResponse
 }
 
 // NewClustersRenderer creates a new renderer for a list of clusters
 func NewClustersRenderer(clusters *clusterListResponse, jsonData []byte, writer io.Writer, noHeaders bool) *clustersRenderer {
 	cr := &clustersRenderer{
-		renderer: renderer{
-			writer: writer,
-			data:   jsonData,
-		},
-		noHeaders: noHeaders,
-		clusters:  clusters,
+		Base:     render.Base{Writer: writer, NoHeaders: noHeaders, JSON: jsonData, Value: clusters, Items: clusters.Clusters},
+		clusters: clusters,
+	}
+	cr.Table = cr.table
+	cr.Names = func() []string {
+		names := make([]string, 0, len(clusters.Clusters))
+		for _, c := range clusters.Clusters {
+			names = append(names, c.ID)
+		}
+		return names
 	}
 	return cr
 }
 
-// Render renders the cluster list
-func (r *clustersRenderer) Render(format string) error {
-	switch format {
-	case FormatJson:
-		return r.renderJSON()
-	case FormatPlain, FormatTable:
-		return r.renderTable()
-	default:
-		return fmt.Errorf("unknown format: %s", format)
-	}
+var clusterColumns = []render.Column{
+	{Name: "provider"},
+	{Name: "id"},
+	{Name: "rz"},
+	{Name: "version"},
+	{Name: "name", Optional: true},
+	{Name: "type", Optional: true},
+	{Name: "environment", Optional: true},
+	{Name: "partition", Optional: true},
+	{Name: "region", Optional: true},
+	{Name: "infrastructure-provider", Optional: true},
+	{Name: "kubernetes-provider", Optional: true},
+	{Name: "client-version", Optional: true},
+	{Name: "cp-nodes", Optional: true},
+	{Name: "cp-cpu", Optional: true},
+	{Name: "cp-memory", Optional: true},
+	{Name: "worker-nodes", Optional: true},
+	{Name: "worker-cpu", Optional: true},
+	{Name: "worker-memory", Optional: true},
 }
 
-func (r *clustersRenderer) renderTable() error {
-	var headers []string
-	if !r.noHeaders {
-		headers = []string{"provider", "id", "rz", "version"}
-	}
-	table := ui.NewTable(r.writer, headers)
+func (r *clustersRenderer) table() *render.Table {
+	t := &render.Table{Columns: clusterColumns}
 	for _, c := range r.clusters.Clusters {
-		table.Append(
-			[]string{
-				c.ProviderName,
-				fmt.Sprintf("%s.%s", c.Name, c.ProviderName),
-				c.ResilienceZone,
-				c.KubernetesVersion,
-			},
-		)
+		row := []string{
+			c.ProviderName,
+			c.ID,
+			c.ResilienceZone,
+			c.KubernetesVersion,
+			c.Name,
+			c.ClusterType,
+			c.EnvironmentName,
+			c.Partition,
+			c.Region,
+			c.InfrastructureProvider,
+			c.KubernetesProvider,
+			c.ClientVersion,
+		}
+		row = append(row, capacityValues(c.ControlPlaneCapacity)...)
+		row = append(row, capacityValues(c.WorkerNodesCapacity)...)
+		t.Rows = append(t.Rows, row)
 	}
-	table.Render()
-	return nil
+	return t
 }
 
-func (r *clustersRenderer) renderJSON() error {
-	return render.PrettyPrintJSON(r.data, r.writer)
+// capacityValues returns the number of nodes, CPU and memory of c or empty
+// values if nil
+func capacityValues(c *capacity) []string {
+	if c == nil {
+		return []string{"", "", ""}
+	}
+	mem, unit := render.BytesToBinarySI(c.MemoryBytes)
+	return []string{
+		fmt.Sprintf("%d", c.NodeCount),
+		fmt.Sprintf("%dm", c.CoresMillis),
+		fmt.Sprintf("%.f%s", mem, unit),
+	}
 }
 
 type clusterNodesRenderer struct {
-	renderer
-	noHeaders bool
-	nodes     *clusterNodesListResponse
+	render.Base
+	nodes *clusterNodesListResponse
 }
 
 // NewClusterNodesRenderer creates a new renderer for a list of cluster nodes
 func NewClusterNodesRenderer(nodes *clusterNodesListResponse, jsonData []byte, writer io.Writer, noHeaders bool) *clusterNodesRenderer {
 	cnr := &clusterNodesRenderer{
-		renderer: renderer{
-			writer: writer,
-			data:   jsonData,
-		},
-		noHeaders: noHeaders,
-		nodes:     nodes,
+		Base:  render.Base{Writer: writer, NoHeaders: noHeaders, JSON: jsonData, Value: nodes, Items: nodes.Nodes},
+		nodes: nodes,
+	}
+	cnr.Table = cnr.table
+	cnr.Names = func() []string {
+		names := make([]string, 0, len(nodes.Nodes))
+		for _, n := range nodes.Nodes {
+			names = append(names, n.Name)
+		}
+		return names
 	}
 	return cnr
 }
 
-// Render renders the cluster node list
-func (r *clusterNodesRenderer) Render(format string) error {
-	switch format {
-	case FormatJson:
-		return r.renderJSON()
-	case FormatPlain, FormatTable:
-		return r.renderTable()
-	default:
-		return fmt.Errorf("unknown format: %s", format)
-	}
+var clusterNodeColumns = []render.Column{
+	{Name: "name"},
+	{Name: "cp"},
+	{Name: "kubelet"},
+	{Name: "cpu-alloc", Header: "cpu (alloc)"},
+	{Name: "mem-alloc", Header: "mem (alloc)"},
+	{Name: "cpu-cap", Header: "cpu (cap)"},
+	{Name: "mem-cap", Header: "mem (cap)"},
+	{Name: "role", Optional: true},
+	{Name: "kube-proxy", Optional: true},
+	{Name: "kernel", Optional: true},
+	{Name: "cri", Optional: true},
+	{Name: "cri-version", Optional: true},
+	{Name: "container-runtime", Optional: true},
+	{Name: "provider", Optional: true},
+	{Name: "region", Optional: true},
+	{Name: "zone", Optional: true},
 }
 
-func (r *clusterNodesRenderer) renderTable() error {
-	var headers []string
-	if !r.noHeaders {
-		headers = []string{"name", "cp", "kubelet", "cpu (alloc)", "mem (alloc)", "cpu (cap)", "mem (cap)"}
-	}
-	table := ui.NewTable(r.writer, headers)
+func (r *clusterNodesRenderer) table() *render.Table {
+	t := &render.Table{Columns: clusterNodeColumns}
 	for _, n := range r.nodes.Nodes {
 		allocMem, allocMemUnit := render.BytesToBinarySI(int64(n.AllocatableMemoryBytes))
 		capMem, capMemUnit := render.BytesToBinarySI(int64(n.CapacityMemoryBytes))
-		table.Append(
-			[]string{
-				n.Name,
-				fmt.Sprintf("%t", n.IsControlPlane),
-				n.KubeletVersion,
-				fmt.Sprintf("%dm", int64(n.AllocatableCPUMillis)),
-				fmt.Sprintf("%.f%s", allocMem, allocMemUnit),
-				fmt.Sprintf("%dm", int64(n.CapacityCPUMillis)),
-				fmt.Sprintf("%.f%s", capMem, capMemUnit),
-			},
-		)
+		t.Rows = append(t.Rows, []string{
+			n.Name,
+			fmt.Sprintf("%t", n.IsControlPlane),
+			n.KubeletVersion,
+			fmt.Sprintf("%dm", int64(n.AllocatableCPUMillis)),
+			fmt.Sprintf("%.f%s", allocMem, allocMemUnit),
+			fmt.Sprintf("%dm", int64(n.CapacityCPUMillis)),
+			fmt.Sprintf("%.f%s", capMem, capMemUnit),
+			n.Role,
+			n.KubeProxyVersion,
+			n.KernelVersion,
+			n.CRIName,
+			n.CRIVersion,
+			n.ContainerRuntimeVersion,
+			n.Provider,
+			n.TopologyRegion,
+			n.TopologyZone,
+		})
 	}
-	table.Render()
-	return nil
-}
-
-func (r *clusterNodesRenderer) renderJSON() error {
-	return render.PrettyPrintJSON(r.data, r.writer)
+	return t
 }
 
 type clusterNodeRenderer struct {
-	renderer
+	render.Base
 	node *clusterNodeResponse
 }
 
 // NewClusterNodeRenderer creates a new renderer of a single cluster node
 func NewClusterNodeRenderer(node *clusterNodeResponse, jsonData []byte, writer io.Writer) *clusterNodeRenderer {
 	cnr := &clusterNodeRenderer{
-		renderer: renderer{
-			data:   jsonData,
-			writer: writer,
-		},
+		Base: render.Base{Writer: writer, JSON: jsonData, Value: node},
 		node: node,
 	}
+	cnr.Text = cnr.renderText
+	cnr.Names = func() []string { return []string{node.Name} }
 	return cnr
 }
 
-// Render renders the cluster node
-func (r *clusterNodeRenderer) Render(format string) error {
-	switch format {
-	case FormatJson:
-		return r.renderJSON()
-	case FormatPlain, FormatTable:
-		return r.renderText()
-	default:
-		return fmt.Errorf("unknown format: %s", format)
-	}
-}
-
 func (r *clusterNodeRenderer) renderText() error {
 	allocMem, allocMemUnit := render.BytesToBinarySI(int64(r.node.AllocatableMemoryBytes))
 	capMem, capMemUnit := render.BytesToBinarySI(int64(r.node.CapacityMemoryBytes))
@@ -265,34 +351,72 @@ func (r *clusterNodeRenderer) renderText() error {
 		{"CPU (Cap)", fmt.Sprintf("%dm", int64(r.node.CapacityCPUMillis))},
 		{"Memory (Cap)", fmt.Sprintf("%.f%s", capMem, capMemUnit)},
 	}
-	ui.RenderKVTable(r.writer, "Node Information", data)
+	ui.RenderKVTable(r.Writer, "Node Information", data)
 
 	return nil
 }
 
-func (r *clusterNodeRenderer) renderJSON() error {
-	return render.PrettyPrintJSON(r.data, r.writer)
+type clusterPodsRenderer struct {
+	render.Base
+	pods *clusterPodsListResponse
+}
+
+// NewClusterPodsRenderer creates a new renderer for a list of cluster pods
+func NewClusterPodsRenderer(pods *clusterPodsListResponse, jsonData []byte, writer io.Writer, noHeaders bool) *clusterPodsRenderer {
+	cpr := &clusterPodsRenderer{
+		Base: render.Base{Writer: writer, NoHeaders: noHeaders, JSON: jsonData, Value: pods, Items: pods.Pods},
+		pods: pods,
+	}
+	cpr.Table = cpr.table
+	cpr.Names = func() []string {
+		names := make([]string, 0, len(pods.Pods))
+		for _, p := range pods.Pods {
+			names = append(names, fmt.Sprintf("%s/%s", p.Namespace, p.Name))
+		}
+		return names
+	}
+	return cpr
+}
+
+var clusterPodColumns = []render.Column{
+	{Name: "namespace"},
+	{Name: "name"},
+	{Name: "node"},
+	{Name: "owner"},
+}
+
+func (r *clusterPodsRenderer) table() *render.Table {
+	t := &render.Table{Columns: clusterPodColumns}
+	for _, p := range r.pods.Pods {
+		t.Rows = append(t.Rows, []string{
+			p.Namespace,
+			p.Name,
+			p.NodeName,
+			p.Owner,
+		})
+	}
+	return t
 }
 
 type clusterKubeConfigRenderer struct {
-	renderer
+	data   []byte
+	writer io.Writer
 }
 
-// NewClusterKubeConfigRenderer creates a new renderer of a cluster kubeconfig
+// NewClusterKubeConfigRenderer creates a new renderer of a cluster kubeconfig.
+// The kubeconfig is printed as is unless JSON is requested
 func NewClusterKubeConfigRenderer(data []byte, writer io.Writer) *clusterKubeConfigRenderer {
 	r := &clusterKubeConfigRenderer{
-		renderer: renderer{
-			data:   data,
-			writer: writer,
-		},
+		data:   data,
+		writer: writer,
 	}
 	return r
 }
 
-// Render renders the cluster node
+// Render renders the cluster kubeconfig
 func (r *clusterKubeConfigRenderer) Render(format string) error {
 	switch format {
-	case FormatJson:
+	case render.FormatJSON:
 		return r.renderJSON()
 	default:
 		render.String(r.data, r.writer)
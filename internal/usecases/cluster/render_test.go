@@ -0,0 +1,63 @@
+package cluster
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/neticdk-k8s/ic/internal/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterRenderer(t *testing.T) {
+	total := int64(120)
+	cluster := &clusterResponse{
+		Name:         "my-cluster",
+		ProviderName: "my-provider",
+		FQDN:         "my-cluster.example.com",
+		JiraProject:  "MYJIRA",
+		Capacity: map[string]capacity{
+			"gpu":           {NodeCount: 1},
+			"worker":        {NodeCount: 5},
+			"control-plane": {NodeCount: 3, CoresMillis: 6000},
+		},
+		Links: map[string]*linkedCollection{
+			LinkPods:   {Link: "/clusters/my-cluster/pods", Total: &total},
+			LinkImages: {Link: "/clusters/my-cluster/images", Error: "bad status code: 404"},
+		},
+	}
+
+	t.Run("DefaultSections", func(t *testing.T) {
+		got := new(bytes.Buffer)
+		assert.NoError(t, NewClusterRenderer(cluster, nil, got, nil).Render(render.FormatPlain))
+		assert.Contains(t, got.String(), "my-cluster.example.com")
+		assert.Contains(t, got.String(), "MYJIRA")
+		assert.NotContains(t, got.String(), "Linked Collections")
+
+		controlPlane := bytes.Index(got.Bytes(), []byte("Control Plane Capacity"))
+		worker := bytes.Index(got.Bytes(), []byte("Worker Nodes Capacity"))
+		gpu := bytes.Index(got.Bytes(), []byte("Capacity (gpu)"))
+		assert.True(t, 0 < controlPlane && controlPlane < worker && worker < gpu, "capacity order")
+	})
+
+	t.Run("Links", func(t *testing.T) {
+		got := new(bytes.Buffer)
+		assert.NoError(t, NewClusterRenderer(cluster, nil, got, []string{SectionLinks}).Render(render.FormatPlain))
+		assert.NotContains(t, got.String(), "Base Information")
+		assert.Contains(t, got.String(), "Linked Collections")
+		assert.Contains(t, got.String(), "120 (/clusters/my-cluster/pods)")
+		assert.Contains(t, got.String(), "/clusters/my-cluster/images (bad status code: 404)")
+	})
+
+	t.Run("All", func(t *testing.T) {
+		got := new(bytes.Buffer)
+		assert.NoError(t, NewClusterRenderer(cluster, nil, got, []string{SectionAll}).Render(render.FormatPlain))
+		assert.Contains(t, got.String(), "Base Information")
+		assert.Contains(t, got.String(), "Worker Nodes Capacity")
+		assert.Contains(t, got.String(), "Linked Collections")
+	})
+
+	t.Run("UnknownSection", func(t *testing.T) {
+		err := NewClusterRenderer(cluster, nil, new(bytes.Buffer), []string{"nodes"}).Render(render.FormatPlain)
+		assert.ErrorContains(t, err, "unknown section")
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 
 	"github.com/neticdk-k8s/ic/internal/apiclient"
+	"github.com/neticdk-k8s/ic/internal/render"
 )
 
 type componentResponse struct {
@@ -101,6 +102,11 @@ type ListComponentsInput struct {
 	Logger *slog.Logger
 	// APIClient is the inventory server API client used to make requests
 	APIClient apiclient.ClientWithResponsesInterface
+	// SortBy is the field the components are sorted by. The order of the
+	// server is kept if nil
+	SortBy *render.Field
+	// Reverse sorts in descending order
+	Reverse bool
 }
 
 // ListComponentResults is the result of ListComponents
@@ -120,11 +126,15 @@ func ListComponents(ctx context.Context, in ListComponentsInput) (*ListComponent
 	if problem != nil {
 		return &ListComponentResults{nil, nil, problem}, nil
 	}
-	jsonData, err := cl.MarshalJSON()
+	clr := cl.ToResponse()
+	if err := render.SortBy(clr.Components, in.SortBy, in.Reverse); err != nil {
+		return nil, fmt.Errorf("sorting components: %w", err)
+	}
+	jsonData, err := json.Marshal(clr)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling component list: %w", err)
 	}
-	return &ListComponentResults{cl.ToResponse(), jsonData, nil}, nil
+	return &ListComponentResults{clr, jsonData, nil}, nil
 }
 
 func listComponents(ctx context.Context, in *ListComponentsInput, componentList *ComponentList) (*apiclient.Problem, error) { //nolint
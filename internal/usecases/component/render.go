@@ -3,48 +3,28 @@ package component
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/neticdk-k8s/ic/internal/render"
 	"github.com/neticdk-k8s/ic/internal/ui"
 )
 
-type Renderer interface {
-	// Render renders the component
-	Render(format string) error
-}
-
-type renderer struct {
-	data   []byte
-	writer io.Writer
-}
-
 type componentRenderer struct {
-	renderer
+	render.Base
 	component *componentResponse
 }
 
 // NewComponentRenderer creates a new renderer of a single component
 func NewComponentRenderer(component *componentResponse, jsonData []byte, writer io.Writer) *componentRenderer {
 	cr := &componentRenderer{
-		renderer: renderer{
-			data:   jsonData,
-			writer: writer,
-		},
+		Base:      render.Base{Writer: writer, JSON: jsonData, Value: component},
 		component: component,
 	}
-	return cr
-}
-
-// Render renders the component
-func (r *componentRenderer) Render(format string) error {
-	switch format {
-	case "json":
-		return r.renderJSON()
-	case "plain", "table":
-		return r.renderText()
-	default:
-		return fmt.Errorf("unknown format: %s", format)
+	cr.Text = cr.renderText
+	cr.Names = func() []string {
+		return []string{fmt.Sprintf("%s/%s", component.Namespace, component.Name)}
 	}
+	return cr
 }
 
 func (r *componentRenderer) renderText() error {
@@ -55,12 +35,12 @@ func (r *componentRenderer) renderText() error {
 		{"Type:", r.component.ComponentType},
 		{"Source:", r.component.Source},
 	}
-	ui.RenderKVTable(r.writer, "Base Information", data)
+	ui.RenderKVTable(r.Writer, "Base Information", data)
 
-	fmt.Fprintln(r.writer)
-	fmt.Fprintln(r.writer, "Resilience Zones:")
+	fmt.Fprintln(r.Writer)
+	fmt.Fprintln(r.Writer, "Resilience Zones:")
 	rzsHeaders := []string{"name", "version"}
-	rzsTable := ui.NewTable(r.writer, rzsHeaders)
+	rzsTable := ui.NewTable(r.Writer, rzsHeaders)
 	for _, c := range r.component.ResilienceZones {
 		rzsTable.Append(
 			[]string{
@@ -71,10 +51,10 @@ func (r *componentRenderer) renderText() error {
 	}
 	rzsTable.Render()
 
-	fmt.Fprintln(r.writer)
-	fmt.Fprintln(r.writer, "Clusters:")
+	fmt.Fprintln(r.Writer)
+	fmt.Fprintln(r.Writer, "Clusters:")
 	clustersHeaders := []string{"name"}
-	clustersTable := ui.NewTable(r.writer, clustersHeaders)
+	clustersTable := ui.NewTable(r.Writer, clustersHeaders)
 	for _, c := range r.component.Clusters {
 		clustersTable.Append([]string{c})
 	}
@@ -83,60 +63,48 @@ func (r *componentRenderer) renderText() error {
 	return nil
 }
 
-func (r *componentRenderer) renderJSON() error {
-	return render.PrettyPrintJSON(r.data, r.writer)
-}
-
 type componentsRenderer struct {
-	renderer
-	noHeaders  bool
+	render.Base
 	components *componentListResponse
 }
 
 // NewComponentsRenderer creates a new renderer for a list of components
 func NewComponentsRenderer(components *componentListResponse, jsonData []byte, writer io.Writer, noHeaders bool) *componentsRenderer {
 	cr := &componentsRenderer{
-		renderer: renderer{
-			writer: writer,
-			data:   jsonData,
-		},
-		noHeaders:  noHeaders,
+		Base:       render.Base{Writer: writer, NoHeaders: noHeaders, JSON: jsonData, Value: components, Items: components.Components},
 		components: components,
 	}
+	cr.Table = cr.table
+	cr.Names = func() []string {
+		names := make([]string, 0, len(components.Components))
+		for _, c := range components.Components {
+			names = append(names, fmt.Sprintf("%s/%s", c.Namespace, c.Name))
+		}
+		return names
+	}
 	return cr
 }
 
-// Render renders the component list
-func (r *componentsRenderer) Render(format string) error {
-	switch format {
-	case "json":
-		return r.renderJSON()
-	case "plain", "table":
-		return r.renderTable()
-	default:
-		return fmt.Errorf("unknown format: %s", format)
-	}
+var componentColumns = []render.Column{
+	{Name: "namespace"},
+	{Name: "name"},
+	{Name: "type"},
+	{Name: "description", Optional: true},
+	{Name: "source", Optional: true},
+	{Name: "clusters", Optional: true},
 }
 
-func (r *componentsRenderer) renderTable() error {
-	var headers []string
-	if !r.noHeaders {
-		headers = []string{"namespace", "name", "type"}
-	}
-	table := ui.NewTable(r.writer, headers)
+func (r *componentsRenderer) table() *render.Table {
+	t := &render.Table{Columns: componentColumns}
 	for _, c := range r.components.Components {
-		table.Append(
-			[]string{
-				c.Namespace,
-				c.Name,
-				c.ComponentType,
-			},
-		)
+		t.Rows = append(t.Rows, []string{
+			c.Namespace,
+			c.Name,
+			c.ComponentType,
+			c.Description,
+			c.Source,
+			strings.Join(c.Clusters, ","),
+		})
 	}
-	table.Render()
-	return nil
-}
-
-func (r *componentsRenderer) renderJSON() error {
-	return render.PrettyPrintJSON(r.data, r.writer)
+	return t
 }
@@ -1,71 +1,31 @@
 package region
 
 import (
-	"encoding/json"
-	"fmt"
 	"io"
 
 	"github.com/neticdk-k8s/ic/internal/render"
-	"github.com/neticdk-k8s/ic/internal/ui"
 )
 
-type Renderer interface {
-	// Render renders the regions
-	Render(format string) error
-}
-
-type renderer struct {
-	writer io.Writer
-}
-
 type regionsRenderer struct {
-	renderer
-	noHeaders bool
-	regions   []string
+	render.Base
+	regions []string
 }
 
 // NewRegionsRenderer creates a new renderer of a list of regions
 func NewRegionsRenderer(regions []string, writer io.Writer, noHeaders bool) *regionsRenderer {
 	cr := &regionsRenderer{
-		renderer: renderer{
-			writer: writer,
-		},
-		noHeaders: noHeaders,
-		regions:   regions,
+		Base:    render.Base{Writer: writer, NoHeaders: noHeaders, Value: regions, Items: regions},
+		regions: regions,
 	}
+	cr.Table = cr.table
+	cr.Names = func() []string { return regions }
 	return cr
 }
 
-// Render renders the regions
-func (r *regionsRenderer) Render(format string) error {
-	switch format {
-	case "json":
-		return r.renderJSON()
-	case "plain", "table":
-		return r.renderText()
-	default:
-		return fmt.Errorf("unknown format: %s", format)
-	}
-}
-
-func (r *regionsRenderer) renderText() error {
-	var headers []string
-	if !r.noHeaders {
-		headers = []string{"regions"}
-	}
-	table := ui.NewTable(r.writer, headers)
-	for _, r := range r.regions {
-		table.Append([]string{r})
-	}
-	table.Render()
-
-	return nil
-}
-
-func (r *regionsRenderer) renderJSON() error {
-	body, err := json.Marshal(r.regions)
-	if err != nil {
-		return err
+func (r *regionsRenderer) table() *render.Table {
+	t := &render.Table{Columns: []render.Column{{Name: "regions"}}}
+	for _, v := range r.regions {
+		t.Rows = append(t.Rows, []string{v})
 	}
-	return render.PrettyPrintJSON(body, r.writer)
+	return t
 }
@@ -0,0 +1,126 @@
+package resource
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/neticdk-k8s/ic/internal/render"
+	"github.com/neticdk-k8s/ic/internal/ui"
+)
+
+type resourceRenderer struct {
+	render.Base
+	resource *resourceResponse
+}
+
+// NewResourceRenderer creates a new renderer of a single resource
+func NewResourceRenderer(resource *resourceResponse, jsonData []byte, writer io.Writer) *resourceRenderer {
+	rr := &resourceRenderer{
+		Base:     render.Base{Writer: writer, JSON: jsonData, Value: resource},
+		resource: resource,
+	}
+	rr.Text = rr.renderText
+	rr.Names = func() []string { return []string{resourceName(*resource)} }
+	return rr
+}
+
+func (r *resourceRenderer) renderText() error {
+	data := [][]string{
+		{"Name:", r.resource.Name},
+		{"Namespace:", r.resource.Namespace},
+		{"API Version:", r.resource.APIVersion},
+		{"Kind:", r.resource.Kind},
+		{"Owner:", r.resource.Owner},
+	}
+	ui.RenderKVTable(r.Writer, "Base Information", data)
+
+	fmt.Fprintln(r.Writer)
+	fmt.Fprintln(r.Writer, "Labels:")
+	labelsTable := ui.NewTable(r.Writer, []string{"key", "value"})
+	labelsTable.AppendBulk(sortedKV(r.resource.Labels))
+	labelsTable.Render()
+
+	fmt.Fprintln(r.Writer)
+	fmt.Fprintln(r.Writer, "Annotations:")
+	annotationsTable := ui.NewTable(r.Writer, []string{"key", "value"})
+	annotationsTable.AppendBulk(sortedKV(r.resource.Annotations))
+	annotationsTable.Render()
+
+	return nil
+}
+
+type resourcesRenderer struct {
+	render.Base
+	resources *resourceListResponse
+}
+
+// NewResourcesRenderer creates a new renderer for a list of resources
+func NewResourcesRenderer(resources *resourceListResponse, jsonData []byte, writer io.Writer, noHeaders bool) *resourcesRenderer {
+	rr := &resourcesRenderer{
+		Base:      render.Base{Writer: writer, NoHeaders: noHeaders, JSON: jsonData, Value: resources, Items: resources.Resources},
+		resources: resources,
+	}
+	rr.Table = rr.table
+	rr.Names = func() []string {
+		names := make([]string, 0, len(resources.Resources))
+		for _, res := range resources.Resources {
+			names = append(names, resourceName(res))
+		}
+		return names
+	}
+	return rr
+}
+
+var resourceColumns = []render.Column{
+	{Name: "namespace"},
+	{Name: "name"},
+	{Name: "kind"},
+	{Name: "owner"},
+	{Name: "labels"},
+	{Name: "api-version", Optional: true},
+	{Name: "annotations", Optional: true},
+}
+
+func (r *resourcesRenderer) table() *render.Table {
+	t := &render.Table{Columns: resourceColumns}
+	for _, res := range r.resources.Resources {
+		t.Rows = append(t.Rows, []string{
+			res.Namespace,
+			res.Name,
+			res.Kind,
+			res.Owner,
+			joinKV(res.Labels),
+			res.APIVersion,
+			joinKV(res.Annotations),
+		})
+	}
+	return t
+}
+
+// resourceName returns namespace/name of namespaced resources and the name
+// of cluster scoped resources
+func resourceName(res resourceResponse) string {
+	if res.Namespace == "" {
+		return res.Name
+	}
+	return fmt.Sprintf("%s/%s", res.Namespace, res.Name)
+}
+
+func joinKV(m map[string]string) string {
+	pairs := make([]string, 0, len(m))
+	for _, kv := range sortedKV(m) {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", kv[0], kv[1]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func sortedKV(m map[string]string) [][]string {
+	rows := make([][]string, 0, len(m))
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		rows = append(rows, []string{k, m[k]})
+	}
+	return rows
+}
@@ -0,0 +1,281 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/neticdk-k8s/ic/internal/apiclient"
+	"github.com/neticdk/go-common/pkg/qsparser"
+)
+
+type resourceResponse struct {
+	Name        string            `json:"name,omitempty"`
+	Namespace   string            `json:"namespace,omitempty"`
+	APIVersion  string            `json:"api_version,omitempty"`
+	Kind        string            `json:"kind,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+}
+
+type resourceListResponse struct {
+	Resources []resourceResponse `json:"resources,omitempty"`
+}
+
+// ResourceList is a list of resources
+type ResourceList struct { //nolint
+	// Included is a list of included items
+	Included []map[string]any
+	// Resources is a list of resources
+	Resources []string
+}
+
+func (rl *ResourceList) ToResponse() *resourceListResponse {
+	rlr := &resourceListResponse{
+		Resources: make([]resourceResponse, 0),
+	}
+	includeMap := make(map[string]any)
+	for _, i := range rl.Included {
+		if v, ok := mapValAs[string](i, "@id"); ok {
+			includeMap[v] = i
+		}
+	}
+	for _, id := range rl.Resources {
+		i, ok := includeMap[id].(map[string]any)
+		if !ok {
+			continue
+		}
+		rr := resourceResponse{}
+		rr.Name, _ = mapValAs[string](i, "name")
+		rr.Namespace, _ = mapValAs[string](i, "namespace")
+		rr.APIVersion, _ = mapValAs[string](i, "apiVersion")
+		rr.Kind, _ = mapValAs[string](i, "kind")
+		rr.Labels = mapStrings(i, "labels")
+		rr.Annotations = mapStrings(i, "annotations")
+		if owner, ok := mapValAs[string](i, "owner"); ok {
+			rr.Owner = ownerName(includeMap, owner)
+		}
+		rlr.Resources = append(rlr.Resources, rr)
+	}
+	return rlr
+}
+
+func (rl *ResourceList) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rl.ToResponse())
+}
+
+// ListResourcesInput is the input given to ListResources()
+type ListResourcesInput struct {
+	// Logger is a logger
+	Logger *slog.Logger
+	// APIClient is the inventory server API client used to make requests
+	APIClient apiclient.ClientWithResponsesInterface
+	// Page is the initial page (0-based index)
+	Page int
+	// PerPage is the number of items requested for each page
+	PerPage int
+	// ClusterID is the id of the cluster
+	ClusterID string
+	// Group is the API group of the resource type
+	Group string
+	// Version is the API version of the resource type
+	Version string
+	// ResourceType is the plural name of the resource type, e.g. deployments
+	ResourceType string
+}
+
+// ListResourcesResults is the result of ListResources
+type ListResourcesResults struct {
+	ResourceListResponse *resourceListResponse
+	JSONResponse         []byte
+	Problem              *apiclient.Problem
+}
+
+// ListResources returns a non-paginated list of resources of a given type
+func ListResources(ctx context.Context, in ListResourcesInput) (*ListResourcesResults, error) {
+	rl := &ResourceList{}
+	problem, err := listResources(ctx, &in, rl)
+	if err != nil {
+		return nil, fmt.Errorf("listing resources: %w", err)
+	}
+	if problem != nil {
+		return &ListResourcesResults{nil, nil, problem}, nil
+	}
+	jsonData, err := rl.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("marshaling resource list: %w", err)
+	}
+	return &ListResourcesResults{rl.ToResponse(), jsonData, nil}, nil
+}
+
+func listResources(ctx context.Context, in *ListResourcesInput, resourceList *ResourceList) (*apiclient.Problem, error) { //nolint
+	nextPage := func(_ context.Context, req *http.Request) error {
+		sp := qsparser.SearchParams{
+			Page:    &in.Page,
+			PerPage: &in.PerPage,
+		}
+		sp.SetRawQuery(req)
+		return nil
+	}
+	response, err := in.APIClient.ListResourcesByTypeWithResponse(ctx, in.ClusterID, in.Group, in.Version, in.ResourceType, nextPage)
+	if err != nil {
+		return nil, fmt.Errorf("reading resources: %w", err)
+	}
+	in.Logger.DebugContext(ctx, "listResourcesByType", logStatus(response.HTTPResponse)...)
+	switch response.StatusCode() {
+	case http.StatusOK:
+	case http.StatusBadRequest:
+		return response.ApplicationproblemJSON400, nil
+	case http.StatusUnauthorized:
+		return response.ApplicationproblemJSON401, nil
+	case http.StatusInternalServerError:
+		return response.ApplicationproblemJSON500, nil
+	default:
+		return nil, fmt.Errorf("bad status code: %d", response.StatusCode())
+	}
+	if response.ApplicationldJSONDefault.Resource != nil {
+		resourceList.Resources = append(resourceList.Resources, *response.ApplicationldJSONDefault.Resource...)
+	}
+	if response.ApplicationldJSONDefault.Included != nil {
+		resourceList.Included = append(resourceList.Included, *response.ApplicationldJSONDefault.Included...)
+	}
+	if response.ApplicationldJSONDefault.Pagination != nil && response.ApplicationldJSONDefault.Pagination.Next != nil {
+		in.Page++
+		return listResources(ctx, in, resourceList)
+	}
+	return nil, nil
+}
+
+// GetResourceInput is the input used by GetResource()
+type GetResourceInput struct {
+	Logger    *slog.Logger
+	APIClient apiclient.ClientWithResponsesInterface
+	// ClusterID is the id of the cluster
+	ClusterID string
+	// Group is the API group of the resource
+	Group string
+	// Version is the API version of the resource
+	Version string
+	// Namespace is the namespace of the resource
+	Namespace string
+	// ResourceType is the plural name of the resource type, e.g. deployments
+	ResourceType string
+	// Name is the name of the resource
+	Name string
+}
+
+// GetResourceResult is the result of GetResource
+type GetResourceResult struct {
+	ResourceResponse *resourceResponse
+	JSONResponse     []byte
+	Problem          *apiclient.Problem
+}
+
+// GetResource returns information about a single resource
+func GetResource(ctx context.Context, in GetResourceInput) (*GetResourceResult, error) {
+	response, err := in.APIClient.GetResourceWithResponse(ctx, in.ClusterID, in.Group, in.Version, in.Namespace, in.ResourceType, in.Name)
+	if err != nil {
+		return nil, fmt.Errorf("getting resource: %w", err)
+	}
+	in.Logger.DebugContext(ctx, "getResource", logStatus(response.HTTPResponse)...)
+	switch response.StatusCode() {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return &GetResourceResult{nil, nil, response.ApplicationproblemJSON401}, nil
+	case http.StatusInternalServerError:
+		return &GetResourceResult{nil, nil, response.ApplicationproblemJSON500}, nil
+	default:
+		return nil, fmt.Errorf("bad status code: %d", response.StatusCode())
+	}
+
+	resource := toResourceResponse(response.ApplicationldJSONDefault)
+
+	jsonData, err := json.Marshal(resource)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling resource: %w", err)
+	}
+
+	return &GetResourceResult{resource, jsonData, nil}, nil
+}
+
+func toResourceResponse(resource *apiclient.GenericResource) *resourceResponse {
+	includeMap := make(map[string]any)
+	if resource.Included != nil {
+		for _, i := range *resource.Included {
+			if v, ok := mapValAs[string](i, "@id"); ok {
+				includeMap[v] = i
+			}
+		}
+	}
+	rr := &resourceResponse{}
+	rr.Name = nilStr(resource.Name)
+	rr.Namespace = nilStr(resource.Namespace)
+	rr.APIVersion = nilStr(resource.ApiVersion)
+	rr.Kind = nilStr(resource.Kind)
+	if resource.Labels != nil {
+		rr.Labels = *resource.Labels
+	}
+	if resource.Annotations != nil {
+		rr.Annotations = *resource.Annotations
+	}
+	if resource.Owner != nil {
+		rr.Owner = ownerName(includeMap, *resource.Owner)
+	}
+	return rr
+}
+
+// ownerName returns kind/name of the owner if it is included and the
+// owner IRI otherwise
+func ownerName(includeMap map[string]any, ownerID string) string {
+	if owner, ok := includeMap[ownerID]; ok {
+		if o, ok := owner.(map[string]any); ok {
+			kind, _ := mapValAs[string](o, "kind")
+			name, _ := mapValAs[string](o, "name")
+			if kind != "" && name != "" {
+				return fmt.Sprintf("%s/%s", kind, name)
+			}
+		}
+	}
+	return ownerID
+}
+
+func mapStrings(haystak map[string]any, needle string) map[string]string {
+	m, ok := mapValAs[map[string]any](haystak, needle)
+	if !ok {
+		return nil
+	}
+	res := make(map[string]string, len(m))
+	for k, v := range m {
+		if s, ok := v.(string); ok {
+			res[k] = s
+		}
+	}
+	return res
+}
+
+func nilStr(s *string) string {
+	if s != nil {
+		return *s
+	}
+	return ""
+}
+
+func mapValAs[T any](haystak map[string]any, needle string) (T, bool) {
+	if v, ok := haystak[needle]; ok {
+		if v2, ok := v.(T); ok {
+			return v2, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func logStatus(r *http.Response) []any {
+	return []any{
+		slog.Int("status", r.StatusCode),
+		slog.String("content-type", r.Header.Get("Content-Type")),
+	}
+}
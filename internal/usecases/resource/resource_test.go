@@ -0,0 +1,174 @@
+package resource
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/neticdk-k8s/ic/internal/apiclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestResourceList_ToResponse(t *testing.T) {
+	rl := ResourceList{
+		Resources: make([]string, 0),
+		Included:  make([]map[string]any, 0),
+	}
+	t.Run("Valid input", func(t *testing.T) {
+		rl.Resources = []string{"my-deployment-id"}
+		rl.Included = []map[string]any{
+			{
+				"@id":        "my-deployment-id",
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"name":       "my-deployment",
+				"namespace":  "my-namespace",
+				"labels": map[string]any{
+					"app": "my-app",
+				},
+				"annotations": map[string]any{
+					"note": "my-note",
+				},
+				"owner": "my-owner-id",
+			},
+			{
+				"@id":  "my-owner-id",
+				"kind": "Application",
+				"name": "my-application",
+			},
+		}
+		want := &resourceListResponse{
+			Resources: []resourceResponse{
+				{
+					Name:        "my-deployment",
+					Namespace:   "my-namespace",
+					APIVersion:  "apps/v1",
+					Kind:        "Deployment",
+					Labels:      map[string]string{"app": "my-app"},
+					Annotations: map[string]string{"note": "my-note"},
+					Owner:       "Application/my-application",
+				},
+			},
+		}
+		got := rl.ToResponse()
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Empty input", func(t *testing.T) {
+		rl.Resources = []string{""}
+		rl.Included = []map[string]any{}
+		want := &resourceListResponse{[]resourceResponse{}}
+		got := rl.ToResponse()
+		assert.Equal(t, want, got)
+	})
+}
+
+func TestListResources(t *testing.T) {
+	logger := slog.Default()
+
+	resources := []string{"my-deployment-id"}
+	included := []map[string]any{
+		{
+			"@id":       "my-deployment-id",
+			"kind":      "Deployment",
+			"name":      "my-deployment",
+			"namespace": "my-namespace",
+		},
+	}
+
+	mockClient := apiclient.NewMockClientWithResponsesInterface(t)
+	mockClient.EXPECT().
+		ListResourcesByTypeWithResponse(mock.Anything, "my-cluster.my-provider", "apps", "v1", "deployments", mock.Anything).
+		Return(
+			&apiclient.ListResourcesByTypeResponse{
+				Body: make([]byte, 0),
+				HTTPResponse: &http.Response{
+					Status:     "200 OK",
+					StatusCode: 200,
+				},
+				ApplicationldJSONDefault: &apiclient.Resources{
+					Resource:   &resources,
+					Included:   &included,
+					Pagination: &apiclient.Pagination{},
+				},
+			}, nil)
+	in := ListResourcesInput{
+		Logger:       logger,
+		APIClient:    mockClient,
+		ClusterID:    "my-cluster.my-provider",
+		Group:        "apps",
+		Version:      "v1",
+		ResourceType: "deployments",
+	}
+
+	want := &resourceListResponse{
+		Resources: []resourceResponse{
+			{
+				Name:      "my-deployment",
+				Namespace: "my-namespace",
+				Kind:      "Deployment",
+			},
+		},
+	}
+
+	got, err := ListResources(context.TODO(), in)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got.ResourceListResponse)
+
+	wantJSON := []byte(`{"resources":[{"name":"my-deployment","namespace":"my-namespace","kind":"Deployment"}]}`)
+	assert.Equal(t, wantJSON, got.JSONResponse)
+}
+
+func TestGetResource(t *testing.T) {
+	logger := slog.Default()
+	mockClient := apiclient.NewMockClientWithResponsesInterface(t)
+	name := "my-deployment"
+	namespace := "my-namespace"
+	kind := "Deployment"
+	labels := map[string]string{"app": "my-app"}
+	owner := "my-owner-id"
+	mockClient.EXPECT().
+		GetResourceWithResponse(mock.Anything, "my-cluster.my-provider", "apps", "v1", "my-namespace", "deployments", "my-deployment").
+		Return(
+			&apiclient.GetResourceResponse{
+				Body: make([]byte, 0),
+				HTTPResponse: &http.Response{
+					Status:     "200 OK",
+					StatusCode: 200,
+				},
+				ApplicationldJSONDefault: &apiclient.GenericResource{
+					Name:      &name,
+					Namespace: &namespace,
+					Kind:      &kind,
+					Labels:    &labels,
+					Owner:     &owner,
+				},
+			}, nil)
+
+	want := &resourceResponse{
+		Name:      "my-deployment",
+		Namespace: "my-namespace",
+		Kind:      "Deployment",
+		Labels:    map[string]string{"app": "my-app"},
+		Owner:     "my-owner-id",
+	}
+
+	in := GetResourceInput{
+		Logger:       logger,
+		APIClient:    mockClient,
+		ClusterID:    "my-cluster.my-provider",
+		Group:        "apps",
+		Version:      "v1",
+		Namespace:    "my-namespace",
+		ResourceType: "deployments",
+		Name:         "my-deployment",
+	}
+	got, err := GetResource(context.TODO(), in)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got.ResourceResponse)
+
+	wantJSON := []byte(`{"name":"my-deployment","namespace":"my-namespace","kind":"Deployment","labels":{"app":"my-app"},"owner":"my-owner-id"}`)
+	assert.Equal(t, wantJSON, got.JSONResponse)
+}